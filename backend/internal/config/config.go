@@ -4,16 +4,26 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Server    ServerConfig    `json:"server"`
-	Database  DatabaseConfig  `json:"database"`
-	Redis     RedisConfig     `json:"redis"`
-	Worker    WorkerConfig    `json:"worker"`
-	Auth      AuthConfig      `json:"auth"`
-	RateLimit RateLimitConfig `json:"rate_limit"`
+	Server       ServerConfig       `json:"server"`
+	Database     DatabaseConfig     `json:"database"`
+	Redis        RedisConfig        `json:"redis"`
+	Worker       WorkerConfig       `json:"worker"`
+	Auth         AuthConfig         `json:"auth"`
+	RateLimit    RateLimitConfig    `json:"rate_limit"`
+	Notification NotificationConfig `json:"notification"`
+	Stream       StreamConfig       `json:"stream"`
+	Collab       CollabConfig       `json:"collab"`
+	Webhook      WebhookConfig      `json:"webhook"`
+	Outbox       OutboxConfig       `json:"outbox"`
+	Events       EventsConfig       `json:"events"`
+	InboundEmail InboundEmailConfig `json:"inbound_email"`
+	Email        EmailConfig        `json:"email"`
+	Reports      ReportsConfig      `json:"reports"`
 }
 
 type ServerConfig struct {
@@ -23,6 +33,7 @@ type ServerConfig struct {
 	WriteTimeout time.Duration `json:"write_timeout"`
 	IdleTimeout  time.Duration `json:"idle_timeout"`
 	Environment  string        `json:"environment"`
+	PublicURL    string        `json:"public_url"`
 }
 
 type DatabaseConfig struct {
@@ -62,6 +73,26 @@ type AuthConfig struct {
 	AccessTokenTTL  time.Duration `json:"access_token_ttl"`
 	RefreshTokenTTL time.Duration `json:"refresh_token_ttl"`
 	BCryptCost      int           `json:"bcrypt_cost"`
+	// PasswordResetTTL is how long a password reset link stays valid.
+	PasswordResetTTL time.Duration `json:"password_reset_ttl"`
+	// PasswordResetResendDelay is the minimum time between reset emails to
+	// the same user.
+	PasswordResetResendDelay time.Duration `json:"password_reset_resend_delay"`
+	// RequireEmailVerification blocks logins until the user has opened the
+	// link in their verification email.
+	RequireEmailVerification bool          `json:"require_email_verification"`
+	EmailVerificationTTL     time.Duration `json:"email_verification_ttl"`
+	// VerificationResendDelay is the minimum time between verification emails
+	// to the same user.
+	VerificationResendDelay time.Duration `json:"verification_resend_delay"`
+	// MFARequiredRoles lists roles that must sign in with a second factor.
+	MFARequiredRoles []string      `json:"mfa_required_roles"`
+	MFATokenTTL      time.Duration `json:"mfa_token_ttl"`
+	MFAIssuer        string        `json:"mfa_issuer"`
+	// MFAMaxFailedAttempts is how many wrong codes a user may enter before
+	// MFA is locked for MFALockout.
+	MFAMaxFailedAttempts int           `json:"mfa_max_failed_attempts"`
+	MFALockout           time.Duration `json:"mfa_lockout"`
 }
 
 type RateLimitConfig struct {
@@ -71,6 +102,77 @@ type RateLimitConfig struct {
 	CleanupInterval time.Duration `json:"cleanup_interval"`
 }
 
+type NotificationConfig struct {
+	Retention       time.Duration `json:"retention"`
+	CleanupInterval time.Duration `json:"cleanup_interval"`
+	// DigestCheckInterval is how often the worker looks for digests due.
+	DigestCheckInterval time.Duration `json:"digest_check_interval"`
+}
+
+type StreamConfig struct {
+	ReplayBufferSize  int           `json:"replay_buffer_size"`
+	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
+}
+
+type CollabConfig struct {
+	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
+	LockTTL           time.Duration `json:"lock_ttl"`
+}
+
+type WebhookConfig struct {
+	MaxAttempts         int           `json:"max_attempts"`
+	FailureThreshold    int           `json:"failure_threshold"`
+	Timeout             time.Duration `json:"timeout"`
+	AllowPrivateTargets bool          `json:"allow_private_targets"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `json:"poll_interval"`
+	BatchSize    int           `json:"batch_size"`
+	MaxAttempts  int           `json:"max_attempts"`
+	Retention    time.Duration `json:"retention"`
+}
+
+type EventsConfig struct {
+	Workers    int `json:"workers"`
+	BufferSize int `json:"buffer_size"`
+}
+
+// InboundEmailConfig controls email-to-task. Ingestion is off unless Domain is
+// set; SMTPAddr additionally starts the built-in SMTP listener.
+type InboundEmailConfig struct {
+	Domain         string `json:"domain"`
+	SMTPAddr       string `json:"smtp_addr"`
+	Secret         string `json:"-"`
+	MaxMessageSize int64  `json:"max_message_size"`
+}
+
+// EmailConfig controls outgoing mail. Transport is "smtp" or "file"; the file
+// transport writes .eml files to FileDir and is the default when no SMTP host
+// is configured.
+type EmailConfig struct {
+	Transport         string `json:"transport"`
+	From              string `json:"from"`
+	AppURL            string `json:"app_url"`
+	FileDir           string `json:"file_dir"`
+	SMTPHost          string `json:"smtp_host"`
+	SMTPPort          int    `json:"smtp_port"`
+	SMTPUsername      string `json:"smtp_username"`
+	SMTPPassword      string `json:"-"`
+	SMTPTLSMode       string `json:"smtp_tls_mode"`
+	UnsubscribeSecret string `json:"-"`
+}
+
+type ReportsConfig struct {
+	CacheTTL time.Duration `json:"cache_ttl"`
+	// A member is flagged as overloaded in the workload view above either limit.
+	WorkloadMaxOpenTasks int `json:"workload_max_open_tasks"`
+	WorkloadMaxHours     int `json:"workload_max_hours"`
+	// SprintSnapshotInterval is how often active sprints are snapshotted for
+	// burndown charts. Each run overwrites the current day's row.
+	SprintSnapshotInterval time.Duration `json:"sprint_snapshot_interval"`
+}
+
 func LoadConfig() (*Config, error) {
 	config := &Config{
 		Server: ServerConfig{
@@ -80,6 +182,7 @@ func LoadConfig() (*Config, error) {
 			WriteTimeout: getEnvAsDuration("WRITE_TIMEOUT", 30*time.Second),
 			IdleTimeout:  getEnvAsDuration("IDLE_TIMEOUT", 60*time.Second),
 			Environment:  getEnv("ENVIRONMENT", "development"),
+			PublicURL:    strings.TrimSuffix(getEnv("PUBLIC_URL", ""), "/"),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -111,10 +214,24 @@ func LoadConfig() (*Config, error) {
 			Queues:       []string{"default", "high_priority", "low_priority"},
 		},
 		Auth: AuthConfig{
-			JWTSecret:       getEnv("JWT_SECRET", "your-secret-key"),
-			AccessTokenTTL:  getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
-			RefreshTokenTTL: getEnvAsDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
-			BCryptCost:      getEnvAsInt("BCRYPT_COST", 10),
+			JWTSecret:        getEnv("JWT_SECRET", "your-secret-key"),
+			AccessTokenTTL:   getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:  getEnvAsDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+			BCryptCost:       getEnvAsInt("BCRYPT_COST", 10),
+			PasswordResetTTL: getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+
+			PasswordResetResendDelay: getEnvAsDuration("PASSWORD_RESET_RESEND_DELAY", 2*time.Minute),
+
+			RequireEmailVerification: getEnvAsBool("REQUIRE_EMAIL_VERIFICATION", false),
+			EmailVerificationTTL:     getEnvAsDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+			VerificationResendDelay:  getEnvAsDuration("VERIFICATION_RESEND_DELAY", 2*time.Minute),
+
+			MFARequiredRoles: getEnvAsList("MFA_REQUIRED_ROLES", nil),
+			MFATokenTTL:      getEnvAsDuration("MFA_TOKEN_TTL", 5*time.Minute),
+			MFAIssuer:        getEnv("MFA_ISSUER", "Taskify"),
+
+			MFAMaxFailedAttempts: getEnvAsInt("MFA_MAX_FAILED_ATTEMPTS", 5),
+			MFALockout:           getEnvAsDuration("MFA_LOCKOUT", 15*time.Minute),
 		},
 		RateLimit: RateLimitConfig{
 			Enabled:         getEnvAsBool("RATE_LIMIT_ENABLED", true),
@@ -122,7 +239,65 @@ func LoadConfig() (*Config, error) {
 			BurstSize:       getEnvAsInt("RATE_LIMIT_BURST", 10),
 			CleanupInterval: getEnvAsDuration("RATE_LIMIT_CLEANUP", 10*time.Minute),
 		},
+		Notification: NotificationConfig{
+			Retention:           getEnvAsDuration("NOTIFICATION_RETENTION", 90*24*time.Hour),
+			CleanupInterval:     getEnvAsDuration("NOTIFICATION_CLEANUP_INTERVAL", 24*time.Hour),
+			DigestCheckInterval: getEnvAsDuration("DIGEST_CHECK_INTERVAL", 5*time.Minute),
+		},
+		Stream: StreamConfig{
+			ReplayBufferSize:  getEnvAsInt("STREAM_REPLAY_BUFFER_SIZE", 1000),
+			HeartbeatInterval: getEnvAsDuration("STREAM_HEARTBEAT_INTERVAL", 25*time.Second),
+		},
+		Collab: CollabConfig{
+			HeartbeatInterval: getEnvAsDuration("COLLAB_HEARTBEAT_INTERVAL", 30*time.Second),
+			LockTTL:           getEnvAsDuration("COLLAB_LOCK_TTL", time.Minute),
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:         getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			FailureThreshold:    getEnvAsInt("WEBHOOK_FAILURE_THRESHOLD", 5),
+			Timeout:             getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			AllowPrivateTargets: getEnvAsBool("WEBHOOK_ALLOW_PRIVATE_TARGETS", false),
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvAsDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+			Retention:    getEnvAsDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+		},
+		Events: EventsConfig{
+			Workers:    getEnvAsInt("EVENT_BUS_WORKERS", 2),
+			BufferSize: getEnvAsInt("EVENT_BUS_BUFFER_SIZE", 256),
+		},
+		InboundEmail: InboundEmailConfig{
+			Domain:         strings.ToLower(getEnv("INBOUND_EMAIL_DOMAIN", "")),
+			SMTPAddr:       getEnv("INBOUND_SMTP_ADDR", ""),
+			Secret:         getEnv("INBOUND_EMAIL_SECRET", ""),
+			MaxMessageSize: int64(getEnvAsInt("INBOUND_EMAIL_MAX_SIZE", 25<<20)),
+		},
+		Email: EmailConfig{
+			From:         getEnv("EMAIL_FROM", "Task Manager <no-reply@localhost>"),
+			AppURL:       strings.TrimSuffix(getEnv("APP_URL", "http://localhost:3000"), "/"),
+			FileDir:      getEnv("EMAIL_FILE_DIR", "emails"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			SMTPTLSMode:  getEnv("SMTP_TLS", "starttls"),
+		},
+		Reports: ReportsConfig{
+			CacheTTL:               getEnvAsDuration("REPORT_CACHE_TTL", 5*time.Minute),
+			WorkloadMaxOpenTasks:   getEnvAsInt("WORKLOAD_MAX_OPEN_TASKS", 10),
+			WorkloadMaxHours:       getEnvAsInt("WORKLOAD_MAX_HOURS", 40),
+			SprintSnapshotInterval: getEnvAsDuration("SPRINT_SNAPSHOT_INTERVAL", time.Hour),
+		},
+	}
+
+	defaultTransport := "file"
+	if config.Email.SMTPHost != "" {
+		defaultTransport = "smtp"
 	}
+	config.Email.Transport = getEnv("EMAIL_TRANSPORT", defaultTransport)
+	config.Email.UnsubscribeSecret = getEnv("EMAIL_UNSUBSCRIBE_SECRET", config.Auth.JWTSecret)
 
 	if config.Database.Password == "" && config.Server.Environment == "production" {
 		return nil, fmt.Errorf("database password is required in production")
@@ -191,3 +366,18 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvAsList reads a comma-separated list, skipping empty entries.
+func getEnvAsList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
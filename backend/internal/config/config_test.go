@@ -20,14 +20,26 @@ func clearEnvVars(vars []string) {
 
 func TestLoadConfig_Defaults(t *testing.T) {
 	envVars := []string{
-		"HOST", "PORT", "READ_TIMEOUT", "WRITE_TIMEOUT", "IDLE_TIMEOUT", "ENVIRONMENT",
+		"HOST", "PORT", "READ_TIMEOUT", "WRITE_TIMEOUT", "IDLE_TIMEOUT", "ENVIRONMENT", "PUBLIC_URL",
 		"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSL_MODE",
 		"DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONN_MAX_IDLE_TIME",
 		"REDIS_HOST", "REDIS_PORT", "REDIS_PASSWORD", "REDIS_DB", "REDIS_POOL_SIZE",
 		"REDIS_MIN_IDLE_CONNS", "REDIS_MAX_RETRIES", "REDIS_DIAL_TIMEOUT", "REDIS_READ_TIMEOUT", "REDIS_WRITE_TIMEOUT",
 		"WORKER_CONCURRENCY", "WORKER_POLL_INTERVAL",
-		"JWT_SECRET", "ACCESS_TOKEN_TTL", "REFRESH_TOKEN_TTL", "BCRYPT_COST",
+		"JWT_SECRET", "ACCESS_TOKEN_TTL", "REFRESH_TOKEN_TTL", "BCRYPT_COST", "PASSWORD_RESET_TTL", "PASSWORD_RESET_RESEND_DELAY",
+		"REQUIRE_EMAIL_VERIFICATION", "EMAIL_VERIFICATION_TTL", "VERIFICATION_RESEND_DELAY",
+		"MFA_REQUIRED_ROLES", "MFA_TOKEN_TTL", "MFA_ISSUER", "MFA_MAX_FAILED_ATTEMPTS", "MFA_LOCKOUT",
 		"RATE_LIMIT_ENABLED", "RATE_LIMIT_RPM", "RATE_LIMIT_BURST", "RATE_LIMIT_CLEANUP",
+		"NOTIFICATION_RETENTION", "NOTIFICATION_CLEANUP_INTERVAL", "DIGEST_CHECK_INTERVAL",
+		"STREAM_REPLAY_BUFFER_SIZE", "STREAM_HEARTBEAT_INTERVAL",
+		"COLLAB_HEARTBEAT_INTERVAL", "COLLAB_LOCK_TTL",
+		"WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_FAILURE_THRESHOLD", "WEBHOOK_TIMEOUT", "WEBHOOK_ALLOW_PRIVATE_TARGETS",
+		"OUTBOX_POLL_INTERVAL", "OUTBOX_BATCH_SIZE", "OUTBOX_MAX_ATTEMPTS", "OUTBOX_RETENTION",
+		"EVENT_BUS_WORKERS", "EVENT_BUS_BUFFER_SIZE",
+		"INBOUND_EMAIL_DOMAIN", "INBOUND_SMTP_ADDR", "INBOUND_EMAIL_SECRET", "INBOUND_EMAIL_MAX_SIZE",
+		"EMAIL_TRANSPORT", "EMAIL_FROM", "APP_URL", "EMAIL_FILE_DIR", "EMAIL_UNSUBSCRIBE_SECRET",
+		"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "SMTP_TLS",
+		"REPORT_CACHE_TTL", "WORKLOAD_MAX_OPEN_TASKS", "WORKLOAD_MAX_HOURS", "SPRINT_SNAPSHOT_INTERVAL",
 	}
 	clearEnvVars(envVars)
 
@@ -96,6 +108,26 @@ func TestLoadConfig_Defaults(t *testing.T) {
 		t.Errorf("Expected default bcrypt cost 10, got %d", config.Auth.BCryptCost)
 	}
 
+	if config.Auth.PasswordResetTTL != time.Hour {
+		t.Errorf("Expected default password reset TTL 1h, got %v", config.Auth.PasswordResetTTL)
+	}
+
+	if config.Auth.PasswordResetResendDelay != 2*time.Minute {
+		t.Errorf("Expected default password reset resend delay 2m, got %v", config.Auth.PasswordResetResendDelay)
+	}
+
+	if config.Auth.RequireEmailVerification {
+		t.Error("Expected email verification to be optional by default")
+	}
+
+	if len(config.Auth.MFARequiredRoles) != 0 {
+		t.Errorf("Expected no roles to require MFA by default, got %v", config.Auth.MFARequiredRoles)
+	}
+
+	if config.Auth.MFAMaxFailedAttempts != 5 {
+		t.Errorf("Expected default MFA attempt limit 5, got %d", config.Auth.MFAMaxFailedAttempts)
+	}
+
 	if !config.RateLimit.Enabled {
 		t.Error("Expected rate limiting to be enabled by default")
 	}
@@ -103,6 +135,36 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	if config.RateLimit.RequestsPerMin != 100 {
 		t.Errorf("Expected default requests per minute 100, got %d", config.RateLimit.RequestsPerMin)
 	}
+
+	if config.Notification.Retention != 90*24*time.Hour {
+		t.Errorf("Expected default notification retention 90 days, got %v", config.Notification.Retention)
+	}
+
+	if config.Reports.CacheTTL != 5*time.Minute {
+		t.Errorf("Expected default report cache TTL 5m, got %v", config.Reports.CacheTTL)
+	}
+	if config.Reports.WorkloadMaxOpenTasks != 10 || config.Reports.WorkloadMaxHours != 40 {
+		t.Errorf("Expected default workload limits 10 tasks and 40 hours, got %d and %d", config.Reports.WorkloadMaxOpenTasks, config.Reports.WorkloadMaxHours)
+	}
+	if config.Reports.SprintSnapshotInterval != time.Hour {
+		t.Errorf("Expected default sprint snapshot interval 1h, got %v", config.Reports.SprintSnapshotInterval)
+	}
+
+	if config.Notification.DigestCheckInterval != 5*time.Minute {
+		t.Errorf("Expected default digest check interval 5m, got %v", config.Notification.DigestCheckInterval)
+	}
+
+	if config.Stream.ReplayBufferSize != 1000 {
+		t.Errorf("Expected default stream replay buffer 1000, got %d", config.Stream.ReplayBufferSize)
+	}
+
+	if config.Email.Transport != "file" {
+		t.Errorf("Expected file email transport without an SMTP host, got %s", config.Email.Transport)
+	}
+
+	if config.Email.UnsubscribeSecret != config.Auth.JWTSecret {
+		t.Error("Expected unsubscribe secret to default to the JWT secret")
+	}
 }
 
 func TestLoadConfig_CustomEnvironment(t *testing.T) {
@@ -438,6 +500,23 @@ func TestGetEnvAsDuration(t *testing.T) {
 	}
 }
 
+func TestGetEnvAsList(t *testing.T) {
+	key := "TEST_LIST_VAR"
+
+	os.Unsetenv(key)
+	if result := getEnvAsList(key, []string{"admin"}); len(result) != 1 || result[0] != "admin" {
+		t.Errorf("Expected default value [admin], got %v", result)
+	}
+
+	os.Setenv(key, " admin, ,manager ")
+	defer os.Unsetenv(key)
+
+	result := getEnvAsList(key, nil)
+	if len(result) != 2 || result[0] != "admin" || result[1] != "manager" {
+		t.Errorf("Expected [admin manager], got %v", result)
+	}
+}
+
 func TestConfigValidation_EdgeCases(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentHandler struct {
+	db                *gorm.DB
+	attachmentService services.AttachmentService
+	authzService      services.AuthorizationService
+}
+
+func NewAttachmentHandler(db *gorm.DB, attachmentService services.AttachmentService, authzService services.AuthorizationService) *AttachmentHandler {
+	return &AttachmentHandler{db: db, attachmentService: attachmentService, authzService: authzService}
+}
+
+// GetAttachments lists a task's attachments
+// GET /tasks/:id/attachments
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	_, taskID, ok := authorizeTaskRead(c, h.authzService)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentService.GetAttachments(requestDB(c, h.db), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attachments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task_id": taskID, "attachments": attachments})
+}
+
+// DownloadAttachment returns an attachment's contents. Files are always sent
+// as downloads so that uploaded HTML cannot run in the app's origin.
+// GET /tasks/:id/attachments/:attachment_id
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	_, taskID, ok := authorizeTaskRead(c, h.authzService)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := uuid.FromString(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(requestDB(c, h.db), taskID, attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attachment"})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, attachment.ContentType, attachment.Data)
+}
@@ -1,18 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/services"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
 	db          *gorm.DB
 	authService services.AuthService
+	mfaService  services.MFAService
 }
 
 type LoginRequest struct {
@@ -27,6 +31,27 @@ type LoginResponse struct {
 	ExpiresIn    int64                `json:"expires_in"`
 	User         *UserProfileResponse `json:"user"`
 	Permissions  []string             `json:"permissions"`
+	// RecoveryCodes is only set when the login also finished MFA enrollment.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// MFAChallengeResponse replaces LoginResponse while a second factor is
+// outstanding. The MFA token goes to /auth/mfa/verify, or first to
+// /auth/mfa/enroll when EnrollmentRequired is set.
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int64  `json:"expires_in"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
 }
 
 type UserProfileResponse struct {
@@ -41,8 +66,8 @@ type UserProfileResponse struct {
 	Roles       []string   `json:"roles"`
 }
 
-func NewAuthHandler(db *gorm.DB, authService services.AuthService) *AuthHandler {
-	return &AuthHandler{db: db, authService: authService}
+func NewAuthHandler(db *gorm.DB, authService services.AuthService, mfaService services.MFAService) *AuthHandler {
+	return &AuthHandler{db: db, authService: authService, mfaService: mfaService}
 }
 
 func (h *AuthHandler) Token(c *gin.Context) {
@@ -59,6 +84,13 @@ func (h *AuthHandler) Token(c *gin.Context) {
 	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
 
 	user, err := h.authService.LoginUser(h.db, req.Email, req.Password)
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "email_not_verified",
+			"message": "Please verify your email address before signing in",
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "invalid_credentials",
@@ -75,7 +107,148 @@ func (h *AuthHandler) Token(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.GenerateToken(h.db, user.ID)
+	enabled, err := h.mfaService.IsEnabled(h.db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to check multi-factor authentication",
+		})
+		return
+	}
+	if enabled {
+		h.challengeMFA(c, user.ID, false)
+		return
+	}
+
+	required, err := h.mfaService.RequiredFor(h.db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to check multi-factor authentication",
+		})
+		return
+	}
+	if required {
+		h.challengeMFA(c, user.ID, true)
+		return
+	}
+
+	h.issueCredentials(c, user, nil)
+}
+
+// EnrollMFA starts TOTP enrollment for a user whose role requires MFA but who
+// has not set it up, using the MFA token from login.
+// POST /auth/mfa/enroll
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	var req MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	challenge, err := h.mfaService.ParseToken(req.MFAToken)
+	if err != nil || !challenge.Enroll {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_mfa_token",
+			"message": "Invalid or expired MFA token",
+		})
+		return
+	}
+
+	user, ok := h.activeUser(c, challenge.UserID)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(h.db, user)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// VerifyMFA completes a login with a TOTP or recovery code. For a login that
+// required enrollment, the code confirms the new authenticator and the
+// response carries the user's recovery codes.
+// POST /auth/mfa/verify
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	challenge, err := h.mfaService.ParseToken(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_mfa_token",
+			"message": "Invalid or expired MFA token",
+		})
+		return
+	}
+
+	user, ok := h.activeUser(c, challenge.UserID)
+	if !ok {
+		return
+	}
+
+	var recoveryCodes []string
+	if challenge.Enroll {
+		recoveryCodes, err = h.mfaService.ConfirmEnrollment(h.db, user.ID, req.Code)
+	} else {
+		err = h.mfaService.Verify(h.db, user.ID, req.Code)
+	}
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	h.issueCredentials(c, user, recoveryCodes)
+}
+
+func (h *AuthHandler) challengeMFA(c *gin.Context, userID uuid.UUID, enroll bool) {
+	token, err := h.mfaService.IssueToken(userID, enroll)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "token_generation_failed",
+			"message": "Failed to generate MFA token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MFAChallengeResponse{
+		MFARequired:        true,
+		EnrollmentRequired: enroll,
+		MFAToken:           token,
+		ExpiresIn:          int64(h.mfaService.TokenTTL().Seconds()),
+	})
+}
+
+// activeUser loads the user an MFA token was issued to, who may have been
+// disabled since.
+func (h *AuthHandler) activeUser(c *gin.Context, userID uuid.UUID) (*models.User, bool) {
+	var user models.User
+	if err := h.db.Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_mfa_token",
+			"message": "Invalid or expired MFA token",
+		})
+		return nil, false
+	}
+	return &user, true
+}
+
+func (h *AuthHandler) issueCredentials(c *gin.Context, user *models.User, recoveryCodes []string) {
+	accessToken, refreshToken, err := h.authService.GenerateToken(h.db, user.ID, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "token_generation_failed",
@@ -113,6 +286,8 @@ func (h *AuthHandler) Token(c *gin.Context) {
 		ExpiresIn:    3600, 
 		User:         userProfile,
 		Permissions:  permissions,
+
+		RecoveryCodes: recoveryCodes,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -38,6 +38,11 @@ func (m *MockAuthorizationService) HasPermission(ctx context.Context, userID uui
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockAuthorizationService) PermissionScopes(ctx context.Context, userID uuid.UUID, resource, action string) ([]string, error) {
+	args := m.Called(ctx, userID, resource, action)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockAuthorizationService) IsAuthorized(ctx context.Context, request services.AuthorizationRequest) (*services.AuthorizationDecision, error) {
 	args := m.Called(ctx, request)
 	return args.Get(0).(*services.AuthorizationDecision), args.Error(1)
@@ -143,7 +148,9 @@ func (suite *AuthorizationHandlerTestSuite) SetupSuite() {
 			department TEXT,
 			position TEXT,
 			is_active BOOLEAN DEFAULT true,
-			last_login_at DATETIME
+			last_login_at DATETIME,
+			email_verified_at DATETIME,
+			verification_sent_at DATETIME
 		)
 	`).Error
 	suite.Require().NoError(err)
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CalendarHandler struct {
+	db              *gorm.DB
+	calendarService services.CalendarService
+	publicURL       string
+}
+
+// NewCalendarHandler creates a calendar handler. publicURL is the externally
+// visible base URL used in feed links; when empty it is taken from the request.
+func NewCalendarHandler(db *gorm.DB, calendarService services.CalendarService, publicURL string) *CalendarHandler {
+	return &CalendarHandler{db: db, calendarService: calendarService, publicURL: publicURL}
+}
+
+// RegenerateToken issues a new secret feed URL. Any earlier URL stops working.
+// POST /calendar/token
+func (h *CalendarHandler) RegenerateToken(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	token, err := h.calendarService.RegenerateToken(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate calendar token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token": token,
+		"url":   h.baseURL(c) + "/api/v1/calendar/" + token + ".ics",
+	})
+}
+
+// RevokeToken disables the caller's feed
+// DELETE /calendar/token
+func (h *CalendarHandler) RevokeToken(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.calendarService.RevokeToken(requestDB(c, h.db), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke calendar token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed disabled"})
+}
+
+// Feed serves the iCalendar feed for a token. The token is the only
+// credential, since calendar clients cannot send auth headers.
+// GET /calendar/:token.ics?type=todo&label=work
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	if c.Query("project") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Filtering by project is not supported"})
+		return
+	}
+
+	feed, err := h.calendarService.GetFeed(requestDB(c, h.db), token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+		return
+	}
+
+	filter := services.CalendarFilter{Component: services.CalendarComponentEvent}
+	switch strings.ToLower(c.Query("type")) {
+	case "", "event":
+	case "todo":
+		filter.Component = services.CalendarComponentTodo
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be event or todo"})
+		return
+	}
+	for _, value := range c.QueryArray("label") {
+		for _, label := range strings.Split(value, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				filter.Labels = append(filter.Labels, label)
+			}
+		}
+	}
+
+	tasks, err := h.calendarService.GetFeedTasks(requestDB(c, h.db), feed.UserID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+		return
+	}
+
+	name := "Tasks"
+	var user models.User
+	if err := requestDB(c, h.db).Select("username").Where("id = ?", feed.UserID).First(&user).Error; err == nil && user.Username != "" {
+		name = user.Username + "'s tasks"
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(services.RenderCalendar(name, tasks, filter.Component, time.Now())))
+}
+
+func (h *CalendarHandler) baseURL(c *gin.Context) string {
+	return publicBaseURL(c, h.publicURL)
+}
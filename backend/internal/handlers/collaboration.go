@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	collabWriteTimeout   = 10 * time.Second
+	collabMaxMessageSize = 4096
+)
+
+type CollaborationHandler struct {
+	db           *gorm.DB
+	hub          *services.CollaborationHub
+	authzService services.AuthorizationService
+	heartbeat    time.Duration
+}
+
+func NewCollaborationHandler(db *gorm.DB, hub *services.CollaborationHub, authzService services.AuthorizationService, heartbeat time.Duration) *CollaborationHandler {
+	if heartbeat <= 0 {
+		heartbeat = 30 * time.Second
+	}
+	return &CollaborationHandler{db: db, hub: hub, authzService: authzService, heartbeat: heartbeat}
+}
+
+// Connect upgrades to a WebSocket for presence and edit locks on task topics
+// GET /ws
+func (h *CollaborationHandler) Connect(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var user models.User
+	if err := requestDB(c, h.db).Select("id", "username").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return
+	}
+
+	// The socket outlives the request; its connection pinned for the
+	// workspace goes back to the pool before the socket opens.
+	ctx := c.Request.Context()
+	services.ReleaseWorkspaceSession(ctx)
+	server := websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			ws.MaxPayloadBytes = collabMaxMessageSize
+			h.serve(ctx, ws, h.hub.Register(userID, user.Username))
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serve pumps messages between one connection and the hub. All writes happen
+// on the writer goroutine; the reader hands replies to the hub instead.
+func (h *CollaborationHandler) serve(ctx context.Context, ws *websocket.Conn, client *services.CollabClient) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		h.writeLoop(ws, client, done)
+	}()
+
+	h.readLoop(ctx, ws, client)
+
+	close(done)
+	ws.Close()
+	wg.Wait()
+	h.hub.Unregister(client)
+}
+
+func (h *CollaborationHandler) writeLoop(ws *websocket.Conn, client *services.CollabClient, done <-chan struct{}) {
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var msg services.CollabMessage
+		select {
+		case <-done:
+			return
+		case m, ok := <-client.Messages():
+			if !ok {
+				ws.Close()
+				return
+			}
+			msg = m
+		case <-ticker.C:
+			msg = services.CollabMessage{Type: services.CollabMessagePing}
+		}
+
+		ws.SetWriteDeadline(time.Now().Add(collabWriteTimeout))
+		if err := websocket.JSON.Send(ws, msg); err != nil {
+			ws.Close()
+			return
+		}
+	}
+}
+
+// readLoop handles client requests until the connection closes or the client
+// misses two heartbeats.
+func (h *CollaborationHandler) readLoop(ctx context.Context, ws *websocket.Conn, client *services.CollabClient) {
+	for {
+		ws.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+
+		var msg services.CollabMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case services.CollabMessageSubscribe:
+			if err := h.authorizeTopic(ctx, client.UserID, msg.Topic); err != nil {
+				h.sendError(client, msg, err.Error())
+				continue
+			}
+			h.hub.Join(client, msg.Topic)
+		case services.CollabMessageUnsubscribe:
+			h.hub.Leave(client, msg.Topic)
+		case services.CollabMessageLock:
+			lock, err := h.hub.Lock(client, msg.Topic, msg.Field)
+			if errors.Is(err, services.ErrLockHeld) {
+				h.hub.Send(client, services.CollabMessage{
+					Type:    services.CollabMessageError,
+					Topic:   msg.Topic,
+					Field:   msg.Field,
+					Lock:    &lock,
+					Message: err.Error(),
+				})
+			} else if err != nil {
+				h.sendError(client, msg, err.Error())
+			}
+		case services.CollabMessageUnlock:
+			if err := h.hub.Unlock(client, msg.Topic, msg.Field); err != nil {
+				h.sendError(client, msg, err.Error())
+			}
+		case services.CollabMessagePing:
+			h.hub.Send(client, services.CollabMessage{Type: services.CollabMessagePong})
+		case services.CollabMessagePong:
+			// Receiving it already extended the read deadline.
+		default:
+			h.sendError(client, msg, fmt.Sprintf("unknown message type %q", msg.Type))
+		}
+	}
+}
+
+func (h *CollaborationHandler) sendError(client *services.CollabClient, msg services.CollabMessage, message string) {
+	h.hub.Send(client, services.CollabMessage{
+		Type:    services.CollabMessageError,
+		Topic:   msg.Topic,
+		Field:   msg.Field,
+		Message: message,
+	})
+}
+
+// authorizeTopic checks the caller may read the resource behind a topic.
+// Topics are "<resource>:<id>"; only tasks exist in this schema, so project
+// topics are rejected until projects do.
+func (h *CollaborationHandler) authorizeTopic(ctx context.Context, userID uuid.UUID, topic string) error {
+	// The socket outlives the request, so the connection pinned for its
+	// workspace goes back to the pool between checks.
+	defer services.ReleaseWorkspaceSession(ctx)
+
+	resource, rawID, ok := strings.Cut(topic, ":")
+	if !ok {
+		return errors.New("invalid topic")
+	}
+
+	if resource != "task" {
+		return fmt.Errorf("unsupported topic type %q", resource)
+	}
+
+	resourceID, err := uuid.FromString(rawID)
+	if err != nil {
+		return errors.New("invalid topic ID")
+	}
+
+	decision, err := h.authzService.IsAuthorized(ctx, services.AuthorizationRequest{
+		UserID:     userID,
+		Resource:   resource,
+		Action:     "read",
+		ResourceID: &resourceID,
+	})
+	if err != nil {
+		log.Printf("Collaboration authorization check failed for %s: %v", topic, err)
+		return errors.New("authorization check failed")
+	}
+
+	if decision.Decision != "allowed" {
+		return errors.New("access denied")
+	}
+	return nil
+}
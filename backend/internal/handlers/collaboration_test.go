@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func collabTestToken(t *testing.T, userID uuid.UUID) string {
+	claims := jwt.MapClaims{
+		"user_id": userID.String(),
+		"role":    "user",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"iss":     "taskify-backend",
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("default_secret_change_in_production"))
+	require.NoError(t, err)
+	return token
+}
+
+func dialCollab(t *testing.T, server *httptest.Server, userID uuid.UUID) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?access_token=" + collabTestToken(t, userID)
+	ws, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// receiveCollab reads messages until one of the wanted type arrives.
+func receiveCollab(t *testing.T, ws *websocket.Conn, msgType string) services.CollabMessage {
+	t.Helper()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for {
+		var msg services.CollabMessage
+		require.NoError(t, websocket.JSON.Receive(ws, &msg))
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestCollaborationHandler_PresenceAndLocks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE users (id TEXT PRIMARY KEY, username TEXT, deleted_at DATETIME)`).Error)
+
+	aliceID := uuid.Must(uuid.NewV4())
+	bobID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec("INSERT INTO users (id, username) VALUES (?, 'alice'), (?, 'bob')", aliceID, bobID).Error)
+
+	taskID := uuid.Must(uuid.NewV4())
+	hiddenID := uuid.Must(uuid.NewV4())
+
+	authz := new(MockAuthorizationService)
+	authz.On("IsAuthorized", mock.Anything, mock.MatchedBy(func(req services.AuthorizationRequest) bool {
+		return *req.ResourceID == taskID
+	})).Return(&services.AuthorizationDecision{Decision: "allowed"}, nil)
+	authz.On("IsAuthorized", mock.Anything, mock.Anything).
+		Return(&services.AuthorizationDecision{Decision: "denied"}, nil)
+
+	hub := services.NewCollaborationHub(time.Minute)
+	handler := handlers.NewCollaborationHandler(db, hub, authz, time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", middleware.AuthzMiddleware(middleware.AuthzConfig{AllowQueryToken: true}), handler.Connect)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	topic := "task:" + taskID.String()
+	alice := dialCollab(t, server, aliceID)
+	bob := dialCollab(t, server, bobID)
+
+	require.NoError(t, websocket.JSON.Send(bob, services.CollabMessage{Type: services.CollabMessageSubscribe, Topic: "task:" + hiddenID.String()}))
+	assert.Equal(t, "access denied", receiveCollab(t, bob, services.CollabMessageError).Message)
+
+	require.NoError(t, websocket.JSON.Send(alice, services.CollabMessage{Type: services.CollabMessageSubscribe, Topic: topic}))
+	receiveCollab(t, alice, services.CollabMessageSubscribed)
+
+	require.NoError(t, websocket.JSON.Send(bob, services.CollabMessage{Type: services.CollabMessageSubscribe, Topic: topic}))
+	subscribed := receiveCollab(t, bob, services.CollabMessageSubscribed)
+	assert.Len(t, subscribed.Users, 2)
+
+	require.NoError(t, websocket.JSON.Send(alice, services.CollabMessage{Type: services.CollabMessageLock, Topic: topic, Field: "title"}))
+	locked := receiveCollab(t, bob, services.CollabMessageLocked)
+	require.NotNil(t, locked.Lock)
+	assert.Equal(t, aliceID, locked.Lock.UserID)
+
+	// Disconnecting releases the lock and updates presence for remaining viewers.
+	alice.Close()
+	assert.Equal(t, "title", receiveCollab(t, bob, services.CollabMessageUnlocked).Field)
+	presence := receiveCollab(t, bob, services.CollabMessagePresence)
+	require.Len(t, presence.Users, 1)
+	assert.Equal(t, "bob", presence.Users[0].Username)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DigestHandler struct {
+	db            *gorm.DB
+	digestService services.DigestService
+}
+
+type DigestPreferenceRequest struct {
+	Frequency *string `json:"frequency"`
+	Timezone  *string `json:"timezone"`
+	Hour      *int    `json:"hour"`
+	Weekday   *int    `json:"weekday"`
+}
+
+func NewDigestHandler(db *gorm.DB, digestService services.DigestService) *DigestHandler {
+	return &DigestHandler{db: db, digestService: digestService}
+}
+
+// GetDigestPreference returns the current user's digest schedule
+// GET /notifications/digest
+func (h *DigestHandler) GetDigestPreference(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pref, err := h.digestService.GetPreference(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get digest preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// UpdateDigestPreference changes the digest schedule. Fields left out of the
+// request keep their current value. While a digest is on, notifications are
+// emailed in the digest instead of one by one.
+// PUT /notifications/digest
+func (h *DigestHandler) UpdateDigestPreference(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req DigestPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	pref, err := h.digestService.GetPreference(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get digest preference"})
+		return
+	}
+
+	if req.Frequency != nil {
+		pref.Frequency = *req.Frequency
+	}
+	if req.Timezone != nil {
+		pref.Timezone = *req.Timezone
+	}
+	if req.Hour != nil {
+		pref.Hour = *req.Hour
+	}
+	if req.Weekday != nil {
+		pref.Weekday = *req.Weekday
+	}
+
+	updated, err := h.digestService.SetPreference(requestDB(c, h.db), *pref)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDigestPreference) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update digest preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EmailHandler struct {
+	db                *gorm.DB
+	unsubscribeSecret string
+}
+
+func NewEmailHandler(db *gorm.DB, unsubscribeSecret string) *EmailHandler {
+	return &EmailHandler{db: db, unsubscribeSecret: unsubscribeSecret}
+}
+
+// Unsubscribe turns off the email notifications an unsubscribe link was sent
+// for. The signed token is the credential. Mail clients use POST for one-click
+// unsubscribe (RFC 8058); GET serves links opened in a browser.
+// GET|POST /email/unsubscribe?token=
+func (h *EmailHandler) Unsubscribe(c *gin.Context) {
+	userID, scope, err := services.ParseUnsubscribeToken(h.unsubscribeSecret, c.Query("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unsubscribe link"})
+		return
+	}
+
+	if err := services.UnsubscribeEmail(h.db, userID, scope); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "You will no longer receive these emails", "event_type": scope})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationHandler struct {
+	db                  *gorm.DB
+	verificationService services.EmailVerificationService
+	queue               *worker.JobQueue
+	appURL              string
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// NewEmailVerificationHandler creates the email verification handler.
+// Verification links point at appURL's /verify-email page.
+func NewEmailVerificationHandler(db *gorm.DB, verificationService services.EmailVerificationService, queue *worker.JobQueue, appURL string) *EmailVerificationHandler {
+	return &EmailVerificationHandler{db: db, verificationService: verificationService, queue: queue, appURL: strings.TrimSuffix(appURL, "/")}
+}
+
+// VerifyEmail confirms the address a verification email was sent to.
+// POST /auth/email/verify
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.verificationService.Verify(h.db, req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_token",
+				"message": "Invalid or expired verification link",
+			})
+			return
+		}
+		log.Printf("❌ Email verification failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to verify email address",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Your email address has been verified",
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt,
+	})
+}
+
+// ResendVerification sends a new verification email to an unverified
+// account. The answer is the same whether or not such an account exists,
+// unless an email went out too recently.
+// POST /auth/email/resend
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, token, err := h.verificationService.RequestResend(h.db, req.Email)
+	if err != nil {
+		if errors.Is(err, services.ErrVerificationThrottled) {
+			retryAfter := int(h.verificationService.ResendDelay().Seconds())
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "verification_throttled",
+				"message":     "A verification email was sent recently. Please wait before asking again.",
+				"retry_after": retryAfter,
+			})
+			return
+		}
+		log.Printf("❌ Verification resend failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to send verification email",
+		})
+		return
+	}
+
+	if user != nil {
+		h.enqueue(user, token)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If this email belongs to an unverified account, a new verification link has been sent",
+	})
+}
+
+// SendVerification emails a verification link to a newly registered user.
+func (h *EmailVerificationHandler) SendVerification(user *models.User) {
+	token, err := h.verificationService.IssueToken(h.db, user)
+	if err != nil {
+		log.Printf("❌ Failed to issue verification token for user %s: %v", user.ID, err)
+		return
+	}
+	h.enqueue(user, token)
+}
+
+func (h *EmailVerificationHandler) enqueue(user *models.User, token string) {
+	data := map[string]interface{}{
+		"url":        h.appURL + "/verify-email?token=" + url.QueryEscape(token),
+		"expires_in": humanizeDuration(h.verificationService.TTL()),
+	}
+	if err := services.EnqueueEmail(h.queue, user.ID, user.Email, services.EmailTemplateVerification, data); err != nil {
+		log.Printf("❌ Failed to queue verification email for user %s: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type InboundEmailHandler struct {
+	db             *gorm.DB
+	inboundService services.InboundEmailService
+	secret         string
+	maxMessageSize int64
+}
+
+// NewInboundEmailHandler creates the email-to-task handler. When secret is set
+// the inbound endpoint also requires it in the X-Inbound-Secret header, so
+// only the mail gateway can post messages.
+func NewInboundEmailHandler(db *gorm.DB, inboundService services.InboundEmailService, secret string, maxMessageSize int64) *InboundEmailHandler {
+	return &InboundEmailHandler{db: db, inboundService: inboundService, secret: secret, maxMessageSize: maxMessageSize}
+}
+
+// Receive creates a task from a raw RFC 5322 message. Envelope recipients may
+// be passed as ?recipient= (repeatable) or X-Original-To; otherwise the To and
+// Cc headers are used.
+// POST /inbound/email
+func (h *InboundEmailHandler) Receive(c *gin.Context) {
+	if h.secret != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Inbound-Secret")), []byte(h.secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid inbound secret"})
+		return
+	}
+
+	recipients := c.QueryArray("recipient")
+	if original := c.GetHeader("X-Original-To"); original != "" && len(recipients) == 0 {
+		recipients = []string{original}
+	}
+
+	body := c.Request.Body
+	if h.maxMessageSize > 0 {
+		body = http.MaxBytesReader(c.Writer, body, h.maxMessageSize)
+	}
+
+	task, err := h.inboundService.Ingest(requestDB(c, h.db), body, recipients)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge), errors.Is(err, services.ErrInboundMessageTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Message too large"})
+		case errors.Is(err, services.ErrInboundMessageInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInboundRecipientUnknown):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown inbound address"})
+		case errors.Is(err, services.ErrInboundSenderMismatch):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Sender is not allowed to post to this address"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task from email"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// GetAddress returns the caller's secret inbound address
+// GET /inbound/address
+func (h *InboundEmailHandler) GetAddress(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	address, err := h.inboundService.GetAddress(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inbound address"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"address": address})
+}
+
+// RegenerateAddress replaces the caller's inbound address. The old address
+// stops working immediately.
+// POST /inbound/address
+func (h *InboundEmailHandler) RegenerateAddress(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	address, err := h.inboundService.RegenerateAddress(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate inbound address"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"address": address})
+}
@@ -1,46 +1,135 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"task-manager/backend/internal/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
 )
 
 type LogoutHandler struct {
 	db          *gorm.DB
 	authService services.AuthService
+	denylist    *services.TokenDenylist
 }
 
 type LogoutRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token"`
 }
 
-func NewLogoutHandler(db *gorm.DB, authService services.AuthService) *LogoutHandler {
-	return &LogoutHandler{db: db, authService: authService}
+func NewLogoutHandler(db *gorm.DB, authService services.AuthService, denylist *services.TokenDenylist) *LogoutHandler {
+	return &LogoutHandler{db: db, authService: authService, denylist: denylist}
 }
 
+// Logout ends the session the access token belongs to: the access token is
+// revoked and the session's refresh tokens are deleted. A refresh token given
+// in the body is revoked too and must be the current user's.
+// POST /auth/logout
 func (h *LogoutHandler) Logout(c *gin.Context) {
-	var req LogoutRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "invalid_request",
-			"message": "Invalid request format",
-			"details": err.Error(),
-		})
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	err := h.authService.RevokeToken(h.db, req.RefreshToken)
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_request",
+				"message": "Invalid request format",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.RefreshToken != "" {
+		if err := h.authService.RevokeToken(h.db, userID, req.RefreshToken); err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidRefreshToken):
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "invalid_token",
+					"message": "Invalid refresh token",
+				})
+			case errors.Is(err, services.ErrRefreshTokenNotOwned):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":   "token_not_owned",
+					"message": "Refresh token belongs to another user",
+				})
+			default:
+				log.Printf("❌ Failed to revoke refresh token: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "server_error",
+					"message": "Failed to log out",
+				})
+			}
+			return
+		}
+	}
+
+	jti := c.GetString("token_jti")
+	if accessJTI, err := uuid.FromString(jti); err == nil {
+		if err := h.authService.RevokeSession(h.db, userID, accessJTI); err != nil {
+			log.Printf("❌ Failed to revoke session of access token %s: %v", jti, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "server_error",
+				"message": "Failed to log out",
+			})
+			return
+		}
+	}
+
+	expiresAt, _ := c.Get("token_expires_at")
+	if exp, ok := expiresAt.(time.Time); ok && jti != "" {
+		if err := h.denylist.Revoke(c.Request.Context(), jti, exp); err != nil {
+			log.Printf("❌ Failed to revoke access token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "server_error",
+				"message": "Failed to log out",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully logged out",
+	})
+}
+
+// LogoutAll ends every session of the current user: all refresh tokens are
+// deleted and all access tokens issued so far are revoked.
+// POST /auth/logout-all
+func (h *LogoutHandler) LogoutAll(c *gin.Context) {
+	userID, err := currentUserID(c)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Successfully logged out",
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.authService.RevokeUserTokens(h.db, userID); err != nil {
+		log.Printf("❌ Failed to revoke refresh tokens for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to log out of all sessions",
+		})
+		return
+	}
+	if err := h.denylist.RevokeUser(c.Request.Context(), userID); err != nil {
+		log.Printf("❌ Failed to revoke access tokens for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to log out of all sessions",
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Successfully logged out",
+		"message": "Successfully logged out of all sessions",
 	})
 }
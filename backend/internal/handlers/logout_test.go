@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// stubAuthService revokes refresh tokens from a map of token to owner.
+type stubAuthService struct {
+	services.AuthService
+	owners map[string]uuid.UUID
+}
+
+func (s *stubAuthService) RevokeToken(db *gorm.DB, userID uuid.UUID, refreshToken string) error {
+	owner, ok := s.owners[refreshToken]
+	if !ok {
+		return services.ErrInvalidRefreshToken
+	}
+	if owner != userID {
+		return services.ErrRefreshTokenNotOwned
+	}
+	delete(s.owners, refreshToken)
+	return nil
+}
+
+func (s *stubAuthService) RevokeSession(db *gorm.DB, userID, accessJTI uuid.UUID) error {
+	return nil
+}
+
+func TestLogoutHandler_RevokesOnlyOwnRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	auth := &stubAuthService{owners: map[string]uuid.UUID{"alice-refresh": alice}}
+	handler := handlers.NewLogoutHandler(nil, auth, services.NewTokenDenylist(nil))
+
+	logout := func(userID uuid.UUID, body string) int {
+		router := gin.New()
+		router.POST("/auth/logout", func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Next()
+		}, handler.Logout)
+		req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, logout(alice, `{"refresh_token":"unknown"}`))
+	assert.Equal(t, http.StatusForbidden, logout(bob, `{"refresh_token":"alice-refresh"}`))
+	assert.Contains(t, auth.owners, "alice-refresh", "another user's token is left alone")
+	assert.Equal(t, http.StatusOK, logout(alice, `{"refresh_token":"alice-refresh"}`))
+	assert.NotContains(t, auth.owners, "alice-refresh")
+	assert.Equal(t, http.StatusOK, logout(alice, ""), "the refresh token is optional")
+}
+
+func TestLogoutHandler_EndsSessionWithoutRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE tokens (
+		id UUID PRIMARY KEY,
+		user_id UUID NOT NULL,
+		jti UUID NOT NULL UNIQUE,
+		refresh_token TEXT NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		family_id UUID,
+		rotated_at TIMESTAMP,
+		access_jti UUID,
+		device TEXT DEFAULT '',
+		user_agent TEXT DEFAULT '',
+		ip_address TEXT DEFAULT '',
+		last_used_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP
+	)`).Error)
+
+	auth := services.NewAuthService(services.AuthOptions{})
+	userID := uuid.Must(uuid.NewV4())
+	_, refreshToken, err := auth.GenerateToken(db, userID, services.ClientInfo{})
+	require.NoError(t, err)
+	_, otherSession, err := auth.GenerateToken(db, userID, services.ClientInfo{})
+	require.NoError(t, err)
+
+	var accessJTI string
+	require.NoError(t, db.Raw("SELECT access_jti FROM tokens WHERE refresh_token = ?", refreshToken).Scan(&accessJTI).Error)
+
+	handler := handlers.NewLogoutHandler(db, auth, services.NewTokenDenylist(nil))
+	router := gin.New()
+	router.POST("/auth/logout", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("token_jti", accessJTI)
+		c.Set("token_expires_at", time.Now().Add(services.AccessTokenTTL))
+		c.Next()
+	}, handler.Logout)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/logout", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, _, _, err = auth.RefreshToken(db, refreshToken, services.ClientInfo{})
+	assert.Error(t, err, "the session's refresh token no longer works")
+	_, _, _, err = auth.RefreshToken(db, otherSession, services.ClientInfo{})
+	assert.NoError(t, err, "other sessions are left alone")
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type MFAHandler struct {
+	db         *gorm.DB
+	mfaService services.MFAService
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func NewMFAHandler(db *gorm.DB, mfaService services.MFAService) *MFAHandler {
+	return &MFAHandler{db: db, mfaService: mfaService}
+}
+
+// GetStatus reports whether the current user has MFA enabled.
+// GET /mfa
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	status, err := h.mfaService.Status(h.db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load MFA status"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// BeginEnrollment creates a TOTP secret for the current user. It takes effect
+// once confirmed with a code from the authenticator app.
+// POST /mfa/enroll
+func (h *MFAHandler) BeginEnrollment(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(h.db, &user)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmEnrollment enables MFA and returns the recovery codes. They are only
+// shown here.
+// POST /mfa/enroll/confirm
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmEnrollment(h.db, userID, req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Multi-factor authentication enabled", "recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes.
+// POST /mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(h.db, userID, req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable turns MFA off for the current user, unless their role requires it.
+// DELETE /mfa
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(h.db, userID, req.Code); err != nil {
+		respondMFAError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Multi-factor authentication disabled"})
+}
+
+// bindCode reads the current user and the code confirming the request.
+func (h *MFAHandler) bindCode(c *gin.Context) (uuid.UUID, MFACodeRequest, bool) {
+	var req MFACodeRequest
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, req, false
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return uuid.Nil, req, false
+	}
+	return userID, req, true
+}
+
+// respondMFAError writes the response for an error from MFAService.
+func respondMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_mfa_code",
+			"message": "Invalid authentication code",
+		})
+	case errors.Is(err, services.ErrMFALocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "mfa_locked",
+			"message": "Too many failed authentication codes. Try again later",
+		})
+	case errors.Is(err, services.ErrMFANotEnrolled):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "mfa_not_enrolled",
+			"message": "Multi-factor authentication is not set up",
+		})
+	case errors.Is(err, services.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "mfa_already_enabled",
+			"message": "Multi-factor authentication is already enabled",
+		})
+	case errors.Is(err, services.ErrMFARequiredForRole):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "mfa_required",
+			"message": "Multi-factor authentication is required for your role",
+		})
+	default:
+		log.Printf("❌ MFA operation failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Multi-factor authentication failed",
+		})
+	}
+}
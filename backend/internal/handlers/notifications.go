@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationHandler struct {
+	db                  *gorm.DB
+	notificationService services.NotificationService
+}
+
+type NotificationPreferenceRequest struct {
+	EventType    string `json:"event_type" binding:"required"`
+	EmailEnabled *bool  `json:"email_enabled"`
+	InAppEnabled *bool  `json:"in_app_enabled"`
+}
+
+var taskEventTypes = map[string]bool{
+	models.TaskEventCreated:       true,
+	models.TaskEventUpdated:       true,
+	models.TaskEventStatusChanged: true,
+	models.TaskEventDeleted:       true,
+}
+
+func NewNotificationHandler(db *gorm.DB, notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{db: db, notificationService: notificationService}
+}
+
+// GetNotifications lists the current user's notifications, newest first
+// GET /notifications?unread=true&page=1&pageSize=20
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	notifications, total, err := h.notificationService.GetNotifications(requestDB(c, h.db), userID, unreadOnly, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+
+	unread, err := h.notificationService.CountUnread(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"total":         total,
+		"unread_count":  unread,
+	})
+}
+
+// GetUnreadCount returns the number of unread notifications
+// GET /notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	unread, err := h.notificationService.CountUnread(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkRead marks a single notification as read
+// PUT /notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	notificationID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.notificationService.MarkRead(requestDB(c, h.db), userID, notificationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllRead marks every unread notification of the current user as read
+// PUT /notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": updated})
+}
+
+// GetNotificationPreferences returns the current user's per-event settings
+// GET /notifications/preferences
+func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	prefs, err := h.notificationService.GetNotificationPreferences(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// UpdateNotificationPreference enables or disables channels for one event type.
+// Channels left out of the request keep their current setting.
+// PUT /notifications/preferences
+func (h *NotificationHandler) UpdateNotificationPreference(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req NotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if !taskEventTypes[req.EventType] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event type", "event_type": req.EventType})
+		return
+	}
+
+	prefs, err := h.notificationService.GetNotificationPreferences(requestDB(c, h.db), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification preferences"})
+		return
+	}
+
+	pref := models.NotificationPreference{
+		UserID:       userID,
+		EventType:    req.EventType,
+		EmailEnabled: true,
+		InAppEnabled: true,
+	}
+	for _, existing := range prefs {
+		if existing.EventType == req.EventType {
+			pref = existing
+			break
+		}
+	}
+
+	if req.EmailEnabled != nil {
+		pref.EmailEnabled = *req.EmailEnabled
+	}
+	if req.InAppEnabled != nil {
+		pref.InAppEnabled = *req.InAppEnabled
+	}
+
+	if err := h.notificationService.SetNotificationPreference(requestDB(c, h.db), pref); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PasswordResetHandler struct {
+	db           *gorm.DB
+	resetService services.PasswordResetService
+	register     *RegisterHandler
+	queue        *worker.JobQueue
+	denylist     *services.TokenDenylist
+	appURL       string
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// NewPasswordResetHandler creates the password reset handler. New passwords
+// follow the registration rules of register. Reset links point at appURL's
+// /reset-password page.
+func NewPasswordResetHandler(db *gorm.DB, resetService services.PasswordResetService, register *RegisterHandler, queue *worker.JobQueue, denylist *services.TokenDenylist, appURL string) *PasswordResetHandler {
+	return &PasswordResetHandler{db: db, resetService: resetService, register: register, queue: queue, denylist: denylist, appURL: strings.TrimSuffix(appURL, "/")}
+}
+
+// ForgotPassword emails a reset link to the account with the address. The
+// answer is the same whether or not the account exists, and whether or not a
+// link was sent too recently for another.
+// POST /auth/password/forgot
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, token, err := h.resetService.RequestReset(h.db, req.Email)
+	if errors.Is(err, services.ErrPasswordResetThrottled) {
+		user, err = nil, nil
+	}
+	if err != nil {
+		log.Printf("❌ Password reset request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to process password reset request",
+		})
+		return
+	}
+
+	if user != nil {
+		data := map[string]interface{}{
+			"url":        h.appURL + "/reset-password?token=" + url.QueryEscape(token),
+			"expires_in": humanizeDuration(h.resetService.TTL()),
+		}
+		if err := services.EnqueueEmail(h.queue, user.ID, user.Email, services.EmailTemplatePasswordReset, data); err != nil {
+			log.Printf("❌ Failed to queue password reset email for user %s: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password with a token from a reset email and signs
+// the user out everywhere
+// POST /auth/password/reset
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.register.validatePassword(req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_password",
+			"message": "Validation failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.resetService.ResetPassword(h.db, req.Token, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_token",
+				"message": "Invalid or expired password reset token",
+			})
+			return
+		}
+		log.Printf("❌ Password reset failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to reset password",
+		})
+		return
+	}
+
+	if h.denylist != nil {
+		if err := h.denylist.RevokeUser(c.Request.Context(), user.ID); err != nil {
+			log.Printf("⚠️  Failed to revoke access tokens after password reset for user %s: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset. Please sign in again."})
+}
+
+// humanizeDuration renders d for emails, such as "1 hour" or "30 minutes".
+func humanizeDuration(d time.Duration) string {
+	unit, count := "minute", int(d.Round(time.Minute)/time.Minute)
+	if d >= time.Hour && d%time.Hour == 0 {
+		unit, count = "hour", int(d/time.Hour)
+	}
+	if count != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", count, unit)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// maxAccessTokenDays bounds the expiry that can be chosen for a token.
+const maxAccessTokenDays = 366
+
+type PersonalAccessTokenHandler struct {
+	db           *gorm.DB
+	tokenService services.PersonalAccessTokenService
+}
+
+type CreateAccessTokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// ExpiresInDays is optional; without it the token lasts until revoked.
+	ExpiresInDays *int `json:"expires_in_days"`
+}
+
+type CreateAccessTokenResponse struct {
+	*models.PersonalAccessToken
+	// Token is only returned when the token is created.
+	Token string `json:"token"`
+}
+
+func NewPersonalAccessTokenHandler(db *gorm.DB, tokenService services.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{db: db, tokenService: tokenService}
+}
+
+// ListTokens returns the current user's personal access tokens.
+// GET /access-tokens
+func (h *PersonalAccessTokenHandler) ListTokens(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	tokens, err := h.tokenService.List(h.db, userID)
+	if err != nil {
+		log.Printf("❌ Failed to list access tokens of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load access tokens"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// CreateToken issues a personal access token limited to the requested scopes,
+// which must be among the user's permissions. The token is only shown here.
+// POST /access-tokens
+func (h *PersonalAccessTokenHandler) CreateToken(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays < 1 || *req.ExpiresInDays > maxAccessTokenDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 1 and 366"})
+			return
+		}
+		expiry := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &expiry
+	}
+
+	token, secret, err := h.tokenService.Create(h.db, userID, services.CreateAccessTokenRequest{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAccessTokenRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrScopeNotGranted) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Failed to create access token for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access token"})
+		return
+	}
+	c.JSON(http.StatusCreated, CreateAccessTokenResponse{PersonalAccessToken: token, Token: secret})
+}
+
+// RevokeToken revokes one of the current user's personal access tokens.
+// DELETE /access-tokens/:id
+func (h *PersonalAccessTokenHandler) RevokeToken(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	tokenID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	if err := h.tokenService.Revoke(h.db, userID, tokenID); err != nil {
+		if errors.Is(err, services.ErrAccessTokenNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Access token not found"})
+			return
+		}
+		log.Printf("❌ Failed to revoke access token %s: %v", tokenID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Access token revoked"})
+}
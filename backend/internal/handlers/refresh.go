@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"task-manager/backend/internal/services"
 
@@ -39,7 +40,14 @@ func (h *RefreshHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	accessToken, newRefreshToken, expiresIn, err := h.authService.RefreshToken(h.db, req.RefreshToken)
+	accessToken, newRefreshToken, expiresIn, err := h.authService.RefreshToken(h.db, req.RefreshToken, clientInfo(c))
+	if errors.Is(err, services.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "refresh_token_reused",
+			"message": "This refresh token was already used. The session has been signed out; please log in again.",
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "invalid_token",
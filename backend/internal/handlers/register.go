@@ -18,10 +18,13 @@ var ErrDuplicateEmail = errors.New("email already exists")
 type RegisterHandler struct {
 	db              *gorm.DB
 	registerService services.RegisterService
+	verification    *EmailVerificationHandler
 }
 
-func NewRegisterHandler(db *gorm.DB, registerService services.RegisterService) *RegisterHandler {
-	return &RegisterHandler{db: db, registerService: registerService}
+// NewRegisterHandler creates the registration handler. New users are sent a
+// verification email through verification when it is not nil.
+func NewRegisterHandler(db *gorm.DB, registerService services.RegisterService, verification *EmailVerificationHandler) *RegisterHandler {
+	return &RegisterHandler{db: db, registerService: registerService, verification: verification}
 }
 
 type RegistrationResponse struct {
@@ -39,6 +42,8 @@ type RegistrationUserDetail struct {
 	Position   string `json:"position,omitempty"`
 	IsActive   bool   `json:"is_active"`
 	Role       string `json:"role"`
+
+	EmailVerified bool `json:"email_verified"`
 }
 
 func (h *RegisterHandler) Registration(c *gin.Context) {
@@ -88,8 +93,14 @@ func (h *RegisterHandler) Registration(c *gin.Context) {
 		return
 	}
 
+	message := "Welcome to Taskify! Your account has been created successfully."
+	if h.verification != nil {
+		h.verification.SendVerification(user)
+		message += " Please check your inbox to verify your email address."
+	}
+
 	response := RegistrationResponse{
-		Message: "Welcome to Taskify! Your account has been created successfully.",
+		Message: message,
 		User: RegistrationUserDetail{
 			ID:         user.ID.String(),
 			Username:   user.Username,
@@ -100,6 +111,8 @@ func (h *RegisterHandler) Registration(c *gin.Context) {
 			Position:   user.Position,
 			IsActive:   user.IsActive,
 			Role:       "user",
+
+			EmailVerified: user.IsEmailVerified(),
 		},
 	}
 
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultReportRange is the range reports cover when the request has no from.
+const defaultReportRange = 30 * 24 * time.Hour
+
+type ReportHandler struct {
+	db            *gorm.DB
+	reportService services.ReportService
+	authzService  services.AuthorizationService
+}
+
+func NewReportHandler(db *gorm.DB, reportService services.ReportService, authzService services.AuthorizationService) *ReportHandler {
+	return &ReportHandler{db: db, reportService: reportService, authzService: authzService}
+}
+
+// Throughput returns tasks created and completed per period
+// GET /reports/throughput?from=&to=&period=day|week|month&department=&format=csv
+func (h *ReportHandler) Throughput(c *gin.Context) {
+	filter, ok := h.authorizeReport(c)
+	if !ok {
+		return
+	}
+	filter.Period = c.DefaultQuery("period", services.ReportPeriodDay)
+
+	points, err := h.reportService.Throughput(requestDB(c, h.db), filter)
+	if err != nil {
+		handleReportError(c, err)
+		return
+	}
+
+	if wantsCSV(c) {
+		rows := [][]string{{"period", "created", "completed"}}
+		for _, point := range points {
+			rows = append(rows, []string{point.Period, itoa(point.Created), itoa(point.Completed)})
+		}
+		writeCSV(c, "throughput", rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"filter": filter, "throughput": points})
+}
+
+// TaskDurations returns lead and cycle time distributions of completed tasks
+// GET /reports/durations?from=&to=&department=&format=csv
+func (h *ReportHandler) TaskDurations(c *gin.Context) {
+	filter, ok := h.authorizeReport(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.TaskDurations(requestDB(c, h.db), filter)
+	if err != nil {
+		handleReportError(c, err)
+		return
+	}
+
+	if wantsCSV(c) {
+		rows := [][]string{{"metric", "bucket", "min_hours", "max_hours", "count"}}
+		for _, metric := range []struct {
+			name         string
+			distribution services.DurationDistribution
+		}{{"lead_time", report.LeadTime}, {"cycle_time", report.CycleTime}} {
+			for _, bucket := range metric.distribution.Buckets {
+				maxHours := ""
+				if bucket.MaxHours > 0 {
+					maxHours = strconv.Itoa(bucket.MaxHours)
+				}
+				rows = append(rows, []string{metric.name, bucket.Label, strconv.Itoa(bucket.MinHours), maxHours, itoa(bucket.Count)})
+			}
+		}
+		writeCSV(c, "durations", rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"filter": filter, "lead_time": report.LeadTime, "cycle_time": report.CycleTime})
+}
+
+// StatusBreakdown returns task counts per status for each user or department
+// GET /reports/status?group_by=user|department&department=&format=csv
+func (h *ReportHandler) StatusBreakdown(c *gin.Context) {
+	filter, ok := h.authorizeReport(c)
+	if !ok {
+		return
+	}
+	groupBy := c.DefaultQuery("group_by", services.ReportGroupByUser)
+
+	breakdown, err := h.reportService.StatusBreakdown(requestDB(c, h.db), groupBy, filter)
+	if err != nil {
+		handleReportError(c, err)
+		return
+	}
+
+	if wantsCSV(c) {
+		statusSet := map[string]bool{}
+		for _, row := range breakdown {
+			for status := range row.Counts {
+				statusSet[status] = true
+			}
+		}
+		statuses := make([]string, 0, len(statusSet))
+		for status := range statusSet {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		rows := [][]string{append(append([]string{groupBy, "label"}, statuses...), "total")}
+		for _, row := range breakdown {
+			record := []string{row.Key, row.Label}
+			for _, status := range statuses {
+				record = append(record, itoa(row.Counts[status]))
+			}
+			rows = append(rows, append(record, itoa(row.Total)))
+		}
+		writeCSV(c, "status-by-"+groupBy, rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group_by": groupBy, "breakdown": breakdown})
+}
+
+// Overdue returns counts of open tasks past their due date
+// GET /reports/overdue?department=&format=csv
+func (h *ReportHandler) Overdue(c *gin.Context) {
+	filter, ok := h.authorizeReport(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.Overdue(requestDB(c, h.db), filter, time.Now())
+	if err != nil {
+		handleReportError(c, err)
+		return
+	}
+
+	if wantsCSV(c) {
+		rows := [][]string{{"group", "key", "label", "count"}}
+		for _, count := range report.ByUser {
+			rows = append(rows, []string{services.ReportGroupByUser, count.Key, count.Label, itoa(count.Count)})
+		}
+		for _, count := range report.ByDepartment {
+			rows = append(rows, []string{services.ReportGroupByDepartment, count.Key, count.Label, itoa(count.Count)})
+		}
+		writeCSV(c, "overdue", rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// authorizeReport checks the report:read permission and parses the common
+// filter parameters, writing the error response when either fails. from and
+// to accept RFC 3339 timestamps or YYYY-MM-DD dates; to defaults to now and
+// from to 30 days before it.
+func (h *ReportHandler) authorizeReport(c *gin.Context) (services.ReportFilter, bool) {
+	if _, ok := requirePermission(c, h.authzService, "report", "read"); !ok {
+		return services.ReportFilter{}, false
+	}
+
+	filter := services.ReportFilter{Department: c.Query("department"), To: time.Now().UTC()}
+	if raw := c.Query("to"); raw != "" {
+		to, err := parseReportTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to: use RFC 3339 or YYYY-MM-DD"})
+			return filter, false
+		}
+		filter.To = to
+	}
+	filter.From = filter.To.Add(-defaultReportRange)
+	if raw := c.Query("from"); raw != "" {
+		from, err := parseReportTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from: use RFC 3339 or YYYY-MM-DD"})
+			return filter, false
+		}
+		filter.From = from
+	}
+
+	return filter, true
+}
+
+func parseReportTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+func handleReportError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidReportQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+}
+
+func wantsCSV(c *gin.Context) bool {
+	return c.Query("format") == "csv"
+}
+
+func writeCSV(c *gin.Context, name string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	for _, row := range rows {
+		w.Write(csvSafeRow(row))
+	}
+	w.Flush()
+}
+
+// csvSafeRow prefixes cells that a spreadsheet would run as a formula.
+func csvSafeRow(row []string) []string {
+	safe := make([]string, len(row))
+	for i, cell := range row {
+		if cell != "" && (cell[0] == '=' || cell[0] == '+' || cell[0] == '-' || cell[0] == '@') {
+			cell = "'" + cell
+		}
+		safe[i] = cell
+	}
+	return safe
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionHandler struct {
+	db             *gorm.DB
+	sessionService services.SessionService
+}
+
+func NewSessionHandler(db *gorm.DB, sessionService services.SessionService) *SessionHandler {
+	return &SessionHandler{db: db, sessionService: sessionService}
+}
+
+// ListSessions returns the devices the current user is logged in on.
+// GET /sessions
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	h.list(c, userID)
+}
+
+// TerminateSession logs the current user out of one session.
+// DELETE /sessions/:id
+func (h *SessionHandler) TerminateSession(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	h.terminate(c, userID)
+}
+
+// ListUserSessions returns the sessions of the user in the path.
+// GET /admin/users/:user_id/sessions
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+	userID, ok := pathUserID(c)
+	if !ok {
+		return
+	}
+	h.list(c, userID)
+}
+
+// TerminateUserSession ends one session of the user in the path.
+// DELETE /admin/users/:user_id/sessions/:id
+func (h *SessionHandler) TerminateUserSession(c *gin.Context) {
+	userID, ok := pathUserID(c)
+	if !ok {
+		return
+	}
+	h.terminate(c, userID)
+}
+
+// TerminateUserSessions ends every session of the user in the path.
+// DELETE /admin/users/:user_id/sessions
+func (h *SessionHandler) TerminateUserSessions(c *gin.Context) {
+	userID, ok := pathUserID(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.sessionService.TerminateAll(c.Request.Context(), h.db, userID)
+	if err != nil {
+		log.Printf("❌ Failed to terminate sessions of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to terminate sessions",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions terminated", "terminated": count})
+}
+
+func (h *SessionHandler) list(c *gin.Context, userID uuid.UUID) {
+	sessions, err := h.sessionService.List(h.db, userID, c.GetString("token_jti"))
+	if err != nil {
+		log.Printf("❌ Failed to list sessions of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to load sessions",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+func (h *SessionHandler) terminate(c *gin.Context, userID uuid.UUID) {
+	sessionID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "message": "Invalid session ID"})
+		return
+	}
+
+	err = h.sessionService.Terminate(c.Request.Context(), h.db, userID, sessionID)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found", "message": "Session not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to terminate session %s of user %s: %v", sessionID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "server_error",
+			"message": "Failed to terminate session",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session terminated"})
+}
+
+// pathUserID reads the :user_id route parameter, writing the error response
+// when it is not a valid ID.
+func pathUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "message": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// SharePasswordHeader carries the password of a protected share link. It is a
+// header rather than a query parameter so it stays out of access logs.
+const SharePasswordHeader = "X-Share-Password"
+
+type ShareHandler struct {
+	db           *gorm.DB
+	shareService services.ShareService
+	authzService services.AuthorizationService
+	publicURL    string
+}
+
+type CreateShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	Password  string     `json:"password"`
+}
+
+// NewShareHandler creates a share handler. publicURL is the externally visible
+// base URL used in share links; when empty it is taken from the request.
+func NewShareHandler(db *gorm.DB, shareService services.ShareService, authzService services.AuthorizationService, publicURL string) *ShareHandler {
+	return &ShareHandler{db: db, shareService: shareService, authzService: authzService, publicURL: publicURL}
+}
+
+// CreateShare mints a public read-only link to a task the caller may update.
+// The token is only returned in this response.
+// POST /tasks/:id/share
+func (h *ShareHandler) CreateShare(c *gin.Context) {
+	userID, taskID, ok := h.authorizeShare(c)
+	if !ok {
+		return
+	}
+
+	var req CreateShareRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	var expiresAt time.Time
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+
+	share, token, err := h.shareService.CreateShare(requestDB(c, h.db), taskID, userID, expiresAt, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidShare) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+
+	response := shareResponse(*share)
+	response["token"] = token
+	response["url"] = h.baseURL(c) + "/api/v1/shared/" + token
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetShares lists a task's share links, including expired and revoked ones
+// GET /tasks/:id/shares
+func (h *ShareHandler) GetShares(c *gin.Context) {
+	_, taskID, ok := h.authorizeShare(c)
+	if !ok {
+		return
+	}
+
+	shares, err := h.shareService.ListShares(requestDB(c, h.db), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get share links"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(shares))
+	for _, share := range shares {
+		response = append(response, shareResponse(share))
+	}
+	c.JSON(http.StatusOK, gin.H{"shares": response})
+}
+
+// RevokeShare disables a share link immediately
+// DELETE /tasks/:id/shares/:share_id
+func (h *ShareHandler) RevokeShare(c *gin.Context) {
+	_, taskID, ok := h.authorizeShare(c)
+	if !ok {
+		return
+	}
+
+	shareID, err := uuid.FromString(c.Param("share_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share ID"})
+		return
+	}
+
+	if err := h.shareService.RevokeShare(requestDB(c, h.db), taskID, shareID); err != nil {
+		if errors.Is(err, services.ErrShareNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked"})
+}
+
+// GetSharedTask serves the public view of a shared task. The token is the
+// credential; protected links also need the X-Share-Password header.
+// GET /shared/:token
+func (h *ShareHandler) GetSharedTask(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+
+	task, err := h.shareService.OpenShare(requestDB(c, h.db), c.Param("token"), c.GetHeader(SharePasswordHeader), services.ShareAccess{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Method:    c.Request.Method,
+		// The route pattern, so the token does not end up in the audit log.
+		Path: c.FullPath(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShareNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		case errors.Is(err, services.ErrSharePassword):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "password_required": true})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shared task"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+func (h *ShareHandler) authorizeShare(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	taskID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	// Sharing publishes the task, so it takes more than read access.
+	if !authorizeTask(c, h.authzService, userID, taskID, "update") {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, taskID, true
+}
+
+func (h *ShareHandler) baseURL(c *gin.Context) string {
+	return publicBaseURL(c, h.publicURL)
+}
+
+func shareResponse(share models.TaskShare) gin.H {
+	return gin.H{
+		"id":                share.ID,
+		"task_id":           share.TaskID,
+		"created_by":        share.CreatedBy,
+		"expires_at":        share.ExpiresAt,
+		"revoked_at":        share.RevokedAt,
+		"password_required": share.HasPassword(),
+		"active":            share.Active(time.Now()),
+		"access_count":      share.AccessCount,
+		"last_accessed_at":  share.LastAccessedAt,
+		"created_at":        share.CreatedAt,
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type SprintHandler struct {
+	db            *gorm.DB
+	sprintService services.SprintService
+	authzService  services.AuthorizationService
+}
+
+type CreateSprintRequest struct {
+	Name      string `json:"name" binding:"required"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+}
+
+type CloseSprintRequest struct {
+	RolloverTo *uuid.UUID `json:"rollover_to"`
+}
+
+type SprintTaskRequest struct {
+	TaskID uuid.UUID `json:"task_id" binding:"required"`
+}
+
+func NewSprintHandler(db *gorm.DB, sprintService services.SprintService, authzService services.AuthorizationService) *SprintHandler {
+	return &SprintHandler{db: db, sprintService: sprintService, authzService: authzService}
+}
+
+// CreateSprint plans a new sprint. Dates are YYYY-MM-DD; end_date is the last
+// day of the sprint.
+// POST /sprints
+func (h *SprintHandler) CreateSprint(c *gin.Context) {
+	userID, ok := requirePermission(c, h.authzService, "sprint", "manage")
+	if !ok {
+		return
+	}
+
+	var req CreateSprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date: use YYYY-MM-DD"})
+		return
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date: use YYYY-MM-DD"})
+		return
+	}
+
+	sprint := models.Sprint{Name: req.Name, Goal: req.Goal, StartDate: start, EndDate: end, CreatedBy: &userID}
+	if err := h.sprintService.CreateSprint(requestDB(c, h.db), &sprint); err != nil {
+		handleSprintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sprint)
+}
+
+// GetSprints lists sprints, newest first
+// GET /sprints?status=planned|active|closed
+func (h *SprintHandler) GetSprints(c *gin.Context) {
+	sprints, err := h.sprintService.ListSprints(requestDB(c, h.db), c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sprints"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sprints": sprints})
+}
+
+// GetSprint returns one sprint
+// GET /sprints/:id
+func (h *SprintHandler) GetSprint(c *gin.Context) {
+	sprintID, ok := sprintIDParam(c)
+	if !ok {
+		return
+	}
+
+	sprint, err := h.sprintService.GetSprint(requestDB(c, h.db), sprintID)
+	if err != nil {
+		handleSprintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+// StartSprint activates a planned sprint
+// POST /sprints/:id/start
+func (h *SprintHandler) StartSprint(c *gin.Context) {
+	if _, ok := requirePermission(c, h.authzService, "sprint", "manage"); !ok {
+		return
+	}
+	sprintID, ok := sprintIDParam(c)
+	if !ok {
+		return
+	}
+
+	sprint, err := h.sprintService.StartSprint(requestDB(c, h.db), sprintID, time.Now())
+	if err != nil {
+		handleSprintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+// CloseSprint closes a sprint and moves its unfinished tasks to rollover_to,
+// or out of the sprint when it is omitted
+// POST /sprints/:id/close
+func (h *SprintHandler) CloseSprint(c *gin.Context) {
+	if _, ok := requirePermission(c, h.authzService, "sprint", "manage"); !ok {
+		return
+	}
+	sprintID, ok := sprintIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req CloseSprintRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.sprintService.CloseSprint(requestDB(c, h.db), sprintID, req.RolloverTo, time.Now())
+	if err != nil {
+		handleSprintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AddTask attaches a task the caller may update to the sprint
+// POST /sprints/:id/tasks
+func (h *SprintHandler) AddTask(c *gin.Context) {
+	sprintID, ok := sprintIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req SprintTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if !h.authorizeTaskUpdate(c, req.TaskID) {
+		return
+	}
+
+	if err := h.sprintService.AddTask(requestDB(c, h.db), sprintID, req.TaskID); err != nil {
+		handleSprintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task added to sprint"})
+}
+
+// RemoveTask detaches a task from the sprint
+// DELETE /sprints/:id/tasks/:task_id
+func (h *SprintHandler) RemoveTask(c *gin.Context) {
+	sprintID, ok := sprintIDParam(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := uuid.FromString(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	if !h.authorizeTaskUpdate(c, taskID) {
+		return
+	}
+
+	if err := h.sprintService.RemoveTask(requestDB(c, h.db), sprintID, taskID); err != nil {
+		handleSprintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task removed from sprint"})
+}
+
+// GetBurndown returns the sprint's daily remaining work next to the ideal line
+// GET /sprints/:id/burndown
+func (h *SprintHandler) GetBurndown(c *gin.Context) {
+	sprintID, ok := sprintIDParam(c)
+	if !ok {
+		return
+	}
+
+	burndown, err := h.sprintService.Burndown(requestDB(c, h.db), sprintID)
+	if err != nil {
+		handleSprintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, burndown)
+}
+
+func (h *SprintHandler) authorizeTaskUpdate(c *gin.Context, taskID uuid.UUID) bool {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return false
+	}
+	return authorizeTask(c, h.authzService, userID, taskID, "update")
+}
+
+func sprintIDParam(c *gin.Context) (uuid.UUID, bool) {
+	sprintID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sprint ID"})
+		return uuid.Nil, false
+	}
+	return sprintID, true
+}
+
+func handleSprintError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSprintNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sprint not found"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, services.ErrSprintClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidSprint):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process sprint request"})
+	}
+}
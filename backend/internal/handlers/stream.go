@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// streamAuthzTTL bounds how long a per-connection read decision is reused, so
+// permission changes reach open streams without re-checking on every event.
+const streamAuthzTTL = time.Minute
+
+type StreamHandler struct {
+	db           *gorm.DB
+	stream       services.EventStream
+	authzService services.AuthorizationService
+	heartbeat    time.Duration
+}
+
+func NewStreamHandler(db *gorm.DB, stream services.EventStream, authzService services.AuthorizationService, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 25 * time.Second
+	}
+	return &StreamHandler{db: db, stream: stream, authzService: authzService, heartbeat: heartbeat}
+}
+
+// Stream pushes task events the caller may read as Server-Sent Events
+// GET /stream
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	lastEventID, err := parseLastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+		return
+	}
+
+	// The server write timeout would otherwise cut long-lived streams off.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Failed to clear write deadline for stream: %v", err)
+	}
+
+	sub := h.stream.Subscribe(lastEventID)
+	defer h.stream.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// The stream outlives the request; its connection pinned for the
+	// workspace goes back to the pool now and after every check.
+	ctx := c.Request.Context()
+	services.ReleaseWorkspaceSession(ctx)
+	filter := newStreamFilter(h.authzService, userID)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+	if sub.Truncated {
+		fmt.Fprint(c.Writer, "event: stream.reset\ndata: {}\n\n")
+	}
+	for _, ev := range sub.Replay {
+		if err := h.writeEvent(ctx, c, filter, ev); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if err := h.writeEvent(ctx, c, filter, ev); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func (h *StreamHandler) writeEvent(ctx context.Context, c *gin.Context, filter *streamFilter, ev services.StreamEvent) error {
+	if !filter.allowed(ctx, ev) {
+		return nil
+	}
+
+	data, err := json.Marshal(ev.Event)
+	if err != nil {
+		log.Printf("Failed to marshal stream event %d: %v", ev.ID, err)
+		return nil
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Event.Type, data)
+	return err
+}
+
+// parseLastEventID reads the resume position from the Last-Event-ID header
+// that EventSource sends on reconnect, or the last_event_id query parameter
+// for clients that cannot set headers.
+func parseLastEventID(c *gin.Context) (uint64, error) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+type streamDecision struct {
+	allowed   bool
+	checkedAt time.Time
+}
+
+// streamFilter decides which events a single connection may see. Deleted tasks
+// can no longer be looked up, so their events go to the owner, to anyone the
+// authorization service still allows (admins), and to connections that were
+// already allowed to read the task.
+type streamFilter struct {
+	authzService services.AuthorizationService
+	userID       uuid.UUID
+	decisions    map[uuid.UUID]streamDecision
+}
+
+func newStreamFilter(authzService services.AuthorizationService, userID uuid.UUID) *streamFilter {
+	return &streamFilter{
+		authzService: authzService,
+		userID:       userID,
+		decisions:    make(map[uuid.UUID]streamDecision),
+	}
+}
+
+func (f *streamFilter) allowed(ctx context.Context, ev services.StreamEvent) bool {
+	event := ev.Event
+	if event.OwnerID == f.userID {
+		return true
+	}
+
+	previous, seen := f.decisions[event.TaskID]
+	if seen && time.Since(previous.checkedAt) < streamAuthzTTL {
+		return previous.allowed
+	}
+
+	defer services.ReleaseWorkspaceSession(ctx)
+	taskID := event.TaskID
+	decision, err := f.authzService.IsAuthorized(ctx, services.AuthorizationRequest{
+		UserID:     f.userID,
+		Resource:   "task",
+		Action:     "read",
+		ResourceID: &taskID,
+	})
+	if err != nil {
+		log.Printf("Stream authorization check failed for task %s: %v", taskID, err)
+		return false
+	}
+
+	allowed := decision.Decision == "allowed"
+	if !allowed && event.Type == models.TaskEventDeleted && seen && previous.allowed {
+		allowed = true
+	}
+
+	f.decisions[event.TaskID] = streamDecision{allowed: allowed, checkedAt: time.Now()}
+	return allowed
+}
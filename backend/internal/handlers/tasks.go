@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/services"
@@ -15,6 +18,7 @@ import (
 type TaskHandler struct {
 	db          *gorm.DB
 	taskService services.TaskService
+	events      services.TaskEventPublisher
 }
 
 func (h *TaskHandler) CreateTask(c *gin.Context) {
@@ -31,9 +35,13 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	}
 
 	var taskInput struct {
-		Title       string `json:"title" binding:"required"`
-		Description string `json:"description"`
-		Status      string `json:"status"`
+		Title          string     `json:"title" binding:"required"`
+		Description    string     `json:"description"`
+		Status         string     `json:"status"`
+		DueDate        *time.Time `json:"due_date"`
+		RecurrenceRule string     `json:"recurrence_rule"`
+		Labels         []string   `json:"labels"`
+		EstimatedHours *float64   `json:"estimated_hours" binding:"omitempty,gte=0,lte=1000"`
 	}
 	if err := c.ShouldBindJSON(&taskInput); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -44,6 +52,16 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		taskInput.Status = "pending"
 	}
 
+	schedule, msg := parseTaskSchedule(taskInput.DueDate, taskInput.RecurrenceRule, taskInput.Labels)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if schedule.RecurrenceRule != "" && schedule.DueDate == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recurrence_rule requires a due_date"})
+		return
+	}
+
 	taskID, err := uuid.NewV4()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -54,13 +72,29 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	}
 
 	task := models.Task{
-		ID:          taskID,
-		UserID:      uuid.FromStringOrNil(userIDStr),
-		Title:       taskInput.Title,
-		Description: taskInput.Description,
-		Status:      taskInput.Status,
+		ID:             taskID,
+		UserID:         uuid.FromStringOrNil(userIDStr),
+		Title:          taskInput.Title,
+		Description:    taskInput.Description,
+		Status:         taskInput.Status,
+		DueDate:        schedule.DueDate,
+		RecurrenceRule: schedule.RecurrenceRule,
+		Labels:         schedule.Labels,
+		EstimatedHours: taskInput.EstimatedHours,
 	}
-	err = h.taskService.CreateTask(h.db, task)
+	err = h.withTaskEvent(c, func(tx *gorm.DB) (*models.TaskEvent, error) {
+		if err := h.taskService.CreateTask(tx, task); err != nil {
+			return nil, err
+		}
+		return &models.TaskEvent{
+			Type:        models.TaskEventCreated,
+			TaskID:      task.ID,
+			WorkspaceID: task.WorkspaceID,
+			OwnerID:     task.UserID,
+			ActorID:     task.UserID,
+			Title:       task.Title,
+		}, nil
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "failed to create task",
@@ -68,53 +102,115 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		})
 		return
 	}
+
 	c.JSON(http.StatusCreated, task)
 }
 
-func NewTaskHandler(db *gorm.DB, taskService services.TaskService) *TaskHandler {
-	return &TaskHandler{db: db, taskService: taskService}
+// NewTaskHandler creates a task handler. events receives each change inside the
+// transaction that writes it, so it should be the outbox rather than a direct
+// publisher. events may be nil, in which case task changes are not published.
+func NewTaskHandler(db *gorm.DB, taskService services.TaskService, events services.TaskEventPublisher) *TaskHandler {
+	return &TaskHandler{db: db, taskService: taskService, events: events}
 }
 
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	idStr := c.Param("id")
 	id := uuid.FromStringOrNil(idStr)
 	var taskInput struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Status      string `json:"status"`
+		Title          string     `json:"title"`
+		Description    string     `json:"description"`
+		Status         string     `json:"status"`
+		DueDate        *time.Time `json:"due_date"`
+		RecurrenceRule string     `json:"recurrence_rule"`
+		Labels         []string   `json:"labels"`
+		EstimatedHours *float64   `json:"estimated_hours" binding:"omitempty,gte=0,lte=1000"`
 	}
 	if err := c.ShouldBindJSON(&taskInput); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+
+	schedule, msg := parseTaskSchedule(taskInput.DueDate, taskInput.RecurrenceRule, taskInput.Labels)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
 	updated := models.Task{
-		Title:       taskInput.Title,
-		Description: taskInput.Description,
-		Status:      taskInput.Status,
+		Title:          taskInput.Title,
+		Description:    taskInput.Description,
+		Status:         taskInput.Status,
+		DueDate:        schedule.DueDate,
+		RecurrenceRule: schedule.RecurrenceRule,
+		Labels:         schedule.Labels,
+		EstimatedHours: taskInput.EstimatedHours,
 	}
-	err := h.taskService.UpdateTask(h.db, id, updated)
+
+	err := h.withTaskEvent(c, func(tx *gorm.DB) (*models.TaskEvent, error) {
+		var previous models.Task
+		if h.events != nil {
+			var err error
+			previous, err = h.taskService.GetTaskByID(tx, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if err := h.taskService.UpdateTask(tx, id, updated); err != nil {
+			return nil, err
+		}
+
+		event := taskUpdateEvent(c, previous, updated)
+		return &event, nil
+	})
 	if err != nil {
 		handleTaskError(c, err)
 		return
 	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "task updated successfully"})
 }
 
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	idStr := c.Param("id")
 	id := uuid.FromStringOrNil(idStr)
-	err := h.taskService.DeleteTask(h.db, id)
+
+	err := h.withTaskEvent(c, func(tx *gorm.DB) (*models.TaskEvent, error) {
+		var previous models.Task
+		if h.events != nil {
+			var err error
+			previous, err = h.taskService.GetTaskByID(tx, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if err := h.taskService.DeleteTask(tx, id); err != nil {
+			return nil, err
+		}
+
+		actorID, _ := currentUserID(c)
+		return &models.TaskEvent{
+			Type:        models.TaskEventDeleted,
+			TaskID:      previous.ID,
+			WorkspaceID: previous.WorkspaceID,
+			OwnerID:     previous.UserID,
+			ActorID:     actorID,
+			Title:       previous.Title,
+		}, nil
+	})
 	if err != nil {
 		handleTaskError(c, err)
 		return
 	}
+
 	c.JSON(http.StatusNoContent, nil)
 }
 
 func (h *TaskHandler) GetTaskByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id := uuid.FromStringOrNil(idStr)
-	task, err := h.taskService.GetTaskByID(h.db, id)
+	task, err := h.taskService.GetTaskByID(requestDB(c, h.db), id)
 	if err != nil {
 		handleTaskError(c, err)
 		return
@@ -126,7 +222,7 @@ func (h *TaskHandler) GetTasksByUser(c *gin.Context) {
 	userIDStr := c.Param("user_id")
 	userID := uuid.FromStringOrNil(userIDStr)
 	var tasks []models.Task
-	result := h.db.Where("user_id = ?", userID).Find(&tasks)
+	result := requestDB(c, h.db).Where("user_id = ?", userID).Find(&tasks)
 	if result.Error != nil {
 		handleTaskError(c, result.Error)
 		return
@@ -141,7 +237,7 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 	page := c.DefaultQuery("page", "1")
 	pageSize := c.DefaultQuery("pageSize", "10")
 
-	tasks, total, err := h.taskService.GetTasksPaginated(h.db, sortBy, order, page, pageSize)
+	tasks, total, err := h.taskService.GetTasksPaginated(requestDB(c, h.db), sortBy, order, page, pageSize)
 	if err != nil {
 		handleTaskError(c, err)
 		return
@@ -152,6 +248,86 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 	})
 }
 
+// withTaskEvent runs write and records the event it returns in one
+// transaction, so the change and its event are committed or lost together.
+// Without a publisher the write runs on its own.
+func (h *TaskHandler) withTaskEvent(c *gin.Context, write func(tx *gorm.DB) (*models.TaskEvent, error)) error {
+	db := requestDB(c, h.db)
+	if h.events == nil {
+		_, err := write(db)
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		event, err := write(tx)
+		if err != nil {
+			return err
+		}
+		if err := h.events.PublishTaskEvent(tx, *event); err != nil {
+			return fmt.Errorf("failed to record %s event: %w", event.Type, err)
+		}
+		return nil
+	})
+}
+
+func taskUpdateEvent(c *gin.Context, previous, updated models.Task) models.TaskEvent {
+	actorID, _ := currentUserID(c)
+
+	event := models.TaskEvent{
+		Type:        models.TaskEventUpdated,
+		TaskID:      previous.ID,
+		WorkspaceID: previous.WorkspaceID,
+		OwnerID:     previous.UserID,
+		ActorID:     actorID,
+		Title:       previous.Title,
+		Changes:     map[string]string{},
+	}
+
+	if updated.Title != "" && updated.Title != previous.Title {
+		event.Title = updated.Title
+		event.Changes["title"] = updated.Title
+	}
+	if updated.Description != "" && updated.Description != previous.Description {
+		event.Changes["description"] = updated.Description
+	}
+	if updated.DueDate != nil && (previous.DueDate == nil || !updated.DueDate.Equal(*previous.DueDate)) {
+		event.Changes["due_date"] = updated.DueDate.UTC().Format(time.RFC3339)
+	}
+	if updated.Status != "" && updated.Status != previous.Status {
+		event.Type = models.TaskEventStatusChanged
+		event.Changes["status"] = updated.Status
+		event.Changes["previous_status"] = previous.Status
+	}
+
+	return event
+}
+
+// parseTaskSchedule validates the optional due date, recurrence rule and
+// labels shared by create and update. It returns a message for invalid input.
+func parseTaskSchedule(dueDate *time.Time, recurrenceRule string, labels []string) (models.Task, string) {
+	var task models.Task
+
+	task.DueDate = dueDate
+
+	rule, err := services.NormalizeRecurrenceRule(recurrenceRule)
+	if err != nil {
+		return task, err.Error()
+	}
+	task.RecurrenceRule = rule
+
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || strings.Contains(label, ",") || len(label) > 50 {
+			return task, "labels must be 1-50 characters and may not contain commas"
+		}
+		if !task.Labels.Contains(label) {
+			task.Labels = append(task.Labels, label)
+		}
+	}
+
+	return task, ""
+}
+
 func handleTaskError(c *gin.Context, err error) {
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -76,7 +76,7 @@ func (m *MockTaskService) DeleteTask(db *gorm.DB, id uuid.UUID) error {
 func setupTaskHandler() (*handlers.TaskHandler, *MockTaskService, *gin.Engine) {
 	gin.SetMode(gin.TestMode)
 	mockService := &MockTaskService{}
-	handler := handlers.NewTaskHandler(nil, mockService)
+	handler := handlers.NewTaskHandler(nil, mockService, nil)
 	router := gin.New()
 
 	// Add mock authentication middleware
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type TeamHandler struct {
+	db           *gorm.DB
+	teamService  services.TeamService
+	authzService services.AuthorizationService
+}
+
+type CreateTeamRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type AddTeamMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	IsLead bool      `json:"is_lead"`
+}
+
+type TeamTaskRequest struct {
+	TaskID uuid.UUID `json:"task_id" binding:"required"`
+}
+
+func NewTeamHandler(db *gorm.DB, teamService services.TeamService, authzService services.AuthorizationService) *TeamHandler {
+	return &TeamHandler{db: db, teamService: teamService, authzService: authzService}
+}
+
+// GetTeams lists the workspace's teams
+// GET /teams
+func (h *TeamHandler) GetTeams(c *gin.Context) {
+	teams, err := h.teamService.ListTeams(requestDB(c, h.db))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get teams"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"teams": teams})
+}
+
+// CreateTeam creates a team in the workspace
+// POST /teams
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	if _, ok := requirePermission(c, h.authzService, "team", "manage"); !ok {
+		return
+	}
+
+	var req CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	team := models.Team{Name: req.Name, Description: req.Description}
+	if err := h.teamService.CreateTeam(requestDB(c, h.db), &team); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+// GetTeam returns one team
+// GET /teams/:id
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+
+	team, err := h.teamService.GetTeam(requestDB(c, h.db), teamID)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// DeleteTeam deletes a team. Its tasks leave the team but are kept.
+// DELETE /teams/:id
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	if _, ok := requirePermission(c, h.authzService, "team", "manage"); !ok {
+		return
+	}
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.DeleteTeam(requestDB(c, h.db), teamID); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team deleted"})
+}
+
+// GetMembers lists the team's members, leads first
+// GET /teams/:id/members
+func (h *TeamHandler) GetMembers(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.teamService.ListMembers(requestDB(c, h.db), teamID)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// AddMember adds a workspace member to the team or changes whether they lead
+// it. Team leads may add members; appointing leads takes team:manage.
+// POST /teams/:id/members
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req AddTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	_, member, canManage, ok := h.teamAccess(c, teamID)
+	if !ok {
+		return
+	}
+	if !canManage && (member == nil || !member.IsLead || req.IsLead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "only team leads may add members and only team managers may appoint leads"})
+		return
+	}
+
+	added, err := h.teamService.AddMember(requestDB(c, h.db), teamID, req.UserID, req.IsLead)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, added)
+}
+
+// RemoveMember removes a user from the team. Members may leave; removing
+// others takes a team lead or team:manage.
+// DELETE /teams/:id/members/:user_id
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+	targetID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userID, member, canManage, ok := h.teamAccess(c, teamID)
+	if !ok {
+		return
+	}
+	if targetID != userID && !canManage && (member == nil || !member.IsLead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "only team leads may remove other members"})
+		return
+	}
+
+	if err := h.teamService.RemoveMember(requestDB(c, h.db), teamID, targetID); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// AddTask hands a task the caller may update to a team they belong to
+// POST /teams/:id/tasks
+func (h *TeamHandler) AddTask(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req TeamTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID, ok := h.requireMember(c, teamID)
+	if !ok || !authorizeTask(c, h.authzService, userID, req.TaskID, "update") {
+		return
+	}
+
+	if err := h.teamService.AddTask(requestDB(c, h.db), teamID, req.TaskID); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task added to team"})
+}
+
+// RemoveTask takes a task away from the team
+// DELETE /teams/:id/tasks/:task_id
+func (h *TeamHandler) RemoveTask(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+	taskID, err := uuid.FromString(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	if !authorizeTask(c, h.authzService, userID, taskID, "update") {
+		return
+	}
+
+	if err := h.teamService.RemoveTask(requestDB(c, h.db), teamID, taskID); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task removed from team"})
+}
+
+// GetQueue lists the team's open tasks that nobody has claimed yet
+// GET /teams/:id/queue
+func (h *TeamHandler) GetQueue(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.requireMember(c, teamID); !ok {
+		return
+	}
+
+	tasks, err := h.teamService.Queue(requestDB(c, h.db), teamID)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// ClaimTask assigns a queued task to the calling team member
+// POST /teams/:id/queue/:task_id/claim
+func (h *TeamHandler) ClaimTask(c *gin.Context) {
+	teamID, ok := teamIDParam(c)
+	if !ok {
+		return
+	}
+	taskID, err := uuid.FromString(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	userID, member, _, ok := h.teamAccess(c, teamID)
+	if !ok {
+		return
+	}
+	if member == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "only team members may claim team tasks"})
+		return
+	}
+
+	task, err := h.teamService.ClaimTask(requestDB(c, h.db), teamID, taskID, userID)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// teamAccess returns the caller, their membership in the team (nil when they
+// are not in it) and whether they hold team:manage, writing the error
+// response when the lookup fails.
+func (h *TeamHandler) teamAccess(c *gin.Context, teamID uuid.UUID) (uuid.UUID, *models.TeamMember, bool, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, nil, false, false
+	}
+
+	db := requestDB(c, h.db)
+	if _, err := h.teamService.GetTeam(db, teamID); err != nil {
+		handleTeamError(c, err)
+		return uuid.Nil, nil, false, false
+	}
+
+	member, err := h.teamService.Membership(db, teamID, userID)
+	if err != nil && !errors.Is(err, services.ErrNotTeamMember) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check team membership"})
+		return uuid.Nil, nil, false, false
+	}
+
+	canManage, err := h.authzService.HasPermission(c.Request.Context(), userID, "team", "manage")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
+		return uuid.Nil, nil, false, false
+	}
+
+	return userID, member, canManage, true
+}
+
+// requireMember checks that the caller is in the team or holds team:manage,
+// writing the error response when not.
+func (h *TeamHandler) requireMember(c *gin.Context, teamID uuid.UUID) (uuid.UUID, bool) {
+	userID, member, canManage, ok := h.teamAccess(c, teamID)
+	if !ok {
+		return uuid.Nil, false
+	}
+	if member == nil && !canManage {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "not a member of this team"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func teamIDParam(c *gin.Context) (uuid.UUID, bool) {
+	teamID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return uuid.Nil, false
+	}
+	return teamID, true
+}
+
+func handleTeamError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTeamNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+	case errors.Is(err, services.ErrNotTeamMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.Is(err, services.ErrTaskAlreadyTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTeam):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process team request"})
+	}
+}
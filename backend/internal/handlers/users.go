@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"task-manager/backend/internal/services"
 	"task-manager/backend/internal/utils"
@@ -42,7 +41,7 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 		ResourceID: &currentUserUUID,
 	}
 
-	decision, err := h.authzService.IsAuthorized(context.Background(), authRequest)
+	decision, err := h.authzService.IsAuthorized(c.Request.Context(), authRequest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
 		return
@@ -53,7 +52,7 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserProfile(h.db, currentUserUUID)
+	user, err := h.userService.GetUserProfile(requestDB(c, h.db), currentUserUUID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
@@ -109,7 +108,7 @@ func (h *UserHandler) GetUserProfileByUserId(c *gin.Context) {
 		ResourceID: &targetUserID,
 	}
 
-	decision, err := h.authzService.IsAuthorized(context.Background(), authRequest)
+	decision, err := h.authzService.IsAuthorized(c.Request.Context(), authRequest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
 		return
@@ -120,7 +119,7 @@ func (h *UserHandler) GetUserProfileByUserId(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserProfile(h.db, targetUserID)
+	user, err := h.userService.GetUserProfile(requestDB(c, h.db), targetUserID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
@@ -162,7 +161,7 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		Action:   "list",
 	}
 
-	decision, err := h.authzService.IsAuthorized(context.Background(), authRequest)
+	decision, err := h.authzService.IsAuthorized(c.Request.Context(), authRequest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
 		return
@@ -173,7 +172,7 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	users, err := h.userService.GetUsers(h.db)
+	users, err := h.userService.GetUsers(requestDB(c, h.db))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users"})
 		return
@@ -227,7 +226,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		ResourceID: &targetUserID,
 	}
 
-	decision, err := h.authzService.IsAuthorized(context.Background(), authRequest)
+	decision, err := h.authzService.IsAuthorized(c.Request.Context(), authRequest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
 		return
@@ -238,7 +237,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.DeleteUser(h.db, targetUserID)
+	err = h.userService.DeleteUser(requestDB(c, h.db), targetUserID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
@@ -290,7 +289,7 @@ func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
 		ResourceID: &targetUserID,
 	}
 
-	decision, err := h.authzService.IsAuthorized(context.Background(), authRequest)
+	decision, err := h.authzService.IsAuthorized(c.Request.Context(), authRequest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
 		return
@@ -301,7 +300,7 @@ func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.UpdateUser(h.db, targetUserID, updateData)
+	err = h.userService.UpdateUser(requestDB(c, h.db), targetUserID, updateData)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
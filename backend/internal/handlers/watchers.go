@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type WatcherHandler struct {
+	db             *gorm.DB
+	watcherService services.WatcherService
+	authzService   services.AuthorizationService
+}
+
+func NewWatcherHandler(db *gorm.DB, watcherService services.WatcherService, authzService services.AuthorizationService) *WatcherHandler {
+	return &WatcherHandler{db: db, watcherService: watcherService, authzService: authzService}
+}
+
+// WatchTask subscribes the current user to a task
+// POST /tasks/:id/watch
+func (h *WatcherHandler) WatchTask(c *gin.Context) {
+	userID, taskID, ok := authorizeTaskRead(c, h.authzService)
+	if !ok {
+		return
+	}
+
+	if err := h.watcherService.Watch(requestDB(c, h.db), taskID, userID, models.WatchReasonManual); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to watch task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task watched", "task_id": taskID, "watching": true})
+}
+
+// UnwatchTask unsubscribes the current user from a task
+// DELETE /tasks/:id/watch
+func (h *WatcherHandler) UnwatchTask(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	taskID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	if err := h.watcherService.Unwatch(requestDB(c, h.db), taskID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unwatch task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task unwatched", "task_id": taskID, "watching": false})
+}
+
+// GetTaskWatchers lists everyone following a task
+// GET /tasks/:id/watchers
+func (h *WatcherHandler) GetTaskWatchers(c *gin.Context) {
+	userID, taskID, ok := authorizeTaskRead(c, h.authzService)
+	if !ok {
+		return
+	}
+
+	watchers, err := h.watcherService.GetWatchers(requestDB(c, h.db), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get watchers"})
+		return
+	}
+
+	watching := false
+	for _, w := range watchers {
+		if w.UserID == userID {
+			watching = true
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task_id":  taskID,
+		"watchers": watchers,
+		"watching": watching,
+	})
+}
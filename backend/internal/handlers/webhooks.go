@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	db             *gorm.DB
+	webhookService services.WebhookService
+	authzService   services.AuthorizationService
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	Secret     string   `json:"secret"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url"`
+	EventTypes []string `json:"event_types"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+func NewWebhookHandler(db *gorm.DB, webhookService services.WebhookService, authzService services.AuthorizationService) *WebhookHandler {
+	return &WebhookHandler{db: db, webhookService: webhookService, authzService: authzService}
+}
+
+// CreateWebhook subscribes a URL to task events. The signing secret is only
+// returned in this response.
+// POST /webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if msg := validateWebhookRequest(req.URL, req.EventTypes); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	webhook := models.Webhook{UserID: userID, URL: req.URL, Secret: req.Secret}
+	webhook.SetEvents(req.EventTypes)
+
+	if err := h.webhookService.CreateWebhook(requestDB(c, h.db), &webhook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	response := webhookResponse(webhook)
+	response["secret"] = webhook.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetWebhooks lists the caller's webhooks; admins see every webhook
+// GET /webhooks
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	isAdmin, err := h.authzService.HasRole(c.Request.Context(), userID, "admin")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
+		return
+	}
+
+	owner := &userID
+	if isAdmin {
+		owner = nil
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(requestDB(c, h.db), owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhooks"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, webhookResponse(webhook))
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": response})
+}
+
+// GetWebhook returns one webhook
+// GET /webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(*webhook))
+}
+
+// UpdateWebhook changes the URL or events, or pauses/resumes a webhook.
+// Re-enabling clears the failure streak that disabled it.
+// PUT /webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		webhook.SetEvents(req.EventTypes)
+	}
+	if msg := validateWebhookRequest(webhook.URL, webhook.Events()); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if req.IsActive != nil {
+		if *req.IsActive && !webhook.IsActive {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledAt = nil
+			webhook.DisabledReason = ""
+		}
+		webhook.IsActive = *req.IsActive
+	}
+
+	if err := h.webhookService.UpdateWebhook(requestDB(c, h.db), webhook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(*webhook))
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+// DELETE /webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(requestDB(c, h.db), webhook.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// GetDeliveries returns the delivery log, newest first
+// GET /webhooks/:id/deliveries?page=1&pageSize=20
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	deliveries, total, err := h.webhookService.GetDeliveries(requestDB(c, h.db), webhook.ID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries, "total": total})
+}
+
+// Redeliver queues a delivery again with its original payload
+// POST /webhooks/:id/deliveries/:delivery_id/redeliver
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.FromString(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(requestDB(c, h.db), webhook.ID, deliveryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		case errors.Is(err, services.ErrWebhookQueueUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook delivery is unavailable"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// loadWebhook fetches the :id webhook if the caller owns it or is an admin.
+// Other users get a 404 so webhook IDs are not disclosed.
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*models.Webhook, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return nil, false
+	}
+
+	webhook, err := h.webhookService.GetWebhook(requestDB(c, h.db), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook"})
+		return nil, false
+	}
+
+	if webhook.UserID != userID {
+		isAdmin, err := h.authzService.HasRole(c.Request.Context(), userID, "admin")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
+			return nil, false
+		}
+		if !isAdmin {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil, false
+		}
+	}
+
+	return webhook, true
+}
+
+func validateWebhookRequest(rawURL string, eventTypes []string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "URL must be an absolute http or https URL"
+	}
+
+	if len(eventTypes) == 0 {
+		return "At least one event type is required"
+	}
+	for _, eventType := range eventTypes {
+		if eventType != "*" && !taskEventTypes[eventType] {
+			return "Unknown event type: " + eventType
+		}
+	}
+	return ""
+}
+
+func webhookResponse(webhook models.Webhook) gin.H {
+	return gin.H{
+		"id":                   webhook.ID,
+		"user_id":              webhook.UserID,
+		"url":                  webhook.URL,
+		"event_types":          webhook.Events(),
+		"is_active":            webhook.IsActive,
+		"consecutive_failures": webhook.ConsecutiveFailures,
+		"disabled_at":          webhook.DisabledAt,
+		"disabled_reason":      webhook.DisabledReason,
+		"created_at":           webhook.CreatedAt,
+		"updated_at":           webhook.UpdatedAt,
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WorkloadHandler struct {
+	db              *gorm.DB
+	workloadService services.WorkloadService
+	authzService    services.AuthorizationService
+}
+
+func NewWorkloadHandler(db *gorm.DB, workloadService services.WorkloadService, authzService services.AuthorizationService) *WorkloadHandler {
+	return &WorkloadHandler{db: db, workloadService: workloadService, authzService: authzService}
+}
+
+// GetWorkload returns open, overdue and estimated work per user, grouped by
+// department. Holders of task:read with the "all" scope may view any
+// department; the "department" scope is limited to the caller's own.
+// GET /workload?department=
+func (h *WorkloadHandler) GetWorkload(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	scopes, err := h.authzService.PermissionScopes(c.Request.Context(), userID, "task", "read")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authorization check failed"})
+		return
+	}
+
+	department := c.Query("department")
+	switch {
+	case hasScope(scopes, "all"):
+	case hasScope(scopes, "department"):
+		var user models.User
+		if err := requestDB(c, h.db).Select("department").Where("id = ?", userID).First(&user).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+			return
+		}
+		if user.Department == "" || (department != "" && department != user.Department) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "Workload is limited to your own department"})
+			return
+		}
+		department = user.Department
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions", "required_permission": "task:read", "required_scope": "department"})
+		return
+	}
+
+	workload, err := h.workloadService.Workload(requestDB(c, h.db), department, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workload"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"departments": workload})
+}
+
+// hasScope reports whether scopes contains scope. "*", the column default for
+// permissions created without a scope, matches everything.
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+	return false
+}
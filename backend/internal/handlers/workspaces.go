@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type WorkspaceHandler struct {
+	db               *gorm.DB
+	workspaceService services.WorkspaceService
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type AddWorkspaceMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role"`
+}
+
+func NewWorkspaceHandler(db *gorm.DB, workspaceService services.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{db: db, workspaceService: workspaceService}
+}
+
+// GetWorkspaces lists the caller's workspaces with their role in each
+// GET /workspaces
+func (h *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaces, err := h.workspaceService.ListWorkspaces(h.db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workspaces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspaces": workspaces})
+}
+
+// CreateWorkspace creates a workspace owned by the caller
+// POST /workspaces
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(h.db, req.Name, userID)
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// GetMembers lists the members of a workspace the caller belongs to
+// GET /workspaces/:id/members
+func (h *WorkspaceHandler) GetMembers(c *gin.Context) {
+	workspaceID, _, ok := h.membership(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaceService.ListMembers(h.db, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workspace members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// AddMember adds a user to the workspace or changes their role. Owners and
+// admins may manage members; only owners may appoint owners.
+// POST /workspaces/:id/members
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	workspaceID, caller, ok := h.membership(c)
+	if !ok {
+		return
+	}
+
+	var req AddWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if req.Role == models.WorkspaceRoleOwner && caller.Role != models.WorkspaceRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "only owners may appoint owners"})
+		return
+	}
+	if !h.canManage(c, workspaceID, caller, req.UserID) {
+		return
+	}
+
+	member, err := h.workspaceService.AddMember(h.db, workspaceID, req.UserID, req.Role)
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a user from the workspace. Members may remove
+// themselves; removing others takes an owner or admin.
+// DELETE /workspaces/:id/members/:user_id
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	workspaceID, caller, ok := h.membership(c)
+	if !ok {
+		return
+	}
+
+	targetID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if targetID != caller.UserID && !h.canManage(c, workspaceID, caller, targetID) {
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(h.db, workspaceID, targetID); err != nil {
+		handleWorkspaceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// membership resolves the :id workspace and the caller's membership in it.
+// Non-members get 404 so workspace IDs cannot be probed.
+func (h *WorkspaceHandler) membership(c *gin.Context) (uuid.UUID, *models.WorkspaceMember, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, nil, false
+	}
+
+	workspaceID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return uuid.Nil, nil, false
+	}
+
+	member, err := h.workspaceService.Membership(h.db, workspaceID, userID)
+	if err != nil {
+		handleWorkspaceError(c, err)
+		return uuid.Nil, nil, false
+	}
+	return workspaceID, member, true
+}
+
+// canManage checks that caller may change targetID's membership, writing the
+// error response when not. Admins manage members and admins but not owners.
+func (h *WorkspaceHandler) canManage(c *gin.Context, workspaceID uuid.UUID, caller *models.WorkspaceMember, targetID uuid.UUID) bool {
+	if !caller.CanManage() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "workspace role does not allow managing members"})
+		return false
+	}
+	if caller.Role == models.WorkspaceRoleOwner {
+		return true
+	}
+
+	target, err := h.workspaceService.Membership(h.db, workspaceID, targetID)
+	if errors.Is(err, services.ErrNotWorkspaceMember) {
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Workspace operation failed"})
+		return false
+	}
+	if target.Role == models.WorkspaceRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "reason": "only owners may change owners"})
+		return false
+	}
+	return true
+}
+
+func handleWorkspaceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotWorkspaceMember), errors.Is(err, services.ErrWorkspaceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace or member not found"})
+	case errors.Is(err, services.ErrInvalidWorkspace):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLastWorkspaceOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Workspace operation failed"})
+	}
+}
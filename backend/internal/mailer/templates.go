@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// TemplateData is passed to every template. Data holds the job's
+// template-specific values.
+type TemplateData struct {
+	Subject        string
+	AppURL         string
+	UnsubscribeURL string
+	Data           map[string]interface{}
+}
+
+// Renderer renders the embedded email templates. Each template NAME has a
+// NAME.txt file defining "subject" and "text" blocks and a NAME.html file
+// defining an "html" block; both are wrapped in the shared layouts.
+type Renderer struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+func NewRenderer() (*Renderer, error) {
+	names, err := fs.Glob(templateFS, "templates/*.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+	for _, file := range names {
+		name := strings.TrimSuffix(path.Base(file), ".txt")
+		if name == "layout" {
+			continue
+		}
+
+		text, err := texttemplate.New("layout.txt").ParseFS(templateFS, "templates/layout.txt", file)
+		if err != nil {
+			return nil, err
+		}
+		html, err := htmltemplate.New("layout.html").ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+		r.text[name] = text
+		r.html[name] = html
+	}
+
+	return r, nil
+}
+
+// Has reports whether a template exists.
+func (r *Renderer) Has(name string) bool {
+	_, ok := r.text[name]
+	return ok
+}
+
+// Render executes a template, returning its subject and both bodies. The
+// template's "subject" block is used unless data.Subject is already set.
+func (r *Renderer) Render(name string, data TemplateData) (subject, text, html string, err error) {
+	textTemplate, ok := r.text[name]
+	if !ok {
+		return "", "", "", ErrUnknownTemplate
+	}
+
+	var buf bytes.Buffer
+	if data.Subject == "" {
+		if err := textTemplate.ExecuteTemplate(&buf, "subject", data); err != nil {
+			return "", "", "", err
+		}
+		data.Subject = strings.Join(strings.Fields(buf.String()), " ")
+	}
+
+	buf.Reset()
+	if err := textTemplate.Execute(&buf, data); err != nil {
+		return "", "", "", err
+	}
+	text = buf.String()
+
+	buf.Reset()
+	if err := r.html[name].Execute(&buf, data); err != nil {
+		return "", "", "", err
+	}
+	html = buf.String()
+
+	return data.Subject, text, html, nil
+}
@@ -1,23 +1,90 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// WorkspaceHeader selects the workspace for a request. It overrides the
+// workspace_id claim of the access token.
+const WorkspaceHeader = "X-Workspace-ID"
+
+// APIKeyHeader carries a personal access token, as an alternative to sending
+// it as a Bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// WorkspaceResolver looks up the caller's membership in a workspace, or in
+// their default workspace when workspaceID is uuid.Nil.
+type WorkspaceResolver interface {
+	ResolveWorkspace(ctx context.Context, userID, workspaceID uuid.UUID) (*models.WorkspaceMember, error)
+}
+
+// RevocationChecker reports whether an access token was revoked by logout.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) bool
+}
+
+// APITokenAuthenticator resolves personal access tokens.
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(ctx context.Context, token string) (*services.APITokenIdentity, error)
+}
+
 type AuthzConfig struct {
 	Role        string
 	Permissions []string
+	// AllowQueryToken accepts the token from the access_token query parameter
+	// when no Authorization header is sent. Browsers cannot set headers on
+	// WebSocket handshakes, so only enable it for such endpoints.
+	AllowQueryToken bool
+	// Workspaces, when set, resolves the request's workspace and rejects
+	// callers who are not members of it. Statements run with the request
+	// context are then limited to that workspace.
+	Workspaces WorkspaceResolver
+	// Revocations, when set, rejects access tokens revoked by logout.
+	Revocations RevocationChecker
+	// APITokens, when set, also accepts personal access tokens, sent in the
+	// X-API-Key header or as a Bearer token. Their requests are limited to the
+	// token's scopes.
+	APITokens APITokenAuthenticator
+	// APITokenScopes lists the routes personal access tokens may call, keyed
+	// by method and route pattern ("POST /api/v1/tasks"), with the scope each
+	// requires. Token requests to any other route are refused, so handlers
+	// that never consult the token's scopes stay out of reach.
+	APITokenScopes map[string]string
 }
 
 func AuthzMiddleware(config AuthzConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if config.APITokens != nil {
+			if apiToken := presentedAPIToken(c); apiToken != "" {
+				claims, ok := authenticateAPIToken(c, config.APITokens, config.APITokenScopes, apiToken)
+				if !ok {
+					return
+				}
+				authorizeRequest(c, config, claims)
+				return
+			}
+		}
+
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && config.AllowQueryToken {
+			if queryToken := c.Query("access_token"); queryToken != "" {
+				authHeader = "Bearer " + queryToken
+			}
+		}
+
 		if authHeader == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error":   "missing_token",
@@ -91,41 +158,209 @@ func AuthzMiddleware(config AuthzConfig) gin.HandlerFunc {
 			return
 		}
 
-		if config.Role != "" {
-			role, _ := claims["role"].(string)
-			if role != config.Role && role != "admin" { 
-				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-					"error":   "insufficient_role",
-					"message": "User role does not have access to this resource",
+		// Refresh and MFA tokens are signed with the same key, but only access
+		// tokens, which carry no type, authenticate requests.
+		if tokenType, _ := claims["type"].(string); tokenType != "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "invalid_token_type",
+				"message": "Token cannot be used to access this resource",
+			})
+			return
+		}
+
+		if config.Revocations != nil {
+			jti, _ := claims["jti"].(string)
+			userID, _ := uuid.FromString(fmt.Sprintf("%v", claims["user_id"]))
+			var issuedAt time.Time
+			if iat, ok := claims["iat"].(float64); ok {
+				issuedAt = services.IssuedAtFromClaim(iat)
+			}
+			if config.Revocations.IsRevoked(c.Request.Context(), jti, userID, issuedAt) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error":   "revoked_token",
+					"message": "Token has been revoked",
 				})
 				return
 			}
 		}
 
-		if len(config.Permissions) > 0 {
-			perms, _ := claims["permissions"].([]interface{})
-			userPerms := map[string]bool{}
-			for _, p := range perms {
-				if ps, ok := p.(string); ok {
-					userPerms[ps] = true
-				}
+		authorizeRequest(c, config, claims)
+	}
+}
+
+// authorizeRequest applies the role, permission and workspace requirements to
+// an authenticated caller and runs the rest of the chain.
+func authorizeRequest(c *gin.Context, config AuthzConfig, claims jwt.MapClaims) {
+	if config.Role != "" {
+		role, _ := claims["role"].(string)
+		if role != config.Role && role != "admin" { 
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "insufficient_role",
+				"message": "User role does not have access to this resource",
+			})
+			return
+		}
+	}
+
+	if len(config.Permissions) > 0 {
+		perms, _ := claims["permissions"].([]interface{})
+		userPerms := map[string]bool{}
+		for _, p := range perms {
+			if ps, ok := p.(string); ok {
+				userPerms[ps] = true
 			}
+		}
 
-			for _, required := range config.Permissions {
-				if !userPerms[required] {
-					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-						"error":   "missing_permission",
-						"message": "User does not have required permission: " + required,
-					})
-					return
-				}
+		for _, required := range config.Permissions {
+			if !userPerms[required] {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error":   "missing_permission",
+					"message": "User does not have required permission: " + required,
+				})
+				return
 			}
 		}
+	}
+
+	c.Set("user_id", claims["user_id"])
+	c.Set("user_role", claims["role"])
+	c.Set("user_permissions", claims["permissions"])
+	jti, _ := claims["jti"].(string)
+	c.Set("token_jti", jti)
+	if exp, ok := claims["exp"].(float64); ok {
+		c.Set("token_expires_at", time.Unix(int64(exp), 0))
+	}
+
+	if config.Workspaces != nil {
+		if !selectWorkspace(c, config.Workspaces, claims) {
+			return
+		}
+		defer services.ReleaseWorkspaceSession(c.Request.Context())
+	}
+
+	c.Next()
+}
+
+// presentedAPIToken returns the personal access token the request carries, if
+// any.
+func presentedAPIToken(c *gin.Context) string {
+	if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+		return apiKey
+	}
+	if bearer := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); strings.HasPrefix(bearer, services.PersonalAccessTokenPrefix) {
+		return bearer
+	}
+	return ""
+}
 
-		c.Set("user_id", claims["user_id"])
-		c.Set("user_role", claims["role"])
-		c.Set("user_permissions", claims["permissions"])
+// authenticateAPIToken resolves a personal access token into the claims an
+// access token for its scopes would carry, checks the route accepts tokens
+// with those scopes, and limits the request to them. Personal access tokens
+// have no role.
+func authenticateAPIToken(c *gin.Context, authenticator APITokenAuthenticator, routeScopes map[string]string, token string) (jwt.MapClaims, bool) {
+	identity, err := authenticator.AuthenticateAPIToken(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAccessToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "invalid_token",
+				"message": "API token is invalid, expired or revoked",
+			})
+			return nil, false
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error":   "authentication_failed",
+			"message": "Failed to verify API token",
+		})
+		return nil, false
+	}
 
-		c.Next()
+	required, ok := routeScopes[c.Request.Method+" "+c.FullPath()]
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":   "api_token_not_allowed",
+			"message": "API tokens cannot access this resource",
+		})
+		return nil, false
+	}
+	granted := false
+	for _, permission := range identity.Permissions {
+		if permission == required {
+			granted = true
+			break
+		}
+	}
+	if !granted {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":   "insufficient_scope",
+			"message": "API token does not have required scope: " + required,
+		})
+		return nil, false
 	}
+
+	permissions := make([]interface{}, len(identity.Permissions))
+	for i, permission := range identity.Permissions {
+		permissions[i] = permission
+	}
+	claims := jwt.MapClaims{
+		"user_id":     identity.UserID.String(),
+		"permissions": permissions,
+	}
+	if identity.ExpiresAt != nil {
+		claims["exp"] = float64(identity.ExpiresAt.Unix())
+	}
+
+	c.Set("api_token_id", identity.TokenID)
+	c.Request = c.Request.WithContext(services.WithTokenScopes(c.Request.Context(), identity.Permissions))
+	return claims, true
+}
+
+// selectWorkspace resolves the workspace from the X-Workspace-ID header, the
+// token's workspace_id claim or the user's default workspace, in that order,
+// and binds it to the request.
+func selectWorkspace(c *gin.Context, resolver WorkspaceResolver, claims jwt.MapClaims) bool {
+	userID, err := uuid.FromString(fmt.Sprintf("%v", claims["user_id"]))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_claims",
+			"message": "Token claims are invalid",
+		})
+		return false
+	}
+
+	selector := c.GetHeader(WorkspaceHeader)
+	if selector == "" {
+		selector, _ = claims["workspace_id"].(string)
+	}
+
+	workspaceID := uuid.Nil
+	if selector != "" {
+		if workspaceID, err = uuid.FromString(selector); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_workspace",
+				"message": "Workspace ID is not a valid UUID",
+			})
+			return false
+		}
+	}
+
+	member, err := resolver.ResolveWorkspace(c.Request.Context(), userID, workspaceID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotWorkspaceMember) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "workspace_forbidden",
+				"message": "User is not a member of the selected workspace",
+			})
+			return false
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error":   "workspace_lookup_failed",
+			"message": "Failed to resolve workspace",
+		})
+		return false
+	}
+
+	c.Set("workspace_id", member.WorkspaceID)
+	c.Set("workspace_role", member.Role)
+	c.Request = c.Request.WithContext(services.WithWorkspace(c.Request.Context(), member.WorkspaceID))
+	return true
 }
@@ -1,14 +1,19 @@
 package middleware_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -184,3 +189,270 @@ func TestAuthzMiddleware_NoRequirements(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
 	}
 }
+
+func TestAuthzMiddleware_QueryToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	token, err := createTestToken("user", nil)
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		config   middleware.AuthzConfig
+		expected int
+	}{
+		{"disabled by default", middleware.AuthzConfig{}, http.StatusUnauthorized},
+		{"accepted when allowed", middleware.AuthzConfig{AllowQueryToken: true}, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.AuthzMiddleware(tc.config))
+			router.GET("/ws", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest("GET", "/ws?access_token="+token, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expected {
+				t.Errorf("Expected status %d, got %d", tc.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthzMiddleware_RejectsTypedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tokenType := range []string{"refresh", "mfa"} {
+		t.Run(tokenType, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"type":    tokenType,
+				"exp":     time.Now().Add(time.Hour).Unix(),
+				"iss":     "taskify-backend",
+				"user_id": "test-user-123",
+			}).SignedString([]byte("default_secret_change_in_production"))
+			if err != nil {
+				t.Fatalf("Failed to create token: %v", err)
+			}
+
+			router := gin.New()
+			router.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{}))
+			router.GET("/protected", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+			}
+		})
+	}
+}
+
+type stubRevocations map[string]bool
+
+func (r stubRevocations) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) bool {
+	return r[jti]
+}
+
+func TestAuthzMiddleware_RevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sign := func(jti string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"jti":     jti,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+			"iss":     "taskify-backend",
+			"user_id": uuid.Must(uuid.NewV4()).String(),
+		}).SignedString([]byte("default_secret_change_in_production"))
+		if err != nil {
+			t.Fatalf("Failed to create token: %v", err)
+		}
+		return token
+	}
+
+	router := gin.New()
+	router.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Revocations: stubRevocations{"revoked": true}}))
+	router.GET("/protected", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"jti": c.GetString("token_jti")})
+	})
+
+	for jti, expected := range map[string]int{"revoked": http.StatusUnauthorized, "active": http.StatusOK} {
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+sign(jti))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != expected {
+			t.Errorf("Expected status %d for %s token, got %d", expected, jti, w.Code)
+		}
+	}
+}
+
+type stubWorkspaceResolver struct {
+	defaultWorkspace uuid.UUID
+	members          map[uuid.UUID]bool
+}
+
+func (r stubWorkspaceResolver) ResolveWorkspace(ctx context.Context, userID, workspaceID uuid.UUID) (*models.WorkspaceMember, error) {
+	if workspaceID == uuid.Nil {
+		workspaceID = r.defaultWorkspace
+	}
+	if !r.members[workspaceID] {
+		return nil, services.ErrNotWorkspaceMember
+	}
+	return &models.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: models.WorkspaceRoleMember}, nil
+}
+
+func TestAuthzMiddleware_WorkspaceSelection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	home := uuid.Must(uuid.NewV4())
+	team := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	resolver := stubWorkspaceResolver{defaultWorkspace: home, members: map[uuid.UUID]bool{home: true, team: true}}
+
+	sign := func(claims jwt.MapClaims) string {
+		claims["user_id"] = uuid.Must(uuid.NewV4()).String()
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		claims["iss"] = "taskify-backend"
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("default_secret_change_in_production"))
+		if err != nil {
+			t.Fatalf("Failed to create token: %v", err)
+		}
+		return token
+	}
+
+	for _, tc := range []struct {
+		name      string
+		claims    jwt.MapClaims
+		header    string
+		expected  int
+		workspace uuid.UUID
+	}{
+		{"default workspace", jwt.MapClaims{}, "", http.StatusOK, home},
+		{"token claim", jwt.MapClaims{"workspace_id": team.String()}, "", http.StatusOK, team},
+		{"header overrides claim", jwt.MapClaims{"workspace_id": home.String()}, team.String(), http.StatusOK, team},
+		{"not a member", jwt.MapClaims{}, other.String(), http.StatusForbidden, uuid.Nil},
+		{"malformed selector", jwt.MapClaims{}, "acme", http.StatusBadRequest, uuid.Nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Workspaces: resolver}))
+			router.GET("/tasks", func(c *gin.Context) {
+				workspaceID, _ := services.WorkspaceFromContext(c.Request.Context())
+				c.JSON(http.StatusOK, gin.H{"workspace_id": workspaceID})
+			})
+
+			req, _ := http.NewRequest("GET", "/tasks", nil)
+			req.Header.Set("Authorization", "Bearer "+sign(tc.claims))
+			if tc.header != "" {
+				req.Header.Set(middleware.WorkspaceHeader, tc.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expected {
+				t.Fatalf("Expected status %d, got %d", tc.expected, w.Code)
+			}
+			if tc.workspace != uuid.Nil && !strings.Contains(w.Body.String(), tc.workspace.String()) {
+				t.Errorf("Expected workspace %s in request context, got %s", tc.workspace, w.Body.String())
+			}
+		})
+	}
+}
+
+type stubAPITokens map[string]*services.APITokenIdentity
+
+func (s stubAPITokens) AuthenticateAPIToken(ctx context.Context, token string) (*services.APITokenIdentity, error) {
+	if identity, ok := s[token]; ok {
+		return identity, nil
+	}
+	return nil, services.ErrInvalidAccessToken
+}
+
+func TestAuthzMiddleware_APITokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	identity := &services.APITokenIdentity{
+		TokenID:     uuid.Must(uuid.NewV4()),
+		UserID:      uuid.Must(uuid.NewV4()),
+		Permissions: []string{"task:read"},
+	}
+	tokens := stubAPITokens{"pat_valid": identity}
+	routeScopes := map[string]string{
+		"GET /tasks":  "task:read",
+		"POST /tasks": "task:create",
+	}
+
+	jwtToken, err := createTestToken("user", []string{"task:read"})
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		path        string
+		header      string
+		value       string
+		permissions []string
+		expected    int
+	}{
+		{"api key header", "GET", "/tasks", middleware.APIKeyHeader, "pat_valid", nil, http.StatusOK},
+		{"bearer token", "GET", "/tasks", "Authorization", "Bearer pat_valid", nil, http.StatusOK},
+		{"within scopes", "GET", "/tasks", middleware.APIKeyHeader, "pat_valid", []string{"task:read"}, http.StatusOK},
+		{"outside scopes", "GET", "/tasks", middleware.APIKeyHeader, "pat_valid", []string{"task:delete"}, http.StatusForbidden},
+		{"route outside scopes", "POST", "/tasks", middleware.APIKeyHeader, "pat_valid", nil, http.StatusForbidden},
+		{"route without scope", "DELETE", "/tasks/" + uuid.Must(uuid.NewV4()).String(), middleware.APIKeyHeader, "pat_valid", nil, http.StatusForbidden},
+		{"unknown token", "GET", "/tasks", middleware.APIKeyHeader, "pat_unknown", nil, http.StatusUnauthorized},
+		{"jwt still accepted", "GET", "/tasks", "Authorization", "Bearer " + jwtToken, nil, http.StatusOK},
+		{"jwt not limited to routes", "DELETE", "/tasks/" + uuid.Must(uuid.NewV4()).String(), "Authorization", "Bearer " + jwtToken, nil, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Permissions: tc.permissions, APITokens: tokens, APITokenScopes: routeScopes}))
+			handler := func(c *gin.Context) {
+				scopes, limited := services.TokenScopesFromContext(c.Request.Context())
+				c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id"), "limited": limited, "scopes": scopes})
+			}
+			router.GET("/tasks", handler)
+			router.POST("/tasks", handler)
+			router.DELETE("/tasks/:id", handler)
+
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set(tc.header, tc.value)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expected {
+				t.Fatalf("Expected status %d, got %d", tc.expected, w.Code)
+			}
+			if tc.expected == http.StatusOK && strings.HasSuffix(tc.value, "pat_valid") {
+				if !strings.Contains(w.Body.String(), identity.UserID.String()) || !strings.Contains(w.Body.String(), `"limited":true`) {
+					t.Errorf("Expected the token's user limited to its scopes, got %s", w.Body.String())
+				}
+			}
+		})
+	}
+
+	// Without an authenticator API tokens are not accepted.
+	router := gin.New()
+	router.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{}))
+	router.GET("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+	req, _ := http.NewRequest("GET", "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer pat_valid")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without an authenticator, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// TaskAttachment is a file attached to a task. Data is only loaded when the
+// attachment is downloaded.
+type TaskAttachment struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	TaskID      uuid.UUID  `json:"task_id" gorm:"type:uuid;not null;index"`
+	Filename    string     `json:"filename" gorm:"not null"`
+	ContentType string     `json:"content_type" gorm:"not null"`
+	Size        int64      `json:"size" gorm:"not null"`
+	Data        []byte     `json:"-" gorm:"not null"`
+	UploadedBy  *uuid.UUID `json:"uploaded_by,omitempty" gorm:"type:uuid"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// InboundEmailAddress is a user's secret address for creating tasks by email.
+// Mail is only accepted when it is sent to this address from the user's own
+// email, so the local part must be kept private.
+type InboundEmailAddress struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	LocalPart string    `json:"-" gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
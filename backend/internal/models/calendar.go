@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// CalendarFeed holds the hash of a user's secret iCal feed token. The token
+// itself is only shown when it is generated.
+type CalendarFeed struct {
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;primaryKey"`
+	TokenHash      string     `json:"-" gorm:"not null;uniqueIndex"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
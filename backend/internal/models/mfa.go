@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// UserMFA is a user's TOTP authenticator. Secret is encrypted at rest and
+// EnabledAt stays nil until the enrollment is confirmed with a code.
+type UserMFA struct {
+	UserID uuid.UUID `json:"user_id" gorm:"primaryKey;type:uuid"`
+	Secret string    `json:"-" gorm:"not null"`
+	// LastUsedStep is the TOTP time step of the last accepted code, so a code
+	// cannot be used twice.
+	LastUsedStep int64      `json:"-" gorm:"not null"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+func (m *UserMFA) Enabled() bool {
+	return m.EnabledAt != nil
+}
+
+// MFARecoveryCode is a single-use fallback for a lost authenticator. Only the
+// code's hash is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
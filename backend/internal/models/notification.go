@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	NotificationChannelInApp = "in_app"
+	NotificationChannelEmail = "email"
+)
+
+type Notification struct {
+	ID           uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID       uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	Type         string     `json:"type" gorm:"not null"`
+	Title        string     `json:"title" gorm:"not null"`
+	Body         string     `json:"body"`
+	ResourceType string     `json:"resource_type,omitempty"`
+	ResourceID   *uuid.UUID `json:"resource_id,omitempty" gorm:"type:uuid"`
+	Data         string     `json:"data,omitempty" gorm:"type:text"`
+	ReadAt       *time.Time `json:"read_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationPreference stores a user's channel choices for one event type.
+// Event types without a stored preference are delivered on every channel.
+type NotificationPreference struct {
+	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	EventType    string    `json:"event_type" gorm:"primaryKey"`
+	EmailEnabled bool      `json:"email_enabled" gorm:"not null"`
+	InAppEnabled bool      `json:"in_app_enabled" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (p *NotificationPreference) ChannelEnabled(channel string) bool {
+	switch channel {
+	case NotificationChannelEmail:
+		return p.EmailEnabled
+	case NotificationChannelInApp:
+		return p.InAppEnabled
+	default:
+		return true
+	}
+}
+
+// Notification types that digests list in their own sections. Features that
+// assign tasks to someone or mention them notify with these types.
+const (
+	NotificationTypeAssigned  = "task.assigned"
+	NotificationTypeMentioned = "task.mentioned"
+)
+
+// DigestPreference is a user's digest schedule. Hour is the local hour the
+// digest is sent and Weekday (0 is Sunday) the day weekly digests go out.
+// PeriodEnd is where the last digest stopped; the next one starts there.
+type DigestPreference struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Frequency string    `json:"frequency" gorm:"not null;default:'off'"`
+	Timezone  string    `json:"timezone" gorm:"not null;default:'UTC'"`
+	Hour      int       `json:"hour" gorm:"not null"`
+	Weekday   int       `json:"weekday" gorm:"not null"`
+	PeriodEnd time.Time `json:"period_end" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
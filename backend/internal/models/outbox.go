@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	OutboxAggregateTask = "task"
+	OutboxAggregateUser = "user"
+	OutboxAggregateRole = "role"
+)
+
+const (
+	UserEventUpdated  = "user.updated"
+	UserEventDeleted  = "user.deleted"
+	RoleEventAssigned = "role.assigned"
+	RoleEventRevoked  = "role.revoked"
+)
+
+// OutboxMessage is an event recorded in the same transaction as the change it
+// describes. The relay publishes it afterwards, so consumers may see it more
+// than once and should dedupe on ID.
+type OutboxMessage struct {
+	ID            uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	AggregateType string     `json:"aggregate_type" gorm:"not null"`
+	AggregateID   uuid.UUID  `json:"aggregate_id" gorm:"type:uuid;not null"`
+	EventType     string     `json:"event_type" gorm:"not null"`
+	Payload       string     `json:"payload" gorm:"type:text;not null"`
+	Attempts      int        `json:"attempts" gorm:"not null"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -95,6 +95,10 @@ type ResourceAttribute struct {
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// AuditDecisionRecorded marks audit entries for domain events, which record
+// what happened rather than an authorization decision.
+const AuditDecisionRecorded = "recorded"
+
 type AuditLog struct {
 	ID            uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
 	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid"`
@@ -109,6 +113,8 @@ type AuditLog struct {
 	RequestPath   string    `json:"request_path"`
 	Context       string    `json:"context" gorm:"type:text"`
 	Timestamp     time.Time `json:"timestamp"`
+	// WorkspaceID is nil for events outside any workspace, such as logins.
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty" gorm:"type:uuid;index"`
 
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// TaskShare is a public read-only link to one task. The token and password are
+// only held as hashes; the token is shown once, when the link is created.
+type TaskShare struct {
+	ID             uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	TaskID         uuid.UUID  `json:"task_id" gorm:"type:uuid;not null"`
+	CreatedBy      uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	TokenHash      string     `json:"-" gorm:"not null;uniqueIndex"`
+	PasswordHash   string     `json:"-"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	AccessCount    int        `json:"access_count" gorm:"not null"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (s *TaskShare) HasPassword() bool {
+	return s.PasswordHash != ""
+}
+
+// Active reports whether the link can still be opened at now.
+func (s *TaskShare) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	SprintStatusPlanned = "planned"
+	SprintStatusActive  = "active"
+	SprintStatusClosed  = "closed"
+)
+
+// Sprint is a time box that tasks can be attached to. StartDate and EndDate
+// are whole days in UTC; EndDate is the last day of the sprint.
+type Sprint struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Name        string     `json:"name" gorm:"not null"`
+	Goal        string     `json:"goal,omitempty"`
+	StartDate   time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate     time.Time  `json:"end_date" gorm:"type:date;not null"`
+	Status      string     `json:"status" gorm:"not null;default:'planned'"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty" gorm:"type:uuid;index"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// SprintSnapshot records a sprint's remaining work at the end of a day.
+// Cancelled tasks are left out of every count.
+type SprintSnapshot struct {
+	SprintID       uuid.UUID `json:"sprint_id" gorm:"primaryKey;type:uuid"`
+	SnapshotDate   time.Time `json:"date" gorm:"primaryKey;type:date"`
+	TotalTasks     int64     `json:"total_tasks"`
+	CompletedTasks int64     `json:"completed_tasks"`
+	RemainingTasks int64     `json:"remaining_tasks"`
+	TotalHours     float64   `json:"total_hours"`
+	RemainingHours float64   `json:"remaining_hours"`
+	CreatedAt      time.Time `json:"-"`
+	UpdatedAt      time.Time `json:"-"`
+}
@@ -1,17 +1,85 @@
 package models
 
 import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 )
 
 type Task struct {
-	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
-	Title       string    `json:"title" gorm:"not null"`
-	Description string    `json:"description"`
-	Status      string    `json:"status" gorm:"not null;default:'pending'"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	Title          string     `json:"title" gorm:"not null"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status" gorm:"not null;default:'pending'"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+	RecurrenceRule string     `json:"recurrence_rule,omitempty"`
+	Labels         StringList `json:"labels,omitempty" gorm:"type:text"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	EstimatedHours *float64   `json:"estimated_hours,omitempty"`
+	SprintID       *uuid.UUID `json:"sprint_id,omitempty" gorm:"type:uuid"`
+	WorkspaceID    *uuid.UUID `json:"workspace_id,omitempty" gorm:"type:uuid;index"`
+	TeamID         *uuid.UUID `json:"team_id,omitempty" gorm:"type:uuid"`
+	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty" gorm:"type:uuid"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+const (
+	TaskStatusPending    = "pending"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusCompleted  = "completed"
+	TaskStatusDone       = "done"
+)
+
+// IsCompletedStatus reports whether status marks a task as finished. Both
+// "completed" and "done" are in use.
+func IsCompletedStatus(status string) bool {
+	return status == TaskStatusCompleted || status == TaskStatusDone
+}
+
+// StringList is stored as a comma-separated column and serialized as a JSON
+// array. Items must not contain commas.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	return strings.Join(l, ","), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+
+	if raw == "" {
+		*l = nil
+		return nil
+	}
+	*l = strings.Split(raw, ",")
+	return nil
+}
+
+func (l StringList) Contains(item string) bool {
+	for _, v := range l {
+		if v == item {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Team groups workspace members who share work. Tasks owned by a team wait in
+// its queue until a member claims them.
+type Team struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty" gorm:"type:uuid;index"`
+	Name        string     `json:"name" gorm:"not null"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TeamMember puts a user in a team. Leads may manage the team's tasks and
+// its other members.
+type TeamMember struct {
+	TeamID    uuid.UUID `json:"team_id" gorm:"primaryKey;type:uuid"`
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;type:uuid"`
+	IsLead    bool      `json:"is_lead" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -14,4 +14,40 @@ type Token struct {
 	JTI          uuid.UUID `json:"jti" gorm:"uniqueIndex"`
 	RefreshToken string    `json:"refresh_token" gorm:"type:text"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	// FamilyID is shared by the tokens descended from one login through
+	// rotation. RotatedAt is set once the token has been exchanged.
+	FamilyID  uuid.UUID  `json:"family_id" gorm:"type:uuid;index"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	// AccessJTI is the access token issued together with this token.
+	AccessJTI  uuid.UUID  `json:"-" gorm:"type:uuid"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent" gorm:"type:text"`
+	IPAddress  string     `json:"ip_address"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// PasswordResetToken is a single-use credential for choosing a new password.
+// Only the token's hash is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PersonalAccessToken authenticates API clients as its user, limited to its
+// scopes. Only the token's hash is stored; TokenPrefix identifies it in lists.
+type PersonalAccessToken struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name        string     `json:"name" gorm:"not null"`
+	TokenHash   string     `json:"-" gorm:"not null;uniqueIndex"`
+	TokenPrefix string     `json:"token_prefix" gorm:"not null"`
+	Scopes      StringList `json:"scopes" gorm:"type:text;not null"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
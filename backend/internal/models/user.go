@@ -21,6 +21,9 @@ type User struct {
 	IsActive    bool       `json:"is_active" gorm:"default:true"`
 	LastLoginAt *time.Time `json:"last_login_at"`
 
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	VerificationSentAt *time.Time `json:"-"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -34,6 +37,10 @@ type User struct {
 	AuditLogs []AuditLog `json:"audit_logs,omitempty" gorm:"foreignKey:UserID"`
 }
 
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 func (u *User) HasRole(roleName string) bool {
 	for _, role := range u.Roles {
 		if role.Name == roleName {
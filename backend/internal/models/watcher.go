@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	WatchReasonManual = "manual"
+	WatchReasonOwner  = "owner"
+)
+
+const (
+	TaskEventCreated       = "task.created"
+	TaskEventUpdated       = "task.updated"
+	TaskEventStatusChanged = "task.status_changed"
+	TaskEventDeleted       = "task.deleted"
+)
+
+type TaskWatcher struct {
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Reason    string    `json:"reason" gorm:"not null;default:'manual'"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskEvent describes something that happened to a task. It travels through
+// the worker queue as a job payload, so every field must survive a JSON round trip.
+// EventID is the outbox message ID and is shared by every redelivery of the event.
+type TaskEvent struct {
+	EventID     uuid.UUID         `json:"event_id"`
+	Type        string            `json:"type"`
+	TaskID      uuid.UUID         `json:"task_id"`
+	WorkspaceID *uuid.UUID        `json:"workspace_id,omitempty"`
+	OwnerID     uuid.UUID         `json:"owner_id"`
+	ActorID     uuid.UUID         `json:"actor_id"`
+	Title       string            `json:"title"`
+	Changes     map[string]string `json:"changes,omitempty"`
+	OccurredAt  time.Time         `json:"occurred_at"`
+}
+
+func (e TaskEvent) ToPayload() map[string]interface{} {
+	changes := make(map[string]interface{}, len(e.Changes))
+	for k, v := range e.Changes {
+		changes[k] = v
+	}
+
+	payload := map[string]interface{}{
+		"event_id":    e.EventID.String(),
+		"type":        e.Type,
+		"task_id":     e.TaskID.String(),
+		"owner_id":    e.OwnerID.String(),
+		"actor_id":    e.ActorID.String(),
+		"title":       e.Title,
+		"changes":     changes,
+		"occurred_at": e.OccurredAt.Format(time.RFC3339Nano),
+	}
+	if e.WorkspaceID != nil {
+		payload["workspace_id"] = e.WorkspaceID.String()
+	}
+	return payload
+}
+
+func TaskEventFromPayload(payload map[string]interface{}) (TaskEvent, error) {
+	var event TaskEvent
+
+	eventType, _ := payload["type"].(string)
+	if eventType == "" {
+		return event, fmt.Errorf("missing event type in payload")
+	}
+	event.Type = eventType
+
+	taskID, err := uuid.FromString(fmt.Sprintf("%v", payload["task_id"]))
+	if err != nil {
+		return event, fmt.Errorf("invalid task_id in payload: %w", err)
+	}
+	event.TaskID = taskID
+
+	event.EventID = uuid.FromStringOrNil(fmt.Sprintf("%v", payload["event_id"]))
+	if workspaceID, err := uuid.FromString(fmt.Sprintf("%v", payload["workspace_id"])); err == nil {
+		event.WorkspaceID = &workspaceID
+	}
+	event.OwnerID = uuid.FromStringOrNil(fmt.Sprintf("%v", payload["owner_id"]))
+	event.ActorID = uuid.FromStringOrNil(fmt.Sprintf("%v", payload["actor_id"]))
+	event.Title, _ = payload["title"].(string)
+
+	if changes, ok := payload["changes"].(map[string]interface{}); ok && len(changes) > 0 {
+		event.Changes = make(map[string]string, len(changes))
+		for k, v := range changes {
+			event.Changes[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	if occurredAt, ok := payload["occurred_at"].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, occurredAt); err == nil {
+			event.OccurredAt = t
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryRetrying  = "retrying"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is a user's subscription to task events. EventTypes is stored as a
+// comma-separated list; "*" subscribes to every event. A webhook only
+// receives events for tasks in its workspace.
+type Webhook struct {
+	ID                  uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID              uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	WorkspaceID         *uuid.UUID `json:"workspace_id,omitempty" gorm:"type:uuid;index"`
+	URL                 string     `json:"url" gorm:"not null"`
+	Secret              string     `json:"-" gorm:"not null"`
+	EventTypes          string     `json:"-" gorm:"not null"`
+	IsActive            bool       `json:"is_active" gorm:"not null"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func (w *Webhook) Events() []string {
+	if w.EventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(w.EventTypes, ",")
+}
+
+func (w *Webhook) SetEvents(events []string) {
+	w.EventTypes = strings.Join(events, ",")
+}
+
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events() {
+		if e == "*" || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	WebhookID      uuid.UUID  `json:"webhook_id" gorm:"type:uuid;not null"`
+	EventType      string     `json:"event_type" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"not null"`
+	Attempts       int        `json:"attempts" gorm:"not null"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	ResponseBody   string     `json:"response_body,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	DurationMs     *int64     `json:"duration_ms,omitempty"`
+	EventID        *uuid.UUID `json:"event_id,omitempty" gorm:"type:uuid"`
+	RedeliveryOf   *uuid.UUID `json:"redelivery_of,omitempty" gorm:"type:uuid"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
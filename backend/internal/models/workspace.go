@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+// Workspace is a tenant. Tasks, sprints and audit logs carry the ID of the
+// workspace they belong to.
+type Workspace struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Name      string     `json:"name" gorm:"not null"`
+	Slug      string     `json:"slug" gorm:"not null;uniqueIndex"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// WorkspaceMember gives a user access to a workspace with a role that only
+// applies inside it.
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID `json:"workspace_id" gorm:"primaryKey;type:uuid"`
+	UserID      uuid.UUID `json:"user_id" gorm:"primaryKey;type:uuid"`
+	Role        string    `json:"role" gorm:"not null;default:'member'"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CanManage reports whether the member may change the workspace's members.
+func (m *WorkspaceMember) CanManage() bool {
+	return m.Role == WorkspaceRoleOwner || m.Role == WorkspaceRoleAdmin
+}
+
+func IsValidWorkspaceRole(role string) bool {
+	switch role {
+	case WorkspaceRoleOwner, WorkspaceRoleAdmin, WorkspaceRoleMember:
+		return true
+	}
+	return false
+}
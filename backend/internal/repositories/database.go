@@ -1,17 +1,24 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"task-manager/backend/internal/utils"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// SystemRole bypasses row-level security. Connections for work that has no
+// workspace assume it; see migration 000035.
+const SystemRole = "taskify_system"
+
 type DatabaseConfig struct {
 	Host            string
 	Port            string
@@ -22,6 +29,9 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+
+	// Role, when set, is assumed with SET ROLE on every new connection.
+	Role string
 }
 
 func NewDatabaseConfig() *DatabaseConfig {
@@ -44,7 +54,12 @@ func (cfg *DatabaseConfig) Connect() (*gorm.DB, error) {
 		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	dialector, err := RoleDialector(dsn, cfg.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -67,6 +82,25 @@ func (cfg *DatabaseConfig) Connect() (*gorm.DB, error) {
 	return db, nil
 }
 
+// RoleDialector opens the Postgres database at dsn, assuming role on every
+// new connection. An empty role keeps the login role.
+func RoleDialector(dsn, role string) (gorm.Dialector, error) {
+	if role == "" {
+		return postgres.Open(dsn), nil
+	}
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
+	setRole := "SET ROLE " + pgx.Identifier{role}.Sanitize()
+	return postgres.New(postgres.Config{
+		Conn: stdlib.OpenDB(*connConfig, stdlib.OptionAfterConnect(func(ctx context.Context, conn *pgx.Conn) error {
+			_, err := conn.Exec(ctx, setRole)
+			return err
+		})),
+	}), nil
+}
+
 func NewDatabaseConnection() (*gorm.DB, error) {
 
 	dbHost := os.Getenv("DB_HOST")
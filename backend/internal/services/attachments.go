@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentService interface {
+	AddAttachment(db *gorm.DB, attachment *models.TaskAttachment) error
+	GetAttachments(db *gorm.DB, taskID uuid.UUID) ([]models.TaskAttachment, error)
+	GetAttachment(db *gorm.DB, taskID, attachmentID uuid.UUID) (models.TaskAttachment, error)
+}
+
+type AttachmentServiceImpl struct{}
+
+func NewAttachmentService() *AttachmentServiceImpl {
+	return &AttachmentServiceImpl{}
+}
+
+func (s *AttachmentServiceImpl) AddAttachment(db *gorm.DB, attachment *models.TaskAttachment) error {
+	if attachment.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return fmt.Errorf("failed to generate attachment ID: %w", err)
+		}
+		attachment.ID = id
+	}
+	if attachment.ContentType == "" {
+		attachment.ContentType = "application/octet-stream"
+	}
+	attachment.Size = int64(len(attachment.Data))
+	attachment.CreatedAt = time.Now()
+
+	return db.Create(attachment).Error
+}
+
+// GetAttachments lists a task's attachments without their contents.
+func (s *AttachmentServiceImpl) GetAttachments(db *gorm.DB, taskID uuid.UUID) ([]models.TaskAttachment, error) {
+	var attachments []models.TaskAttachment
+	err := db.Omit("data").
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (s *AttachmentServiceImpl) GetAttachment(db *gorm.DB, taskID, attachmentID uuid.UUID) (models.TaskAttachment, error) {
+	var attachment models.TaskAttachment
+	err := db.Where("id = ? AND task_id = ?", attachmentID, taskID).First(&attachment).Error
+	return attachment, err
+}
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"task-manager/backend/internal/models"
 	"time"
@@ -14,15 +17,59 @@ import (
 
 type AuthService interface {
 	LoginUser(db *gorm.DB, email, password string) (*models.User, error)
-	GenerateToken(db *gorm.DB, userID uuid.UUID) (string, string, error)
-	RefreshToken(db *gorm.DB, refreshToken string) (string, string, int64, error)
+	// GenerateToken starts a session for the client and returns its access
+	// and refresh tokens.
+	GenerateToken(db *gorm.DB, userID uuid.UUID, client ClientInfo) (string, string, error)
+	RefreshToken(db *gorm.DB, refreshToken string, client ClientInfo) (string, string, int64, error)
 	GetUserPermissions(db *gorm.DB, userID uuid.UUID) ([]string, error)
-	RevokeToken(db *gorm.DB, refreshToken string) error
+	// RevokeToken deletes userID's refresh token. It returns
+	// ErrInvalidRefreshToken for a token that is malformed, expired or
+	// already revoked and ErrRefreshTokenNotOwned for another user's.
+	RevokeToken(db *gorm.DB, userID uuid.UUID, refreshToken string) error
+	// RevokeSession deletes the refresh tokens of the session userID's access
+	// token accessJTI was issued in. An unknown access token is not an error.
+	RevokeSession(db *gorm.DB, userID, accessJTI uuid.UUID) error
+	// RevokeUserTokens deletes every refresh token the user holds.
+	RevokeUserTokens(db *gorm.DB, userID uuid.UUID) error
 }
 
-type AuthServiceImpl struct{}
+// AccessTokenTTL is how long access tokens are valid.
+const AccessTokenTTL = time.Hour
 
-func (s *AuthServiceImpl) RefreshToken(db *gorm.DB, refreshToken string) (string, string, int64, error) {
+// ErrEmailNotVerified is returned by LoginUser for correct credentials when
+// logins wait for email verification.
+var ErrEmailNotVerified = errors.New("email address not verified")
+
+// ErrRefreshTokenReused is returned by RefreshToken when a token that was
+// already exchanged is presented again. The token's family has been revoked,
+// so the client must log in again.
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// ErrInvalidRefreshToken is returned by RevokeToken for a refresh token that
+// does not name a live session.
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// ErrRefreshTokenNotOwned is returned by RevokeToken for a refresh token that
+// belongs to another user.
+var ErrRefreshTokenNotOwned = errors.New("refresh token belongs to another user")
+
+const AuditActionRefreshTokenReused = "auth.refresh_token_reused"
+
+type AuthOptions struct {
+	// RequireEmailVerification blocks logins until the user's email address
+	// is verified.
+	RequireEmailVerification bool
+
+	// Denylist revokes the live access tokens of a token family revoked for
+	// refresh token reuse. Without one they stay valid until they expire.
+	Denylist *TokenDenylist
+}
+
+type AuthServiceImpl struct {
+	options AuthOptions
+}
+
+func (s *AuthServiceImpl) RefreshToken(db *gorm.DB, refreshToken string, client ClientInfo) (string, string, int64, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "default_secret_change_in_production"
@@ -79,22 +126,36 @@ func (s *AuthServiceImpl) RefreshToken(db *gorm.DB, refreshToken string) (string
 		return "", "", 0, fmt.Errorf("database error: %w", err)
 	}
 
-	accessToken, newRefreshToken, err := s.GenerateToken(db, userID)
+	// A rotated token coming back means it was copied: either the caller or
+	// whoever refreshed with it before is not the legitimate client, so the
+	// whole family goes. Claiming the token with a conditional update also
+	// catches two refreshes racing with the same token.
+	if dbToken.RotatedAt != nil {
+		return "", "", 0, s.revokeReusedFamily(db, dbToken)
+	}
+	claimed := db.Model(&models.Token{}).Where("id = ? AND rotated_at IS NULL", dbToken.ID).Update("rotated_at", time.Now())
+	if claimed.Error != nil {
+		return "", "", 0, fmt.Errorf("failed to rotate refresh token: %w", claimed.Error)
+	}
+	if claimed.RowsAffected == 0 {
+		return "", "", 0, s.revokeReusedFamily(db, dbToken)
+	}
+
+	if client.UserAgent == "" && client.IPAddress == "" {
+		client = ClientInfo{UserAgent: dbToken.UserAgent, IPAddress: dbToken.IPAddress}
+	}
+	accessToken, newRefreshToken, err := s.generateToken(db, userID, dbToken.FamilyID, client)
 	if err != nil {
 		return "", "", 0, fmt.Errorf("failed to generate new tokens: %w", err)
 	}
 
-	expiresIn := int64(3600)
-
-	if err := db.Delete(&dbToken).Error; err != nil {
-		return "", "", 0, fmt.Errorf("failed to delete old token: %w", err)
-	}
+	expiresIn := int64(AccessTokenTTL.Seconds())
 
 	return accessToken, newRefreshToken, expiresIn, nil
 }
 
-func NewAuthService() *AuthServiceImpl {
-	return &AuthServiceImpl{}
+func NewAuthService(options AuthOptions) *AuthServiceImpl {
+	return &AuthServiceImpl{options: options}
 }
 
 func VerifyPassword(hashedPassword, plainPassword string) bool {
@@ -110,10 +171,18 @@ func (s *AuthServiceImpl) LoginUser(db *gorm.DB, email, password string) (*model
 	if !VerifyPassword(user.Password, password) {
 		return nil, gorm.ErrInvalidData
 	}
+	if s.options.RequireEmailVerification && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
 	return &user, nil
 }
 
 func (s *AuthServiceImpl) GetUserPermissions(db *gorm.DB, userID uuid.UUID) ([]string, error) {
+	return userPermissions(db, userID)
+}
+
+// userPermissions returns the user's permissions as "resource:action".
+func userPermissions(db *gorm.DB, userID uuid.UUID) ([]string, error) {
 	var permissions []string
 	err := db.Raw(`SELECT DISTINCT p.resource || ':' || p.action FROM permissions p
 		JOIN role_permissions rp ON p.id = rp.permission_id
@@ -125,7 +194,7 @@ func (s *AuthServiceImpl) GetUserPermissions(db *gorm.DB, userID uuid.UUID) ([]s
 	return permissions, nil
 }
 
-func (s *AuthServiceImpl) RevokeToken(db *gorm.DB, refreshToken string) error {
+func (s *AuthServiceImpl) RevokeToken(db *gorm.DB, userID uuid.UUID, refreshToken string) error {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "default_secret_change_in_production"
@@ -139,28 +208,126 @@ func (s *AuthServiceImpl) RevokeToken(db *gorm.DB, refreshToken string) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("invalid refresh token: %w", err)
+		return fmt.Errorf("%w: %v", ErrInvalidRefreshToken, err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return fmt.Errorf("invalid token claims")
+		return fmt.Errorf("%w: invalid token claims", ErrInvalidRefreshToken)
 	}
 
 	jtiStr, ok := claims["jti"].(string)
 	if !ok {
-		return fmt.Errorf("missing jti in token")
+		return fmt.Errorf("%w: missing jti in token", ErrInvalidRefreshToken)
 	}
 
 	jti, err := uuid.FromString(jtiStr)
 	if err != nil {
-		return fmt.Errorf("invalid jti format: %w", err)
+		return fmt.Errorf("%w: invalid jti format: %v", ErrInvalidRefreshToken, err)
+	}
+
+	var dbToken models.Token
+	if err := db.Where("jti = ?", jti).First(&dbToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	if dbToken.UserId != userID {
+		return ErrRefreshTokenNotOwned
+	}
+
+	return db.Delete(&dbToken).Error
+}
+
+func (s *AuthServiceImpl) RevokeSession(db *gorm.DB, userID, accessJTI uuid.UUID) error {
+	var token models.Token
+	err := db.Where("user_id = ? AND access_jti = ?", userID, accessJTI).Limit(1).Find(&token).Error
+	if err != nil || token.ID == uuid.Nil {
+		return err
+	}
+	return db.Where("user_id = ? AND family_id = ?", userID, token.FamilyID).Delete(&models.Token{}).Error
+}
+
+func (s *AuthServiceImpl) RevokeUserTokens(db *gorm.DB, userID uuid.UUID) error {
+	return db.Where("user_id = ?", userID).Delete(&models.Token{}).Error
+}
+
+// revokeReusedFamily deletes every token in the reused token's family,
+// revokes the access tokens issued with them and records the reuse in the
+// audit log. It returns ErrRefreshTokenReused.
+func (s *AuthServiceImpl) revokeReusedFamily(db *gorm.DB, reused models.Token) error {
+	detail, _ := json.Marshal(map[string]string{
+		"jti":       reused.JTI.String(),
+		"family_id": reused.FamilyID.String(),
+	})
+
+	// Tokens issued within the access token lifetime still have live access
+	// tokens, which must go with the family.
+	var tokens []models.Token
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("family_id = ? AND created_at > ?", reused.FamilyID, time.Now().Add(-AccessTokenTTL)).
+			Find(&tokens).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("family_id = ?", reused.FamilyID).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			ID:         uuid.Must(uuid.NewV4()),
+			UserID:     reused.UserId,
+			Action:     AuditActionRefreshTokenReused,
+			Resource:   "token",
+			ResourceID: reused.FamilyID,
+			Decision:   "denied",
+			Reason:     "rotated refresh token presented again; token family revoked",
+			Context:    string(detail),
+			Timestamp:  time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke reused token family: %w", err)
 	}
 
-	return db.Where("jti = ?", jti).Delete(&models.Token{}).Error
+	if s.options.Denylist != nil {
+		for _, token := range tokens {
+			if token.AccessJTI == uuid.Nil {
+				continue
+			}
+			if err := s.options.Denylist.Revoke(db.Statement.Context, token.AccessJTI.String(), token.CreatedAt.Add(AccessTokenTTL)); err != nil {
+				return fmt.Errorf("failed to revoke reused family access token: %w", err)
+			}
+		}
+	}
+	return ErrRefreshTokenReused
+}
+
+// DeleteExpiredTokens removes refresh tokens that expired before cutoff,
+// including rotated and revoked ones kept for reuse detection.
+func DeleteExpiredTokens(db *gorm.DB, cutoff time.Time) (int64, error) {
+	result := db.Unscoped().Where("expires_at < ?", cutoff).Delete(&models.Token{})
+	return result.RowsAffected, result.Error
 }
 
-func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID) (string, string, error) {
+func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID, client ClientInfo) (string, string, error) {
+	return s.generateToken(db, userID, uuid.Nil, client)
+}
+
+// IssuedAtClaim is the iat claim of an access token issued at t: seconds
+// with microsecond precision.
+func IssuedAtClaim(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
+
+// IssuedAtFromClaim reverses IssuedAtClaim. Whole seconds, as older tokens
+// carry, are read as they are.
+func IssuedAtFromClaim(iat float64) time.Time {
+	return time.UnixMicro(int64(math.Round(iat * 1e6)))
+}
+
+// generateToken issues an access and refresh token pair. The refresh token
+// joins familyID, or starts a new family when it is uuid.Nil.
+func (s *AuthServiceImpl) generateToken(db *gorm.DB, userID, familyID uuid.UUID, client ClientInfo) (string, string, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "default_secret_change_in_production"
@@ -183,16 +350,31 @@ func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID) (string,
 
 	now := time.Now()
 
+	accessJTI, err := uuid.NewV4()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate jti: %w", err)
+	}
+
+	// The jti lets logout revoke this token before it expires. iat has
+	// microsecond precision so logging out of all sessions does not also
+	// revoke a token issued later within the same second.
 	accessTokenClaims := jwt.MapClaims{
 		"user_id":     userID.String(),
 		"role":        roleName,
 		"permissions": permissions,
-		"iat":         now.Unix(),
-		"exp":         now.Add(time.Hour).Unix(),
+		"jti":         accessJTI.String(),
+		"iat":         IssuedAtClaim(now),
+		"exp":         now.Add(AccessTokenTTL).Unix(),
 		"iss":         "taskify-backend",
 		"aud":         "taskify-users",
 	}
 
+	// The default workspace is only a fallback; clients pick another one with
+	// the X-Workspace-ID header.
+	if member, err := defaultMembership(db, userID); err == nil {
+		accessTokenClaims["workspace_id"] = member.WorkspaceID.String()
+	}
+
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
 	accessTokenString, err := accessToken.SignedString([]byte(secret))
 	if err != nil {
@@ -221,12 +403,23 @@ func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID) (string,
 		return "", "", fmt.Errorf("failed to sign refresh token: %w", err)
 	}
 
+	tokenID := uuid.Must(uuid.NewV4())
+	if familyID == uuid.Nil {
+		familyID = tokenID
+	}
+
 	tokenRecord := models.Token{
-		ID:           uuid.Must(uuid.NewV4()),
+		ID:           tokenID,
 		UserId:       userID,
 		JTI:          jti,
 		RefreshToken: refreshTokenString,
 		ExpiresAt:    refreshTokenExpiry,
+		FamilyID:     familyID,
+		AccessJTI:    accessJTI,
+		Device:       DeviceName(client.UserAgent),
+		UserAgent:    client.UserAgent,
+		IPAddress:    client.IPAddress,
+		LastUsedAt:   &now,
 	}
 
 	if err := db.Create(&tokenRecord).Error; err != nil {
@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_RefreshTokenRotationDetectsReuse(t *testing.T) {
+	db := openTestDB(t, "users", "tokens", "audit_logs")
+	userID := createTestUser(t, db, "alice")
+	denylist := services.NewTokenDenylist(nil)
+	auth := services.NewAuthService(services.AuthOptions{Denylist: denylist})
+
+	_, original, err := auth.GenerateToken(db, userID, services.ClientInfo{})
+	require.NoError(t, err)
+	// A second login is a separate family and must survive the reuse below.
+	_, otherSession, err := auth.GenerateToken(db, userID, services.ClientInfo{})
+	require.NoError(t, err)
+
+	_, rotated, _, err := auth.RefreshToken(db, original, services.ClientInfo{})
+	require.NoError(t, err)
+
+	var tokens []models.Token
+	require.NoError(t, db.Order("created_at").Find(&tokens, "refresh_token IN ?", []string{original, rotated}).Error)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, tokens[0].FamilyID, tokens[1].FamilyID, "rotation stays in the family")
+	assert.NotNil(t, tokens[0].RotatedAt)
+	assert.Nil(t, tokens[1].RotatedAt)
+
+	_, _, _, err = auth.RefreshToken(db, original, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrRefreshTokenReused)
+
+	_, _, _, err = auth.RefreshToken(db, rotated, services.ClientInfo{})
+	assert.Error(t, err, "the whole family is revoked after reuse")
+
+	var audit models.AuditLog
+	require.NoError(t, db.First(&audit, "action = ?", services.AuditActionRefreshTokenReused).Error)
+	assert.Equal(t, userID, audit.UserID)
+	assert.Equal(t, tokens[0].FamilyID, audit.ResourceID)
+	assert.Equal(t, "denied", audit.Decision)
+
+	ctx := context.Background()
+	for _, token := range tokens {
+		assert.True(t, denylist.IsRevoked(ctx, token.AccessJTI.String(), userID, time.Now()), "access tokens of the family are revoked")
+	}
+	var other models.Token
+	require.NoError(t, db.First(&other, "refresh_token = ?", otherSession).Error)
+	assert.False(t, denylist.IsRevoked(ctx, other.AccessJTI.String(), userID, time.Now()), "other sessions keep their access tokens")
+
+	_, _, _, err = auth.RefreshToken(db, otherSession, services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestAuthService_RevokeTokenChecksOwner(t *testing.T) {
+	db := openTestDB(t, "users", "tokens")
+	alice, bob := createTestUser(t, db, "alice"), createTestUser(t, db, "bob")
+	auth := services.NewAuthService(services.AuthOptions{})
+
+	_, refreshToken, err := auth.GenerateToken(db, alice, services.ClientInfo{})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, auth.RevokeToken(db, alice, "not-a-token"), services.ErrInvalidRefreshToken)
+	assert.ErrorIs(t, auth.RevokeToken(db, bob, refreshToken), services.ErrRefreshTokenNotOwned)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Token{}).Where("user_id = ?", alice).Count(&count).Error)
+	assert.Equal(t, int64(1), count, "another user cannot revoke the token")
+
+	require.NoError(t, auth.RevokeToken(db, alice, refreshToken))
+	assert.ErrorIs(t, auth.RevokeToken(db, alice, refreshToken), services.ErrInvalidRefreshToken, "a revoked token is no longer valid")
+}
@@ -15,6 +15,7 @@ import (
 type AuthorizationService interface {
 	HasRole(ctx context.Context, userID uuid.UUID, roleName string) (bool, error)
 	HasPermission(ctx context.Context, userID uuid.UUID, resource, action string) (bool, error)
+	PermissionScopes(ctx context.Context, userID uuid.UUID, resource, action string) ([]string, error)
 	AssignRole(ctx context.Context, userID, roleID, assignedBy uuid.UUID) error
 	RevokeRole(ctx context.Context, userID, roleID uuid.UUID) error
 
@@ -64,6 +65,11 @@ type AuthorizationDecision struct {
 }
 
 func (s *AuthorizationServiceImpl) HasRole(ctx context.Context, userID uuid.UUID, roleName string) (bool, error) {
+	// Personal access tokens carry permissions, not roles.
+	if _, ok := TokenScopesFromContext(ctx); ok {
+		return false, nil
+	}
+
 	var count int64
 	err := s.db.WithContext(ctx).
 		Table("user_roles").
@@ -75,6 +81,10 @@ func (s *AuthorizationServiceImpl) HasRole(ctx context.Context, userID uuid.UUID
 }
 
 func (s *AuthorizationServiceImpl) HasPermission(ctx context.Context, userID uuid.UUID, resource, action string) (bool, error) {
+	if !scopeAllows(ctx, resource, action) {
+		return false, nil
+	}
+
 	var count int64
 	err := s.db.WithContext(ctx).
 		Table("user_roles").
@@ -90,6 +100,30 @@ func (s *AuthorizationServiceImpl) HasPermission(ctx context.Context, userID uui
 	return count > 0, err
 }
 
+// PermissionScopes returns the scopes of every resource:action permission the
+// user holds through their roles, such as "own", "department" or "all". It is
+// empty when the user lacks the permission.
+func (s *AuthorizationServiceImpl) PermissionScopes(ctx context.Context, userID uuid.UUID, resource, action string) ([]string, error) {
+	if !scopeAllows(ctx, resource, action) {
+		return nil, nil
+	}
+
+	var scopes []string
+	err := s.db.WithContext(ctx).
+		Table("user_roles").
+		Joins("JOIN role_permissions ON role_permissions.role_id = user_roles.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where(`user_roles.user_id = ?
+			   AND permissions.resource = ?
+			   AND permissions.action = ?
+			   AND user_roles.deleted_at IS NULL
+			   AND role_permissions.deleted_at IS NULL`, userID, resource, action).
+		Distinct().
+		Pluck("permissions.scope", &scopes).Error
+
+	return scopes, err
+}
+
 func (s *AuthorizationServiceImpl) AssignRole(ctx context.Context, userID, roleID, assignedBy uuid.UUID) error {
 	var existing models.UserRole
 	err := s.db.WithContext(ctx).
@@ -114,13 +148,33 @@ func (s *AuthorizationServiceImpl) AssignRole(ctx context.Context, userID, roleI
 		UpdatedAt:  now,
 	}
 
-	return s.db.WithContext(ctx).Create(&userRole).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&userRole).Error; err != nil {
+			return err
+		}
+
+		_, err := WriteOutbox(tx, models.OutboxAggregateRole, roleID, models.RoleEventAssigned, map[string]interface{}{
+			"user_id":     userID.String(),
+			"role_id":     roleID.String(),
+			"assigned_by": assignedBy.String(),
+		})
+		return err
+	})
 }
 
 func (s *AuthorizationServiceImpl) RevokeRole(ctx context.Context, userID, roleID uuid.UUID) error {
-	return s.db.WithContext(ctx).
-		Where("user_id = ? AND role_id = ?", userID, roleID).
-		Delete(&models.UserRole{}).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		_, err := WriteOutbox(tx, models.OutboxAggregateRole, roleID, models.RoleEventRevoked, map[string]interface{}{
+			"user_id": userID.String(),
+			"role_id": roleID.String(),
+		})
+		return err
+	})
 }
 
 func (s *AuthorizationServiceImpl) IsAuthorized(ctx context.Context, request AuthorizationRequest) (*AuthorizationDecision, error) {
@@ -222,6 +276,29 @@ func (s *AuthorizationServiceImpl) evaluateTaskABACPolicy(ctx context.Context, r
 			return true, "Task owner has access", nil
 		}
 
+		if task.AssigneeID != nil && *task.AssigneeID == request.UserID {
+			if request.Action == "read" || request.Action == "update" {
+				return true, "Task assignee has access", nil
+			}
+		}
+
+		if task.TeamID != nil {
+			var member models.TeamMember
+			err := s.db.WithContext(ctx).
+				Where("team_id = ? AND user_id = ?", *task.TeamID, request.UserID).
+				First(&member).Error
+			if err == nil {
+				if request.Action == "read" {
+					return true, "Team member can view team task", nil
+				}
+				if member.IsLead && request.Action == "update" {
+					return true, "Team lead can update team task", nil
+				}
+			} else if err != gorm.ErrRecordNotFound {
+				return false, "Failed to check team membership", err
+			}
+		}
+
 		if userDept, exists := userAttrs["department"]; exists {
 			var taskOwner models.User
 			err := s.db.WithContext(ctx).
@@ -47,7 +47,9 @@ func (suite *AuthorizationTestSuite) SetupSuite() {
 			department TEXT,
 			position TEXT,
 			is_active BOOLEAN DEFAULT true,
-			last_login_at DATETIME
+			last_login_at DATETIME,
+			email_verified_at DATETIME,
+			verification_sent_at DATETIME
 		)
 	`).Error
 	suite.Require().NoError(err)
@@ -72,6 +74,7 @@ func (suite *AuthorizationTestSuite) SetupSuite() {
 			name TEXT,
 			resource TEXT NOT NULL,
 			action TEXT NOT NULL,
+			scope TEXT DEFAULT '*',
 			description TEXT,
 			created_at DATETIME,
 			updated_at DATETIME,
@@ -164,6 +167,7 @@ func (suite *AuthorizationTestSuite) SetupSuite() {
 			request_path TEXT,
 			context TEXT,
 			timestamp DATETIME,
+			workspace_id TEXT,
 			FOREIGN KEY (user_id) REFERENCES users(id)
 		)
 	`).Error
@@ -177,6 +181,15 @@ func (suite *AuthorizationTestSuite) SetupSuite() {
 			status TEXT,
 			priority TEXT,
 			due_date DATETIME,
+			recurrence_rule TEXT,
+			labels TEXT,
+			started_at DATETIME,
+			completed_at DATETIME,
+			estimated_hours REAL,
+			sprint_id TEXT,
+			workspace_id TEXT,
+			team_id TEXT,
+			assignee_id TEXT,
 			user_id TEXT,
 			created_at DATETIME,
 			updated_at DATETIME,
@@ -186,6 +199,20 @@ func (suite *AuthorizationTestSuite) SetupSuite() {
 	`).Error
 	suite.Require().NoError(err)
 
+	err = db.Exec(`
+		CREATE TABLE team_members (
+			team_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			is_lead BOOLEAN NOT NULL DEFAULT false,
+			created_at DATETIME,
+			updated_at DATETIME,
+			PRIMARY KEY (team_id, user_id)
+		)
+	`).Error
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(createOutboxTable(db))
+
 	suite.db = db
 
 	suite.service = services.NewAuthorizationService(db)
@@ -201,6 +228,7 @@ func (suite *AuthorizationTestSuite) SetupTest() {
 	suite.db.Exec("DELETE FROM roles")
 	suite.db.Exec("DELETE FROM users")
 	suite.db.Exec("DELETE FROM tasks")
+	suite.db.Exec("DELETE FROM team_members")
 
 	suite.userID = uuid.Must(uuid.NewV4())
 	suite.adminID = uuid.Must(uuid.NewV4())
@@ -479,6 +507,27 @@ func (suite *AuthorizationTestSuite) TestHasPermission() {
 	assert.True(suite.T(), hasPerm)
 }
 
+func (suite *AuthorizationTestSuite) TestPermissionScopes() {
+	ctx := context.Background()
+
+	scopes, err := suite.service.PermissionScopes(ctx, suite.userID, "task", "read")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"*"}, scopes)
+
+	departmentPerm := models.Permission{ID: uuid.Must(uuid.NewV4()), Name: "task:read:department", Resource: "task", Action: "read"}
+	suite.Require().NoError(suite.db.Create(&departmentPerm).Error)
+	suite.Require().NoError(suite.db.Exec("UPDATE permissions SET scope = 'department' WHERE id = ?", departmentPerm.ID).Error)
+	suite.Require().NoError(suite.db.Create(&models.RolePermission{RoleID: suite.userRole.ID, PermissionID: departmentPerm.ID}).Error)
+
+	scopes, err = suite.service.PermissionScopes(ctx, suite.userID, "task", "read")
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []string{"*", "department"}, scopes)
+
+	scopes, err = suite.service.PermissionScopes(ctx, suite.userID, "user", "read")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), scopes)
+}
+
 func (suite *AuthorizationTestSuite) TestAssignRole() {
 	ctx := context.Background()
 
@@ -492,6 +541,10 @@ func (suite *AuthorizationTestSuite) TestAssignRole() {
 	assert.NoError(suite.T(), err)
 	assert.True(suite.T(), hasRole)
 
+	var message models.OutboxMessage
+	assert.NoError(suite.T(), suite.db.Where("event_type = ?", models.RoleEventAssigned).First(&message).Error)
+	assert.Equal(suite.T(), newRole.ID, message.AggregateID)
+
 	err = suite.service.AssignRole(ctx, suite.userID, newRole.ID, suite.adminID)
 	assert.Error(suite.T(), err)
 }
@@ -608,6 +661,45 @@ func (suite *AuthorizationTestSuite) TestIsAuthorized_DepartmentAccess() {
 	assert.NotEmpty(suite.T(), decision.Reason)
 }
 
+func (suite *AuthorizationTestSuite) TestIsAuthorized_TeamTasks() {
+	ctx := context.Background()
+
+	teamID := uuid.Must(uuid.NewV4())
+	teamTask := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: suite.userID, Title: "Team task", Status: "pending", TeamID: &teamID}
+	assignedTask := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: suite.managerID, Title: "Assigned task", Status: "pending", AssigneeID: &suite.userID}
+	suite.Require().NoError(suite.db.Create(&teamTask).Error)
+	suite.Require().NoError(suite.db.Create(&assignedTask).Error)
+
+	lead := models.TeamMember{TeamID: teamID, UserID: suite.managerID, IsLead: true}
+	suite.Require().NoError(suite.db.Create(&lead).Error)
+
+	decision, err := suite.service.IsAuthorized(ctx, services.AuthorizationRequest{
+		UserID: suite.managerID, Resource: "task", Action: "update", ResourceID: &teamTask.ID,
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "allowed", decision.Decision)
+
+	decision, err = suite.service.IsAuthorized(ctx, services.AuthorizationRequest{
+		UserID: suite.managerID, Resource: "task", Action: "delete", ResourceID: &teamTask.ID,
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "denied", decision.Decision, "leads update team tasks but do not delete them")
+
+	suite.Require().NoError(suite.db.Model(&lead).Where("team_id = ? AND user_id = ?", teamID, suite.managerID).Update("is_lead", false).Error)
+	decision, err = suite.service.IsAuthorized(ctx, services.AuthorizationRequest{
+		UserID: suite.managerID, Resource: "task", Action: "update", ResourceID: &teamTask.ID,
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "denied", decision.Decision, "members only read team tasks")
+
+	decision, err = suite.service.IsAuthorized(ctx, services.AuthorizationRequest{
+		UserID: suite.userID, Resource: "task", Action: "update", ResourceID: &assignedTask.ID,
+	})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "allowed", decision.Decision)
+	assert.Contains(suite.T(), decision.Reason, "Access granted")
+}
+
 func (suite *AuthorizationTestSuite) TestIsAuthorized_UserProfile() {
 	ctx := context.Background()
 
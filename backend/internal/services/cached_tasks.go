@@ -30,22 +30,16 @@ func NewCachedTaskService(taskService TaskService, cacheInstance *cache.MultiLev
 	return cts
 }
 
+// CreateTask, UpdateTask and DeleteTask evict what the write changes from this
+// instance's cache straight away, so the writer reads its own change. Other
+// instances evict once the change's event is relayed, through the subscribers
+// registered in SubscribeInvalidation; those also catch a read that refilled
+// the cache before the write's transaction committed.
 func (s *CachedTaskService) CreateTask(db *gorm.DB, task models.Task) error {
-	err := s.taskService.CreateTask(db, task)
-	if err != nil {
+	if err := s.taskService.CreateTask(db, task); err != nil {
 		return err
 	}
-
-	cacheKey := fmt.Sprintf("task:%s", task.ID.String())
-	s.cache.Set(cacheKey, task, 30*time.Minute)
-
-	userCachePattern := fmt.Sprintf("user_tasks:%s:*", task.UserID.String())
-	s.cache.DeletePattern(userCachePattern)
-
-	// Invalidate list caches to ensure new task appears in listings
-	s.cache.DeletePattern("tasks_paginated:*")
-	s.cache.Delete("all_tasks")
-
+	s.invalidateTask(task.ID, task.UserID)
 	return nil
 }
 
@@ -55,6 +49,12 @@ func (s *CachedTaskService) GetTaskByID(db *gorm.DB, id uuid.UUID) (models.Task,
 	var cachedTask models.Task
 	err := s.cache.Get(cacheKey, &cachedTask)
 	if err == nil {
+		// Task keys are shared by all workspaces; a hit from another tenant
+		// reads as missing, as the database would.
+		if workspaceID, ok := WorkspaceFromContext(db.Statement.Context); ok &&
+			(cachedTask.WorkspaceID == nil || *cachedTask.WorkspaceID != workspaceID) {
+			return models.Task{}, gorm.ErrRecordNotFound
+		}
 		return cachedTask, nil
 	}
 
@@ -69,7 +69,7 @@ func (s *CachedTaskService) GetTaskByID(db *gorm.DB, id uuid.UUID) (models.Task,
 }
 
 func (s *CachedTaskService) GetTasks(db *gorm.DB) ([]models.Task, error) {
-	cacheKey := "all_tasks"
+	cacheKey := "all_tasks" + workspaceCacheSuffix(db)
 
 	var cachedTasks []models.Task
 	err := s.cache.Get(cacheKey, &cachedTasks)
@@ -88,7 +88,7 @@ func (s *CachedTaskService) GetTasks(db *gorm.DB) ([]models.Task, error) {
 }
 
 func (s *CachedTaskService) GetTasksPaginated(db *gorm.DB, sortBy, order, page, pageSize string) ([]models.Task, int64, error) {
-	cacheKey := fmt.Sprintf("tasks_paginated:%s:%s:%s:%s", sortBy, order, page, pageSize)
+	cacheKey := fmt.Sprintf("tasks_paginated:%s:%s:%s:%s", sortBy, order, page, pageSize) + workspaceCacheSuffix(db)
 
 	var cachedResult struct {
 		Tasks []models.Task `json:"tasks"`
@@ -118,50 +118,85 @@ func (s *CachedTaskService) GetTasksPaginated(db *gorm.DB, sortBy, order, page,
 }
 
 func (s *CachedTaskService) UpdateTask(db *gorm.DB, id uuid.UUID, updated models.Task) error {
-	err := s.taskService.UpdateTask(db, id, updated)
-	if err != nil {
+	owners := s.taskOwners(db, id)
+	if err := s.taskService.UpdateTask(db, id, updated); err != nil {
 		return err
 	}
+	s.invalidateTask(id, append(owners, updated.UserID)...)
+	return nil
+}
 
-	cacheKey := fmt.Sprintf("task:%s", id.String())
-	s.cache.Delete(cacheKey)
-
-	task, getErr := s.taskService.GetTaskByID(db, id)
-	if getErr == nil {
-		userCachePattern := fmt.Sprintf("user_tasks:%s:*", task.UserID.String())
-		s.cache.DeletePattern(userCachePattern)
+func (s *CachedTaskService) DeleteTask(db *gorm.DB, id uuid.UUID) error {
+	owners := s.taskOwners(db, id)
+	if err := s.taskService.DeleteTask(db, id); err != nil {
+		return err
 	}
+	s.invalidateTask(id, owners...)
+	return nil
+}
+
+// taskOwners returns the owner of the task as stored, whose listing a write to
+// it changes. A task that cannot be read has no listing to evict.
+func (s *CachedTaskService) taskOwners(db *gorm.DB, id uuid.UUID) []uuid.UUID {
+	var owners []uuid.UUID
+	db.Model(&models.Task{}).Where("id = ?", id).Pluck("user_id", &owners)
+	return owners
+}
 
+// invalidateTask evicts the task, its owners' listings and every shared
+// listing.
+func (s *CachedTaskService) invalidateTask(taskID uuid.UUID, ownerIDs ...uuid.UUID) {
+	s.cache.Delete(fmt.Sprintf("task:%s", taskID.String()))
+	for _, ownerID := range ownerIDs {
+		if ownerID != uuid.Nil {
+			s.invalidateUserTasks(ownerID)
+		}
+	}
 	s.cache.DeletePattern("tasks_paginated:*")
 	s.cache.Delete("all_tasks")
-
-	return nil
+	s.cache.DeletePattern("all_tasks:*")
 }
 
-func (s *CachedTaskService) DeleteTask(db *gorm.DB, id uuid.UUID) error {
-	task, getErr := s.taskService.GetTaskByID(db, id)
+// SubscribeInvalidation evicts cached tasks and listings when task events are
+// published, covering writes made through other instances.
+func (s *CachedTaskService) SubscribeInvalidation(bus *EventBus) {
+	invalidate := func(db *gorm.DB, event DomainEvent) error {
+		task, ok := event.(taskDomainEvent)
+		if !ok {
+			return nil
+		}
 
-	err := s.taskService.DeleteTask(db, id)
-	if err != nil {
-		return err
+		s.invalidateTask(task.Task().TaskID, task.Task().OwnerID)
+		return nil
 	}
 
-	cacheKey := fmt.Sprintf("task:%s", id.String())
-	s.cache.Delete(cacheKey)
-
-	if getErr == nil {
-		userCachePattern := fmt.Sprintf("user_tasks:%s:*", task.UserID.String())
-		s.cache.DeletePattern(userCachePattern)
+	for _, name := range []string{models.TaskEventCreated, models.TaskEventUpdated, models.TaskEventStatusChanged, models.TaskEventDeleted} {
+		bus.Subscribe(name, invalidate)
 	}
 
-	s.cache.DeletePattern("tasks_paginated:*")
-	s.cache.Delete("all_tasks")
+	On(bus, func(db *gorm.DB, event UserDeleted) error {
+		s.invalidateUserTasks(event.UserID)
+		return nil
+	})
+}
 
-	return nil
+// workspaceCacheSuffix keeps listings of different workspaces apart. Unscoped
+// reads keep the bare keys the cache warmer fills.
+func workspaceCacheSuffix(db *gorm.DB) string {
+	if workspaceID, ok := WorkspaceFromContext(db.Statement.Context); ok {
+		return ":" + workspaceID.String()
+	}
+	return ""
+}
+
+func (s *CachedTaskService) invalidateUserTasks(userID uuid.UUID) {
+	userCacheKey := fmt.Sprintf("user_tasks:%s", userID.String())
+	s.cache.Delete(userCacheKey)
+	s.cache.DeletePattern(userCacheKey + ":*")
 }
 
 func (s *CachedTaskService) GetTasksByUser(db *gorm.DB, userID uuid.UUID) ([]models.Task, error) {
-	cacheKey := fmt.Sprintf("user_tasks:%s", userID.String())
+	cacheKey := fmt.Sprintf("user_tasks:%s", userID.String()) + workspaceCacheSuffix(db)
 
 	var cachedTasks []models.Task
 	err := s.cache.Get(cacheKey, &cachedTasks)
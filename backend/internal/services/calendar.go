@@ -0,0 +1,317 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CalendarComponentEvent = "VEVENT"
+	CalendarComponentTodo  = "VTODO"
+
+	calendarTokenPrefix = "cal_"
+	calendarProdID      = "-//Taskify//Task Calendar//EN"
+	calendarUIDDomain   = "taskify"
+)
+
+var ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+
+// CalendarFilter narrows a feed. A task must carry every label in Labels.
+type CalendarFilter struct {
+	Component string
+	Labels    []string
+}
+
+type CalendarService interface {
+	RegenerateToken(db *gorm.DB, userID uuid.UUID) (string, error)
+	RevokeToken(db *gorm.DB, userID uuid.UUID) error
+	GetFeed(db *gorm.DB, token string) (*models.CalendarFeed, error)
+	GetFeedTasks(db *gorm.DB, userID uuid.UUID, filter CalendarFilter) ([]models.Task, error)
+}
+
+type CalendarServiceImpl struct{}
+
+func NewCalendarService() *CalendarServiceImpl {
+	return &CalendarServiceImpl{}
+}
+
+// RegenerateToken issues a new feed token, invalidating any previous one.
+func (s *CalendarServiceImpl) RegenerateToken(db *gorm.DB, userID uuid.UUID) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	token := calendarTokenPrefix + hex.EncodeToString(buf)
+
+	feed := models.CalendarFeed{UserID: userID, TokenHash: hashCalendarToken(token)}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"token_hash": feed.TokenHash, "last_accessed_at": nil, "updated_at": time.Now()}),
+	}).Create(&feed).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to save calendar token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *CalendarServiceImpl) RevokeToken(db *gorm.DB, userID uuid.UUID) error {
+	return db.Where("user_id = ?", userID).Delete(&models.CalendarFeed{}).Error
+}
+
+// GetFeed looks a feed up by its token and records the access.
+func (s *CalendarServiceImpl) GetFeed(db *gorm.DB, token string) (*models.CalendarFeed, error) {
+	if !strings.HasPrefix(token, calendarTokenPrefix) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var feed models.CalendarFeed
+	if err := db.Where("token_hash = ?", hashCalendarToken(token)).First(&feed).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	feed.LastAccessedAt = &now
+	db.Model(&feed).UpdateColumn("last_accessed_at", now)
+
+	return &feed, nil
+}
+
+// GetFeedTasks returns the user's tasks that have a due date, soonest first.
+func (s *CalendarServiceImpl) GetFeedTasks(db *gorm.DB, userID uuid.UUID, filter CalendarFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	err := db.Where("user_id = ? AND due_date IS NOT NULL", userID).
+		Order("due_date ASC").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filter.Labels) == 0 {
+		return tasks, nil
+	}
+
+	filtered := tasks[:0]
+	for _, task := range tasks {
+		if hasAllLabels(task.Labels, filter.Labels) {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered, nil
+}
+
+func hasAllLabels(labels models.StringList, wanted []string) bool {
+	for _, label := range wanted {
+		if !labels.Contains(label) {
+			return false
+		}
+	}
+	return true
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RenderCalendar writes tasks as an RFC 5545 calendar. Each task becomes a
+// VEVENT at its due time, or a VTODO due then.
+func RenderCalendar(name string, tasks []models.Task, component string, now time.Time) string {
+	if component != CalendarComponentTodo {
+		component = CalendarComponentEvent
+	}
+
+	var b strings.Builder
+	line := func(name, value string) {
+		writeCalendarLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", calendarProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeCalendarText(name))
+
+	stamp := formatCalendarTime(now)
+	for _, task := range tasks {
+		if task.DueDate == nil {
+			continue
+		}
+		due := formatCalendarTime(*task.DueDate)
+
+		line("BEGIN", component)
+		line("UID", task.ID.String()+"@"+calendarUIDDomain)
+		line("DTSTAMP", stamp)
+		line("CREATED", formatCalendarTime(task.CreatedAt))
+		line("LAST-MODIFIED", formatCalendarTime(task.UpdatedAt))
+		line("SUMMARY", escapeCalendarText(task.Title))
+		if task.Description != "" {
+			line("DESCRIPTION", escapeCalendarText(task.Description))
+		}
+		if len(task.Labels) > 0 {
+			escaped := make([]string, len(task.Labels))
+			for i, label := range task.Labels {
+				escaped[i] = escapeCalendarText(label)
+			}
+			line("CATEGORIES", strings.Join(escaped, ","))
+		}
+
+		// RRULE expands from DTSTART, so todos carry one as well as DUE.
+		line("DTSTART", due)
+		if component == CalendarComponentTodo {
+			line("DUE", due)
+			line("STATUS", calendarTodoStatus(task.Status))
+			if models.IsCompletedStatus(task.Status) {
+				line("COMPLETED", formatCalendarTime(task.UpdatedAt))
+			}
+		}
+		if task.RecurrenceRule != "" {
+			line("RRULE", task.RecurrenceRule)
+		}
+		line("END", component)
+	}
+
+	line("END", "VCALENDAR")
+	return b.String()
+}
+
+func calendarTodoStatus(status string) string {
+	switch status {
+	case "completed", "done":
+		return "COMPLETED"
+	case "in_progress":
+		return "IN-PROCESS"
+	case "cancelled":
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func formatCalendarTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var calendarTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "")
+
+func escapeCalendarText(s string) string {
+	return calendarTextEscaper.Replace(s)
+}
+
+// writeCalendarLine folds content lines longer than 75 octets, never splitting
+// a UTF-8 sequence, and terminates them with CRLF.
+func writeCalendarLine(b *strings.Builder, content string) {
+	const limit = 75
+
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+}
+
+var recurrenceFrequencies = map[string]bool{"DAILY": true, "WEEKLY": true, "MONTHLY": true, "YEARLY": true}
+var recurrenceWeekdays = map[string]bool{"MO": true, "TU": true, "WE": true, "TH": true, "FR": true, "SA": true, "SU": true}
+
+// NormalizeRecurrenceRule validates an RRULE value and returns it upper-cased
+// without an "RRULE:" prefix. Only the parts a task calendar needs are
+// accepted: FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH.
+func NormalizeRecurrenceRule(rule string) (string, error) {
+	rule = strings.ToUpper(strings.TrimSpace(rule))
+	rule = strings.TrimPrefix(rule, "RRULE:")
+	if rule == "" {
+		return "", nil
+	}
+
+	invalid := func(format string, args ...interface{}) (string, error) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRecurrenceRule, fmt.Sprintf(format, args...))
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return invalid("malformed part %q", part)
+		}
+		if seen[key] {
+			return invalid("%s given twice", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			if !recurrenceFrequencies[value] {
+				return invalid("unsupported FREQ %q", value)
+			}
+		case "INTERVAL", "COUNT":
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return invalid("%s must be a positive integer", key)
+			}
+		case "UNTIL":
+			if _, err := time.Parse("20060102T150405Z", value); err != nil {
+				if _, err := time.Parse("20060102", value); err != nil {
+					return invalid("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+				}
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				ordinal := strings.TrimRight(day, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+				if !recurrenceWeekdays[day[len(ordinal):]] {
+					return invalid("unknown weekday %q", day)
+				}
+				if ordinal != "" {
+					if n, err := strconv.Atoi(ordinal); err != nil || n == 0 || n < -53 || n > 53 {
+						return invalid("bad weekday ordinal %q", day)
+					}
+				}
+			}
+		case "BYMONTHDAY":
+			if err := checkRecurrenceList(value, -31, 31); err != nil {
+				return invalid("BYMONTHDAY %v", err)
+			}
+		case "BYMONTH":
+			if err := checkRecurrenceList(value, 1, 12); err != nil {
+				return invalid("BYMONTH %v", err)
+			}
+		default:
+			return invalid("unsupported part %s", key)
+		}
+	}
+
+	if !seen["FREQ"] {
+		return invalid("FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return invalid("COUNT and UNTIL are mutually exclusive")
+	}
+	return rule, nil
+}
+
+func checkRecurrenceList(value string, lo, hi int) error {
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < lo || n > hi {
+			return fmt.Errorf("value %q out of range", item)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupCalendarTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`CREATE TABLE calendar_feeds (
+		user_id TEXT PRIMARY KEY,
+		token_hash TEXT NOT NULL UNIQUE,
+		last_accessed_at DATETIME,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`).Error)
+	createTestTables(t, db, "tasks")
+
+	return db
+}
+
+func TestCalendarService_TokenLifecycle(t *testing.T) {
+	db := setupCalendarTestDB(t)
+	calendarService := services.NewCalendarService()
+	userID := uuid.Must(uuid.NewV4())
+
+	first, err := calendarService.RegenerateToken(db, userID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, "cal_"))
+
+	feed, err := calendarService.GetFeed(db, first)
+	require.NoError(t, err)
+	assert.Equal(t, userID, feed.UserID)
+	assert.NotEqual(t, first, feed.TokenHash, "only the hash is stored")
+
+	second, err := calendarService.RegenerateToken(db, userID)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	_, err = calendarService.GetFeed(db, first)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "regenerating invalidates the old token")
+
+	require.NoError(t, calendarService.RevokeToken(db, userID))
+	_, err = calendarService.GetFeed(db, second)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestCalendarService_GetFeedTasksFiltersLabels(t *testing.T) {
+	db := setupCalendarTestDB(t)
+	calendarService := services.NewCalendarService()
+	userID := uuid.Must(uuid.NewV4())
+
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	later := due.Add(24 * time.Hour)
+	tasks := []models.Task{
+		{ID: uuid.Must(uuid.NewV4()), Title: "Later", UserID: userID, DueDate: &later, Labels: models.StringList{"work"}},
+		{ID: uuid.Must(uuid.NewV4()), Title: "Sooner", UserID: userID, DueDate: &due, Labels: models.StringList{"work", "urgent"}},
+		{ID: uuid.Must(uuid.NewV4()), Title: "Undated", UserID: userID, Labels: models.StringList{"work"}},
+		{ID: uuid.Must(uuid.NewV4()), Title: "Someone else's", UserID: uuid.Must(uuid.NewV4()), DueDate: &due},
+	}
+	require.NoError(t, db.Create(&tasks).Error)
+
+	all, err := calendarService.GetFeedTasks(db, userID, services.CalendarFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "Sooner", all[0].Title)
+	assert.Equal(t, "Later", all[1].Title)
+
+	urgent, err := calendarService.GetFeedTasks(db, userID, services.CalendarFilter{Labels: []string{"work", "urgent"}})
+	require.NoError(t, err)
+	require.Len(t, urgent, 1)
+	assert.Equal(t, "Sooner", urgent[0].Title)
+}
+
+func TestRenderCalendar(t *testing.T) {
+	due := time.Date(2026, 3, 2, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	task := models.Task{
+		ID:             uuid.Must(uuid.NewV4()),
+		Title:          "Review; budget, Q1",
+		Description:    strings.Repeat("long line ", 12) + "\nsecond",
+		Status:         "in_progress",
+		DueDate:        &due,
+		RecurrenceRule: "FREQ=WEEKLY;BYDAY=MO",
+		Labels:         models.StringList{"finance", "q1"},
+	}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	event := services.RenderCalendar("alice's tasks", []models.Task{task, {Title: "undated"}}, services.CalendarComponentEvent, now)
+	assert.True(t, strings.HasPrefix(event, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(event, "END:VCALENDAR\r\n"))
+	assert.Contains(t, event, "BEGIN:VEVENT\r\n")
+	assert.Contains(t, event, "UID:"+task.ID.String()+"@")
+	assert.Contains(t, event, "DTSTART:20260302T083000Z\r\n")
+	assert.Contains(t, event, `SUMMARY:Review\; budget\, Q1`+"\r\n")
+	assert.Contains(t, event, "CATEGORIES:finance,q1\r\n")
+	assert.Contains(t, event, "RRULE:FREQ=WEEKLY;BYDAY=MO\r\n")
+	assert.NotContains(t, event, "undated", "tasks without a due date are skipped")
+	assert.NotContains(t, event, "DUE:")
+
+	for _, line := range strings.Split(strings.TrimSuffix(event, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line %q is not folded", line)
+	}
+	unfolded := strings.ReplaceAll(event, "\r\n ", "")
+	assert.Contains(t, unfolded, "DESCRIPTION:"+strings.Repeat("long line ", 12)+`\nsecond`+"\r\n")
+
+	todo := services.RenderCalendar("tasks", []models.Task{task}, services.CalendarComponentTodo, now)
+	assert.Contains(t, todo, "BEGIN:VTODO\r\n")
+	assert.Contains(t, todo, "DUE:20260302T083000Z\r\n")
+	assert.Contains(t, todo, "STATUS:IN-PROCESS\r\n")
+}
+
+func TestNormalizeRecurrenceRule(t *testing.T) {
+	valid := map[string]string{
+		"":                                     "",
+		"freq=daily":                           "FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR":     "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+		"FREQ=MONTHLY;BYDAY=-1FR;COUNT=6":      "FREQ=MONTHLY;BYDAY=-1FR;COUNT=6",
+		"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1":   "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1",
+		"FREQ=DAILY;INTERVAL=2;UNTIL=20261231": "FREQ=DAILY;INTERVAL=2;UNTIL=20261231",
+	}
+	for input, want := range valid {
+		got, err := services.NormalizeRecurrenceRule(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got)
+	}
+
+	for _, input := range []string{
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20261231",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=DAILY;BYSECOND=5",
+		"FREQ=DAILY;\r\nX-INJECT:1",
+	} {
+		_, err := services.NormalizeRecurrenceRule(input)
+		assert.ErrorIs(t, err, services.ErrInvalidRecurrenceRule, input)
+	}
+}
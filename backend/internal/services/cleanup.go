@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"task-manager/backend/internal/worker"
+
+	"gorm.io/gorm"
+)
+
+// CleanupFunc removes stale rows and reports how many were deleted.
+type CleanupFunc func(db *gorm.DB) (int64, error)
+
+// NewCleanupJobHandler returns the worker handler for cleanup jobs. The job
+// payload's "target" selects which registered cleanup to run.
+func NewCleanupJobHandler(db *gorm.DB, targets map[string]CleanupFunc) worker.JobHandler {
+	return func(ctx context.Context, job *worker.Job) error {
+		target, _ := job.Payload["target"].(string)
+
+		cleanup, ok := targets[target]
+		if !ok {
+			return fmt.Errorf("unknown cleanup target: %q", target)
+		}
+
+		deleted, err := cleanup(db.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("%s cleanup failed: %w", target, err)
+		}
+
+		log.Printf("Cleanup %s removed %d rows", target, deleted)
+		return nil
+	}
+}
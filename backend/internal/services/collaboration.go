@@ -0,0 +1,370 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	CollabMessageSubscribe   = "subscribe"
+	CollabMessageUnsubscribe = "unsubscribe"
+	CollabMessageLock        = "lock"
+	CollabMessageUnlock      = "unlock"
+	CollabMessagePing        = "ping"
+	CollabMessagePong        = "pong"
+	CollabMessageSubscribed  = "subscribed"
+	CollabMessagePresence    = "presence"
+	CollabMessageLocked      = "locked"
+	CollabMessageUnlocked    = "unlocked"
+	CollabMessageError       = "error"
+
+	defaultEditLockTTL   = time.Minute
+	collabSendBufferSize = 32
+)
+
+var (
+	ErrNotSubscribed = errors.New("not subscribed to topic")
+	ErrLockHeld      = errors.New("topic field is locked by another user")
+	ErrLockNotHeld   = errors.New("lock is not held by this connection")
+)
+
+// CollabMessage is the envelope exchanged with collaboration clients in both
+// directions. Only the fields relevant to Type are set.
+type CollabMessage struct {
+	Type    string           `json:"type"`
+	Topic   string           `json:"topic,omitempty"`
+	Field   string           `json:"field,omitempty"`
+	Users   []CollabPresence `json:"users,omitempty"`
+	Locks   []EditLock       `json:"locks,omitempty"`
+	Lock    *EditLock        `json:"lock,omitempty"`
+	Message string           `json:"message,omitempty"`
+}
+
+type CollabPresence struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+// EditLock marks a field of a topic as being edited. Locks expire unless the
+// holder renews them by locking again, so a crashed client cannot hold one forever.
+type EditLock struct {
+	Field     string    `json:"field"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CollabClient is one connection to the hub. The transport reads outgoing
+// messages from Messages until it is closed.
+type CollabClient struct {
+	UserID   uuid.UUID
+	Username string
+
+	send    chan CollabMessage
+	topics  map[string]struct{}
+	dropped bool
+}
+
+func (c *CollabClient) Messages() <-chan CollabMessage {
+	return c.send
+}
+
+type collabLock struct {
+	holder    *CollabClient
+	expiresAt time.Time
+}
+
+type collabTopic struct {
+	clients map[*CollabClient]struct{}
+	locks   map[string]*collabLock
+}
+
+// CollaborationHub tracks who is viewing each topic and which fields they are
+// editing. State is held in process, so clients only see peers connected to
+// the same instance.
+type CollaborationHub struct {
+	mu      sync.Mutex
+	clients map[*CollabClient]struct{}
+	topics  map[string]*collabTopic
+	lockTTL time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewCollaborationHub(lockTTL time.Duration) *CollaborationHub {
+	if lockTTL <= 0 {
+		lockTTL = defaultEditLockTTL
+	}
+
+	return &CollaborationHub{
+		clients: make(map[*CollabClient]struct{}),
+		topics:  make(map[string]*collabTopic),
+		lockTTL: lockTTL,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start periodically releases expired edit locks.
+func (h *CollaborationHub) Start(interval time.Duration) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.stop:
+				return
+			case now := <-ticker.C:
+				h.ExpireLocks(now)
+			}
+		}
+	}()
+}
+
+// Stop halts lock expiry and disconnects every client.
+func (h *CollaborationHub) Stop() {
+	close(h.stop)
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		h.drop(client)
+	}
+}
+
+func (h *CollaborationHub) Register(userID uuid.UUID, username string) *CollabClient {
+	client := &CollabClient{
+		UserID:   userID,
+		Username: username,
+		send:     make(chan CollabMessage, collabSendBufferSize),
+		topics:   make(map[string]struct{}),
+	}
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	return client
+}
+
+// Unregister removes a client from all topics, releasing its locks and
+// announcing the departure to remaining viewers.
+func (h *CollaborationHub) Unregister(client *CollabClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for topic := range client.topics {
+		h.leave(client, topic)
+	}
+	delete(h.clients, client)
+	h.drop(client)
+}
+
+// Join subscribes a client to a topic. The client receives the current
+// presence and locks; other viewers receive the updated presence.
+func (h *CollaborationHub) Join(client *CollabClient, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[topic]
+	if !ok {
+		t = &collabTopic{
+			clients: make(map[*CollabClient]struct{}),
+			locks:   make(map[string]*collabLock),
+		}
+		h.topics[topic] = t
+	}
+
+	t.clients[client] = struct{}{}
+	client.topics[topic] = struct{}{}
+
+	h.deliver(client, CollabMessage{
+		Type:  CollabMessageSubscribed,
+		Topic: topic,
+		Users: t.presence(),
+		Locks: t.lockList(),
+	})
+	h.broadcastPresence(topic, t)
+}
+
+func (h *CollaborationHub) Leave(client *CollabClient, topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.leave(client, topic)
+}
+
+// Lock acquires or renews an edit lock on a topic field. New locks are
+// announced to every viewer; a renewal is only confirmed to its holder.
+func (h *CollaborationHub) Lock(client *CollabClient, topic, field string) (EditLock, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[topic]
+	if !ok || !t.has(client) {
+		return EditLock{}, ErrNotSubscribed
+	}
+
+	expiresAt := time.Now().Add(h.lockTTL)
+	if existing, ok := t.locks[field]; ok && existing.expiresAt.After(time.Now()) {
+		if existing.holder != client {
+			return existing.toEditLock(field), ErrLockHeld
+		}
+		existing.expiresAt = expiresAt
+		renewed := existing.toEditLock(field)
+		h.deliver(client, CollabMessage{Type: CollabMessageLocked, Topic: topic, Field: field, Lock: &renewed})
+		return renewed, nil
+	}
+
+	lock := &collabLock{holder: client, expiresAt: expiresAt}
+	t.locks[field] = lock
+
+	editLock := lock.toEditLock(field)
+	h.broadcast(t, CollabMessage{Type: CollabMessageLocked, Topic: topic, Field: field, Lock: &editLock})
+	return editLock, nil
+}
+
+func (h *CollaborationHub) Unlock(client *CollabClient, topic, field string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[topic]
+	if !ok {
+		return ErrLockNotHeld
+	}
+
+	lock, ok := t.locks[field]
+	if !ok || lock.holder != client {
+		return ErrLockNotHeld
+	}
+
+	delete(t.locks, field)
+	h.broadcast(t, CollabMessage{Type: CollabMessageUnlocked, Topic: topic, Field: field})
+	return nil
+}
+
+// ExpireLocks releases every lock whose holder stopped renewing it.
+func (h *CollaborationHub) ExpireLocks(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for topic, t := range h.topics {
+		for field, lock := range t.locks {
+			if !lock.expiresAt.After(now) {
+				delete(t.locks, field)
+				h.broadcast(t, CollabMessage{Type: CollabMessageUnlocked, Topic: topic, Field: field})
+			}
+		}
+	}
+}
+
+// Send queues a message for a single client, e.g. a reply to its request.
+func (h *CollaborationHub) Send(client *CollabClient, msg CollabMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.deliver(client, msg)
+}
+
+func (h *CollaborationHub) leave(client *CollabClient, topic string) {
+	delete(client.topics, topic)
+
+	t, ok := h.topics[topic]
+	if !ok || !t.has(client) {
+		return
+	}
+	delete(t.clients, client)
+
+	for field, lock := range t.locks {
+		if lock.holder == client {
+			delete(t.locks, field)
+			h.broadcast(t, CollabMessage{Type: CollabMessageUnlocked, Topic: topic, Field: field})
+		}
+	}
+
+	if len(t.clients) == 0 {
+		delete(h.topics, topic)
+		return
+	}
+	h.broadcastPresence(topic, t)
+}
+
+func (h *CollaborationHub) broadcastPresence(topic string, t *collabTopic) {
+	h.broadcast(t, CollabMessage{Type: CollabMessagePresence, Topic: topic, Users: t.presence()})
+}
+
+func (h *CollaborationHub) broadcast(t *collabTopic, msg CollabMessage) {
+	for client := range t.clients {
+		h.deliver(client, msg)
+	}
+}
+
+// deliver never blocks the hub: a client whose buffer is full is dropped and
+// its transport closes the connection, which then unregisters it.
+func (h *CollaborationHub) deliver(client *CollabClient, msg CollabMessage) {
+	if client.dropped {
+		return
+	}
+
+	select {
+	case client.send <- msg:
+	default:
+		h.drop(client)
+	}
+}
+
+func (h *CollaborationHub) drop(client *CollabClient) {
+	if !client.dropped {
+		client.dropped = true
+		close(client.send)
+	}
+}
+
+func (t *collabTopic) has(client *CollabClient) bool {
+	_, ok := t.clients[client]
+	return ok
+}
+
+// presence lists each viewing user once, however many connections they have.
+func (t *collabTopic) presence() []CollabPresence {
+	seen := make(map[uuid.UUID]bool, len(t.clients))
+	users := make([]CollabPresence, 0, len(t.clients))
+	for client := range t.clients {
+		if seen[client.UserID] {
+			continue
+		}
+		seen[client.UserID] = true
+		users = append(users, CollabPresence{UserID: client.UserID, Username: client.Username})
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+func (t *collabTopic) lockList() []EditLock {
+	locks := make([]EditLock, 0, len(t.locks))
+	for field, lock := range t.locks {
+		locks = append(locks, lock.toEditLock(field))
+	}
+
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Field < locks[j].Field })
+	return locks
+}
+
+func (l *collabLock) toEditLock(field string) EditLock {
+	return EditLock{
+		Field:     field,
+		UserID:    l.holder.UserID,
+		Username:  l.holder.Username,
+		ExpiresAt: l.expiresAt,
+	}
+}
@@ -0,0 +1,138 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainCollab returns every message queued for a client so far.
+func drainCollab(client *services.CollabClient) []services.CollabMessage {
+	var msgs []services.CollabMessage
+	for {
+		select {
+		case msg, ok := <-client.Messages():
+			if !ok {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func lastCollabMessage(t *testing.T, client *services.CollabClient, msgType string) services.CollabMessage {
+	t.Helper()
+	msgs := drainCollab(client)
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Type == msgType {
+			return msgs[i]
+		}
+	}
+	t.Fatalf("no %q message received, got %+v", msgType, msgs)
+	return services.CollabMessage{}
+}
+
+func TestCollaborationHub_PresenceDeduplicatesUsers(t *testing.T) {
+	hub := services.NewCollaborationHub(time.Minute)
+	topic := "task:" + uuid.Must(uuid.NewV4()).String()
+
+	aliceID := uuid.Must(uuid.NewV4())
+	alice := hub.Register(aliceID, "alice")
+	aliceSecondTab := hub.Register(aliceID, "alice")
+	bob := hub.Register(uuid.Must(uuid.NewV4()), "bob")
+
+	hub.Join(alice, topic)
+	hub.Join(aliceSecondTab, topic)
+	hub.Join(bob, topic)
+
+	subscribed := lastCollabMessage(t, bob, services.CollabMessageSubscribed)
+	require.Len(t, subscribed.Users, 2)
+	assert.Equal(t, "alice", subscribed.Users[0].Username)
+	assert.Equal(t, "bob", subscribed.Users[1].Username)
+
+	hub.Unregister(bob)
+	presence := lastCollabMessage(t, alice, services.CollabMessagePresence)
+	require.Len(t, presence.Users, 1)
+	assert.Equal(t, aliceID, presence.Users[0].UserID)
+}
+
+func TestCollaborationHub_EditLocks(t *testing.T) {
+	hub := services.NewCollaborationHub(time.Minute)
+	topic := "task:" + uuid.Must(uuid.NewV4()).String()
+
+	alice := hub.Register(uuid.Must(uuid.NewV4()), "alice")
+	bob := hub.Register(uuid.Must(uuid.NewV4()), "bob")
+
+	_, err := hub.Lock(alice, topic, "description")
+	assert.ErrorIs(t, err, services.ErrNotSubscribed)
+
+	hub.Join(alice, topic)
+	hub.Join(bob, topic)
+	drainCollab(bob)
+
+	lock, err := hub.Lock(alice, topic, "description")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", lock.Username)
+
+	locked := lastCollabMessage(t, bob, services.CollabMessageLocked)
+	assert.Equal(t, "description", locked.Field)
+
+	held, err := hub.Lock(bob, topic, "description")
+	assert.ErrorIs(t, err, services.ErrLockHeld)
+	assert.Equal(t, "alice", held.Username)
+
+	assert.ErrorIs(t, hub.Unlock(bob, topic, "description"), services.ErrLockNotHeld)
+	require.NoError(t, hub.Unlock(alice, topic, "description"))
+	assert.Equal(t, services.CollabMessageUnlocked, lastCollabMessage(t, bob, services.CollabMessageUnlocked).Type)
+
+	_, err = hub.Lock(bob, topic, "description")
+	assert.NoError(t, err)
+}
+
+func TestCollaborationHub_DisconnectReleasesLocks(t *testing.T) {
+	hub := services.NewCollaborationHub(time.Minute)
+	topic := "task:" + uuid.Must(uuid.NewV4()).String()
+
+	alice := hub.Register(uuid.Must(uuid.NewV4()), "alice")
+	bob := hub.Register(uuid.Must(uuid.NewV4()), "bob")
+	hub.Join(alice, topic)
+	hub.Join(bob, topic)
+
+	_, err := hub.Lock(alice, topic, "title")
+	require.NoError(t, err)
+	drainCollab(bob)
+
+	hub.Unregister(alice)
+
+	unlocked := lastCollabMessage(t, bob, services.CollabMessageUnlocked)
+	assert.Equal(t, "title", unlocked.Field)
+
+	// Ranging terminates only once the hub has closed the client's channel.
+	for range alice.Messages() {
+	}
+}
+
+func TestCollaborationHub_ExpireLocks(t *testing.T) {
+	hub := services.NewCollaborationHub(time.Minute)
+	topic := "task:" + uuid.Must(uuid.NewV4()).String()
+
+	alice := hub.Register(uuid.Must(uuid.NewV4()), "alice")
+	hub.Join(alice, topic)
+
+	_, err := hub.Lock(alice, topic, "title")
+	require.NoError(t, err)
+
+	hub.ExpireLocks(time.Now())
+	drainCollab(alice)
+
+	hub.ExpireLocks(time.Now().Add(2 * time.Minute))
+	unlocked := lastCollabMessage(t, alice, services.CollabMessageUnlocked)
+	assert.Equal(t, "title", unlocked.Field)
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EmailTemplateDigest = "digest"
+
+	digestSectionLimit = 50
+)
+
+var ErrInvalidDigestPreference = errors.New("invalid digest preference")
+
+// Digest is what happened to a user's tasks over one digest period, plus the
+// open tasks due on the day it is sent or earlier.
+type Digest struct {
+	Date        time.Time
+	DueToday    []models.Task
+	Overdue     []models.Task
+	Assignments []models.Notification
+	Mentions    []models.Notification
+	Activity    []models.Notification
+}
+
+func (d *Digest) Empty() bool {
+	return len(d.DueToday)+len(d.Overdue)+len(d.Assignments)+len(d.Mentions)+len(d.Activity) == 0
+}
+
+type DigestService interface {
+	GetPreference(db *gorm.DB, userID uuid.UUID) (*models.DigestPreference, error)
+	SetPreference(db *gorm.DB, pref models.DigestPreference) (*models.DigestPreference, error)
+	ListSchedules(db *gorm.DB) ([]worker.DigestSchedule, error)
+	BuildDigest(db *gorm.DB, userID uuid.UUID, periodStart, periodEnd time.Time, loc *time.Location) (*Digest, error)
+}
+
+type DigestServiceImpl struct{}
+
+func NewDigestService() *DigestServiceImpl {
+	return &DigestServiceImpl{}
+}
+
+// GetPreference returns the user's digest settings, or the defaults (digest
+// off, 08:00 UTC, Mondays for weekly) if they have none.
+func (s *DigestServiceImpl) GetPreference(db *gorm.DB, userID uuid.UUID) (*models.DigestPreference, error) {
+	pref := models.DigestPreference{
+		UserID:    userID,
+		Frequency: worker.DigestFrequencyOff,
+		Timezone:  "UTC",
+		Hour:      8,
+		Weekday:   int(time.Monday),
+	}
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&pref).Error; err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// SetPreference validates and saves digest settings. Turning the digest on
+// starts its first period now, so it does not replay older activity.
+func (s *DigestServiceImpl) SetPreference(db *gorm.DB, pref models.DigestPreference) (*models.DigestPreference, error) {
+	switch pref.Frequency {
+	case worker.DigestFrequencyOff, worker.DigestFrequencyDaily, worker.DigestFrequencyWeekly:
+	default:
+		return nil, fmt.Errorf("%w: frequency must be off, daily or weekly", ErrInvalidDigestPreference)
+	}
+	if _, err := time.LoadLocation(pref.Timezone); err != nil || pref.Timezone == "" || pref.Timezone == "Local" {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidDigestPreference, pref.Timezone)
+	}
+	if pref.Hour < 0 || pref.Hour > 23 {
+		return nil, fmt.Errorf("%w: hour must be between 0 and 23", ErrInvalidDigestPreference)
+	}
+	if pref.Weekday < 0 || pref.Weekday > 6 {
+		return nil, fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6", ErrInvalidDigestPreference)
+	}
+
+	current, err := s.GetPreference(db, pref.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pref.PeriodEnd = current.PeriodEnd
+	if current.Frequency == worker.DigestFrequencyOff || pref.PeriodEnd.IsZero() {
+		pref.PeriodEnd = now
+	}
+	pref.CreatedAt = now
+	pref.UpdatedAt = now
+
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"frequency", "timezone", "hour", "weekday", "period_end", "updated_at"}),
+	}).Create(&pref).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPreference(db, pref.UserID)
+}
+
+// ListSchedules returns the schedules of every active user with a digest on.
+func (s *DigestServiceImpl) ListSchedules(db *gorm.DB) ([]worker.DigestSchedule, error) {
+	var prefs []models.DigestPreference
+	err := db.Table("digest_preferences dp").
+		Select("dp.*").
+		Joins("JOIN users u ON u.id = dp.user_id AND u.deleted_at IS NULL").
+		Where("dp.frequency <> ?", worker.DigestFrequencyOff).
+		Find(&prefs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]worker.DigestSchedule, 0, len(prefs))
+	for _, pref := range prefs {
+		schedules = append(schedules, worker.DigestSchedule{
+			UserID:    pref.UserID.String(),
+			Frequency: pref.Frequency,
+			Timezone:  pref.Timezone,
+			Hour:      pref.Hour,
+			Weekday:   time.Weekday(pref.Weekday),
+			PeriodEnd: pref.PeriodEnd,
+		})
+	}
+	return schedules, nil
+}
+
+// BuildDigest collects the user's notifications from the period and the open
+// tasks assigned to them, or owned and unassigned, due by the end of the day
+// periodEnd falls on in loc.
+func (s *DigestServiceImpl) BuildDigest(db *gorm.DB, userID uuid.UUID, periodStart, periodEnd time.Time, loc *time.Location) (*Digest, error) {
+	local := periodEnd.In(loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	digest := &Digest{Date: dayStart}
+
+	openTasks := db.Model(&models.Task{}).
+		Where("COALESCE(assignee_id, user_id) = ? AND due_date IS NOT NULL AND status NOT IN ?", userID, closedTaskStatuses).
+		Order("due_date").Limit(digestSectionLimit)
+	if err := openTasks.Session(&gorm.Session{}).Where("due_date >= ? AND due_date < ?", dayStart.UTC(), dayEnd.UTC()).Find(&digest.DueToday).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tasks due today: %w", err)
+	}
+	if err := openTasks.Session(&gorm.Session{}).Where("due_date < ?", dayStart.UTC()).Find(&digest.Overdue).Error; err != nil {
+		return nil, fmt.Errorf("failed to load overdue tasks: %w", err)
+	}
+
+	var notifications []models.Notification
+	err := db.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, periodStart.UTC(), periodEnd.UTC()).
+		Order("created_at").Limit(3 * digestSectionLimit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load activity: %w", err)
+	}
+	for _, notification := range notifications {
+		switch notification.Type {
+		case models.NotificationTypeAssigned:
+			digest.Assignments = append(digest.Assignments, notification)
+		case models.NotificationTypeMentioned:
+			digest.Mentions = append(digest.Mentions, notification)
+		default:
+			digest.Activity = append(digest.Activity, notification)
+		}
+	}
+
+	return digest, nil
+}
+
+// digestEmailData is the digest template's data. Only non-empty sections are
+// included, in the order they are shown.
+func digestEmailData(digest *Digest, frequency string) map[string]interface{} {
+	sections := []interface{}{}
+	addTasks := func(heading string, tasks []models.Task) {
+		if len(tasks) == 0 {
+			return
+		}
+		items := make([]interface{}, 0, len(tasks))
+		for _, task := range tasks {
+			item := map[string]interface{}{"title": task.Title, "task_id": task.ID.String()}
+			if task.DueDate != nil {
+				item["detail"] = "due " + task.DueDate.In(digest.Date.Location()).Format("Jan 2")
+			}
+			items = append(items, item)
+		}
+		sections = append(sections, map[string]interface{}{"heading": heading, "items": items})
+	}
+	addActivity := func(heading string, notifications []models.Notification) {
+		if len(notifications) == 0 {
+			return
+		}
+		items := make([]interface{}, 0, len(notifications))
+		for _, notification := range notifications {
+			item := map[string]interface{}{"title": notification.Title}
+			if notification.ResourceType == "task" && notification.ResourceID != nil {
+				item["task_id"] = notification.ResourceID.String()
+			}
+			items = append(items, item)
+		}
+		sections = append(sections, map[string]interface{}{"heading": heading, "items": items})
+	}
+
+	addTasks("Due today", digest.DueToday)
+	addTasks("Overdue", digest.Overdue)
+	addActivity("New assignments", digest.Assignments)
+	addActivity("Mentions", digest.Mentions)
+	addActivity("Other activity", digest.Activity)
+
+	return map[string]interface{}{
+		"frequency": frequency,
+		"date":      digest.Date.Format("Monday, January 2"),
+		"sections":  sections,
+	}
+}
+
+// digestEnabled reports whether the user gets a digest instead of an email per
+// notification.
+func digestEnabled(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.DigestPreference{}).
+		Where("user_id = ? AND frequency <> ?", userID, worker.DigestFrequencyOff).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// NewDigestJobHandler returns the worker handler for digest jobs. It builds the
+// digest for the period in the payload and queues it as an email. The period
+// is claimed by moving the user's PeriodEnd forward in the same transaction,
+// so a digest that was queued twice is only sent once. Empty digests are not
+// sent.
+func NewDigestJobHandler(db *gorm.DB, queue *worker.JobQueue, digestService DigestService) worker.JobHandler {
+	return func(ctx context.Context, job *worker.Job) error {
+		userID := uuid.FromStringOrNil(fmt.Sprintf("%v", job.Payload["user_id"]))
+		if userID == uuid.Nil {
+			return errors.New("digest job requires user_id")
+		}
+		periodStart, err := time.Parse(time.RFC3339, fmt.Sprintf("%v", job.Payload["period_start"]))
+		if err != nil {
+			return fmt.Errorf("invalid period_start: %w", err)
+		}
+		periodEnd, err := time.Parse(time.RFC3339, fmt.Sprintf("%v", job.Payload["period_end"]))
+		if err != nil {
+			return fmt.Errorf("invalid period_end: %w", err)
+		}
+		timezone, _ := job.Payload["timezone"].(string)
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		frequency, _ := job.Payload["frequency"].(string)
+
+		var email string
+		err = db.WithContext(ctx).Raw("SELECT email FROM users WHERE id = ? AND deleted_at IS NULL", userID).Scan(&email).Error
+		if err != nil {
+			return fmt.Errorf("failed to load user: %w", err)
+		}
+		if email == "" {
+			return nil
+		}
+
+		digest, err := digestService.BuildDigest(db.WithContext(ctx), userID, periodStart, periodEnd, loc)
+		if err != nil {
+			return err
+		}
+
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.DigestPreference{}).
+				Where("user_id = ? AND frequency <> ? AND period_end < ?", userID, worker.DigestFrequencyOff, periodEnd).
+				Updates(map[string]interface{}{"period_end": periodEnd, "updated_at": time.Now()})
+			if result.Error != nil {
+				return fmt.Errorf("failed to advance digest period: %w", result.Error)
+			}
+			if result.RowsAffected == 0 || digest.Empty() {
+				return nil
+			}
+			return EnqueueEmail(queue, userID, email, EmailTemplateDigest, digestEmailData(digest, frequency))
+		})
+	}
+}
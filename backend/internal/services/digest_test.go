@@ -0,0 +1,179 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/mailer"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/worker"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupDigestTestDB(t *testing.T) *gorm.DB {
+	db := setupNotificationTestDB(t)
+	createTestTables(t, db, "tasks")
+	return db
+}
+
+func TestDigestService_SetPreference(t *testing.T) {
+	db := setupDigestTestDB(t)
+	digests := services.NewDigestService()
+	userID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec("INSERT INTO users (id, username, email) VALUES (?, 'alice', 'alice@example.com')", userID).Error)
+
+	pref, err := digests.GetPreference(db, userID)
+	require.NoError(t, err)
+	assert.Equal(t, worker.DigestFrequencyOff, pref.Frequency)
+	assert.Equal(t, 8, pref.Hour)
+
+	for _, invalid := range []models.DigestPreference{
+		{UserID: userID, Frequency: "hourly", Timezone: "UTC"},
+		{UserID: userID, Frequency: worker.DigestFrequencyDaily, Timezone: "Mars/Olympus_Mons"},
+		{UserID: userID, Frequency: worker.DigestFrequencyDaily, Timezone: "UTC", Hour: 24},
+		{UserID: userID, Frequency: worker.DigestFrequencyWeekly, Timezone: "UTC", Weekday: 7},
+	} {
+		_, err := digests.SetPreference(db, invalid)
+		assert.ErrorIs(t, err, services.ErrInvalidDigestPreference)
+	}
+
+	pref, err = digests.SetPreference(db, models.DigestPreference{UserID: userID, Frequency: worker.DigestFrequencyWeekly, Timezone: "Europe/Berlin", Hour: 7, Weekday: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", pref.Timezone)
+	assert.WithinDuration(t, time.Now(), pref.PeriodEnd, time.Minute, "the first period starts when the digest is turned on")
+
+	schedules, err := digests.ListSchedules(db)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, userID.String(), schedules[0].UserID)
+	assert.Equal(t, time.Friday, schedules[0].Weekday)
+
+	_, err = digests.SetPreference(db, models.DigestPreference{UserID: userID, Frequency: worker.DigestFrequencyOff, Timezone: "UTC"})
+	require.NoError(t, err)
+	schedules, err = digests.ListSchedules(db)
+	require.NoError(t, err)
+	assert.Empty(t, schedules)
+}
+
+func TestDigestJobHandler_SendsDigestEmail(t *testing.T) {
+	db := setupDigestTestDB(t)
+	digests := services.NewDigestService()
+	mr := miniredis.RunT(t)
+	queue := worker.NewJobQueue(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	userID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec("INSERT INTO users (id, username, email) VALUES (?, 'alice', 'alice@example.com')", userID).Error)
+	_, err := digests.SetPreference(db, models.DigestPreference{UserID: userID, Frequency: worker.DigestFrequencyDaily, Timezone: "Europe/Berlin", Hour: 7})
+	require.NoError(t, err)
+
+	// The digest for 07:00 on Wednesday 4 March in Berlin covers the day before.
+	periodStart := time.Date(2026, 3, 3, 6, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Model(&models.DigestPreference{}).Where("user_id = ?", userID).Update("period_end", periodStart).Error)
+
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	otherID := uuid.Must(uuid.NewV4())
+	dueToday := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: userID, Title: "Send invoices", Status: "pending", DueDate: at(4, 22)}
+	for _, task := range []models.Task{
+		dueToday,
+		{ID: uuid.Must(uuid.NewV4()), UserID: userID, Title: "File taxes", Status: "in_progress", DueDate: at(1, 12)},
+		{ID: uuid.Must(uuid.NewV4()), UserID: userID, Title: "Renew domain", Status: "done", DueDate: at(1, 12)},
+		{ID: uuid.Must(uuid.NewV4()), UserID: userID, Title: "Plan offsite", Status: "pending", DueDate: at(6, 9)},
+		{ID: uuid.Must(uuid.NewV4()), UserID: uuid.Must(uuid.NewV4()), Title: "Someone else's", Status: "pending", DueDate: at(4, 9)},
+		{ID: uuid.Must(uuid.NewV4()), UserID: uuid.Must(uuid.NewV4()), AssigneeID: &userID, Title: "Review contract", Status: "pending", DueDate: at(2, 12)},
+		{ID: uuid.Must(uuid.NewV4()), UserID: userID, AssigneeID: &otherID, Title: "Delegated", Status: "pending", DueDate: at(2, 12)},
+	} {
+		require.NoError(t, db.Create(&task).Error)
+	}
+
+	for _, notification := range []models.Notification{
+		{Type: models.NotificationTypeAssigned, Title: `You were assigned "Review budget"`, CreatedAt: *at(3, 9)},
+		{Type: models.NotificationTypeMentioned, Title: "Bob mentioned you", CreatedAt: *at(3, 10)},
+		{Type: models.TaskEventStatusChanged, Title: `Task "Ship release" is now done`, ResourceType: "task", ResourceID: &dueToday.ID, CreatedAt: *at(3, 11)},
+		{Type: models.TaskEventUpdated, Title: "Before the period", CreatedAt: *at(2, 11)},
+	} {
+		notification.ID = uuid.Must(uuid.NewV4())
+		notification.UserID = userID
+		require.NoError(t, db.Create(&notification).Error)
+	}
+
+	handler := services.NewDigestJobHandler(db, queue, digests)
+	job := &worker.Job{Payload: map[string]interface{}{
+		"user_id":      userID.String(),
+		"frequency":    worker.DigestFrequencyDaily,
+		"timezone":     "Europe/Berlin",
+		"period_start": periodStart.Format(time.RFC3339),
+		"period_end":   periodEnd.Format(time.RFC3339),
+	}}
+	require.NoError(t, handler(context.Background(), job))
+	require.NoError(t, handler(context.Background(), job), "a duplicate job is a no-op")
+
+	queued, err := mr.List("default")
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	var emailJob worker.Job
+	require.NoError(t, json.Unmarshal([]byte(queued[0]), &emailJob))
+	assert.Equal(t, worker.JobTypeEmailNotification, emailJob.Type)
+
+	pref, err := digests.GetPreference(db, userID)
+	require.NoError(t, err)
+	assert.True(t, pref.PeriodEnd.Equal(periodEnd))
+
+	dir := t.TempDir()
+	renderer, err := mailer.NewRenderer()
+	require.NoError(t, err)
+	emailHandler := services.NewEmailJobHandler(db, mailer.NewFileSender(dir), renderer, services.EmailOptions{
+		From:   "no-reply@example.com",
+		AppURL: "https://app.example.com",
+	})
+	require.NoError(t, emailHandler(context.Background(), &emailJob))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	assert.Equal(t, "Your daily digest for Wednesday, March 4", msg.Header.Get("Subject"))
+
+	text := strings.ReplaceAll(decodeEmailParts(t, msg)["text/plain"], "\r\n", "\n")
+	assert.Contains(t, text, "Due today\n- Send invoices (due Mar 4)\n  https://app.example.com/tasks/"+dueToday.ID.String())
+	assert.Contains(t, text, "Overdue\n- File taxes (due Mar 1)\n")
+	assert.Contains(t, text, "- Review contract (due Mar 2)", "tasks assigned to the user are included")
+	assert.Contains(t, text, "New assignments\n- You were assigned \"Review budget\"")
+	assert.Contains(t, text, "Mentions\n- Bob mentioned you")
+	assert.Contains(t, text, "Other activity\n- Task \"Ship release\" is now done")
+	for _, excluded := range []string{"Renew domain", "Plan offsite", "Someone else's", "Delegated", "Before the period"} {
+		assert.NotContains(t, text, excluded)
+	}
+
+	// With the digest on, notifications are no longer emailed one by one.
+	notifier := services.NewEmailNotifier(queue)
+	mr.Del("default")
+	require.NoError(t, notifier.Notify(db, services.NotificationRecipient{UserID: userID, Email: "alice@example.com"}, models.Notification{Type: models.TaskEventUpdated, Title: "Updated"}))
+	queued, err = mr.List("default")
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	require.NoError(t, json.Unmarshal([]byte(queued[0]), &emailJob))
+	require.NoError(t, emailHandler(context.Background(), &emailJob))
+	files, err = filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
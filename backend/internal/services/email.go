@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/mailer"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Email templates. Transactional templates are always sent and carry no
+// unsubscribe link.
+const (
+	EmailTemplateNotification  = "notification"
+	EmailTemplateAssignment    = "assignment"
+	EmailTemplateMention       = "mention"
+	EmailTemplateReminder      = "reminder"
+	EmailTemplatePasswordReset = "password_reset"
+	EmailTemplateVerification  = "verification"
+)
+
+var transactionalEmailTemplates = map[string]bool{
+	EmailTemplatePasswordReset: true,
+	EmailTemplateVerification:  true,
+}
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+type EmailOptions struct {
+	From string
+	// AppURL is the web app's base URL, used for links to tasks.
+	AppURL string
+	// APIURL is the API's public base URL, used for unsubscribe links.
+	APIURL string
+	// UnsubscribeSecret signs unsubscribe links. Without it no links are added.
+	UnsubscribeSecret string
+}
+
+// EnqueueEmail queues an email job. data holds the template's values.
+func EnqueueEmail(queue *worker.JobQueue, userID uuid.UUID, email, template string, data map[string]interface{}) error {
+	if queue == nil {
+		return errors.New("job queue unavailable")
+	}
+
+	payload := map[string]interface{}{
+		"email":    email,
+		"template": template,
+		"data":     data,
+	}
+	if userID != uuid.Nil {
+		payload["user_id"] = userID.String()
+	}
+	return queue.Enqueue(notificationQueue, worker.JobTypeEmailNotification, payload)
+}
+
+// NewEmailJobHandler returns the worker handler for email jobs. The payload
+// carries email, template, an optional subject and user_id, and data for the
+// template. Non-transactional emails are skipped when the user has turned email
+// off for that kind of message, and include a one-click unsubscribe link.
+// Notification emails are also skipped for users who get a digest instead.
+func NewEmailJobHandler(db *gorm.DB, sender mailer.Sender, renderer *mailer.Renderer, options EmailOptions) worker.JobHandler {
+	appURL := strings.TrimSuffix(options.AppURL, "/")
+	apiURL := strings.TrimSuffix(options.APIURL, "/")
+
+	return func(ctx context.Context, job *worker.Job) error {
+		to, _ := job.Payload["email"].(string)
+		template, _ := job.Payload["template"].(string)
+		if to == "" || template == "" {
+			return errors.New("email job requires email and template")
+		}
+		if !renderer.Has(template) {
+			return fmt.Errorf("%w: %s", mailer.ErrUnknownTemplate, template)
+		}
+
+		data, _ := job.Payload["data"].(map[string]interface{})
+		if data == nil {
+			data = map[string]interface{}{}
+		}
+		if _, ok := data["url"]; !ok && appURL != "" {
+			if resourceType, _ := data["resource_type"].(string); resourceType == "task" {
+				if resourceID, _ := data["resource_id"].(string); resourceID != "" {
+					data["url"] = appURL + "/tasks/" + url.PathEscape(resourceID)
+				}
+			}
+		}
+
+		userID := uuid.FromStringOrNil(fmt.Sprintf("%v", job.Payload["user_id"]))
+		scope := emailPreferenceScope(template, data)
+
+		subject, _ := job.Payload["subject"].(string)
+		templateData := mailer.TemplateData{Subject: subject, AppURL: appURL, Data: data}
+		headers := map[string]string{}
+		if !transactionalEmailTemplates[template] && userID != uuid.Nil {
+			var pref models.NotificationPreference
+			err := db.WithContext(ctx).Where("user_id = ? AND event_type = ?", userID, scope).Limit(1).Find(&pref).Error
+			if err != nil {
+				return fmt.Errorf("failed to load notification preference: %w", err)
+			}
+			if pref.UserID != uuid.Nil && !pref.EmailEnabled {
+				return nil
+			}
+			if template == EmailTemplateNotification {
+				digest, err := digestEnabled(db.WithContext(ctx), userID)
+				if err != nil {
+					return fmt.Errorf("failed to load digest preference: %w", err)
+				}
+				if digest {
+					return nil
+				}
+			}
+
+			if options.UnsubscribeSecret != "" && apiURL != "" {
+				token := SignUnsubscribeToken(options.UnsubscribeSecret, userID, scope)
+				templateData.UnsubscribeURL = apiURL + "/api/v1/email/unsubscribe?token=" + url.QueryEscape(token)
+				headers["List-Unsubscribe"] = "<" + templateData.UnsubscribeURL + ">"
+				headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+			}
+		}
+
+		subject, text, html, err := renderer.Render(template, templateData)
+		if err != nil {
+			return fmt.Errorf("failed to render %s email: %w", template, err)
+		}
+
+		return sender.Send(ctx, &mailer.Message{
+			From:    options.From,
+			To:      to,
+			Subject: subject,
+			Text:    text,
+			HTML:    html,
+			Headers: headers,
+		})
+	}
+}
+
+// emailPreferenceScope is the notification preference event type that
+// governs an email: the notification's type, or else the template name.
+func emailPreferenceScope(template string, data map[string]interface{}) string {
+	if template == EmailTemplateNotification {
+		if eventType, _ := data["type"].(string); eventType != "" {
+			return eventType
+		}
+	}
+	return template
+}
+
+// SignUnsubscribeToken creates a token that turns off email for one
+// notification event type. Tokens do not expire, so links in old emails keep
+// working.
+func SignUnsubscribeToken(secret string, userID uuid.UUID, scope string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID.String() + ":" + scope))
+	return payload + "." + unsubscribeSignature(secret, payload)
+}
+
+// ParseUnsubscribeToken verifies a token and returns the user and scope.
+func ParseUnsubscribeToken(secret, token string) (uuid.UUID, string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(unsubscribeSignature(secret, payload))) {
+		return uuid.Nil, "", ErrInvalidUnsubscribeToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidUnsubscribeToken
+	}
+	id, scope, ok := strings.Cut(string(raw), ":")
+	userID, err := uuid.FromString(id)
+	if !ok || err != nil || scope == "" {
+		return uuid.Nil, "", ErrInvalidUnsubscribeToken
+	}
+	return userID, scope, nil
+}
+
+func unsubscribeSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte("unsubscribe:"+secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// UnsubscribeEmail turns email off for the scope, leaving in-app
+// notifications as they were.
+func UnsubscribeEmail(db *gorm.DB, userID uuid.UUID, scope string) error {
+	now := time.Now()
+	pref := models.NotificationPreference{
+		UserID:       userID,
+		EventType:    scope,
+		EmailEnabled: false,
+		InAppEnabled: true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email_enabled", "updated_at"}),
+	}).Create(&pref).Error
+}
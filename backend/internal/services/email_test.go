@@ -0,0 +1,255 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"task-manager/backend/internal/mailer"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/worker"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// mailbox accepts every recipient and keeps the raw messages it receives, so
+// the inbound SMTP server can stand in for a real mail server.
+type mailbox struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (m *mailbox) GetAddress(db *gorm.DB, userID uuid.UUID) (string, error) { return "", nil }
+
+func (m *mailbox) RegenerateAddress(db *gorm.DB, userID uuid.UUID) (string, error) {
+	return "", nil
+}
+
+func (m *mailbox) ResolveRecipient(db *gorm.DB, address string) (uuid.UUID, error) {
+	return uuid.Must(uuid.NewV4()), nil
+}
+
+func (m *mailbox) Ingest(db *gorm.DB, raw io.Reader, recipients []string) (*models.Task, error) {
+	data, err := io.ReadAll(raw)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.messages = append(m.messages, data)
+	m.mu.Unlock()
+	return &models.Task{}, nil
+}
+
+func (m *mailbox) received() [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]byte(nil), m.messages...)
+}
+
+func startTestSMTPServer(t *testing.T) (*mailbox, string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	box := &mailbox{}
+	server := services.NewInboundSMTPServer(nil, box, services.InboundSMTPOptions{})
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return box, addr.IP.String(), addr.Port
+}
+
+func TestEmailJobHandler_SendsNotificationOverSMTP(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	box, host, port := startTestSMTPServer(t)
+
+	renderer, err := mailer.NewRenderer()
+	require.NoError(t, err)
+	sender := mailer.NewSMTPSender(mailer.SMTPOptions{Host: host, Port: port, TLSMode: mailer.TLSModeNone})
+	handler := services.NewEmailJobHandler(db, sender, renderer, services.EmailOptions{
+		From:              "Task Manager <no-reply@example.com>",
+		AppURL:            "https://app.example.com",
+		APIURL:            "https://api.example.com",
+		UnsubscribeSecret: "secret",
+	})
+
+	// Queue the job the way notifications do, so the handler sees the payload
+	// exactly as it comes back out of Redis.
+	mr := miniredis.RunT(t)
+	queue := worker.NewJobQueue(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	notifier := services.NewEmailNotifier(queue)
+
+	userID, taskID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	notification := models.Notification{
+		Type:         models.TaskEventStatusChanged,
+		Title:        `Task "Ship release" is now done`,
+		Body:         "Tagged v2.0 <finally>",
+		ResourceType: "task",
+		ResourceID:   &taskID,
+	}
+	recipient := services.NotificationRecipient{UserID: userID, Email: "bob@example.com"}
+
+	nextJob := func() *worker.Job {
+		raw, err := mr.Lpop("default")
+		require.NoError(t, err)
+		var job worker.Job
+		require.NoError(t, json.Unmarshal([]byte(raw), &job))
+		return &job
+	}
+
+	require.NoError(t, notifier.Notify(db, recipient, notification))
+	require.NoError(t, handler(context.Background(), nextJob()))
+
+	messages := box.received()
+	require.Len(t, messages, 1)
+	msg, err := mail.ReadMessage(strings.NewReader(string(messages[0])))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, notification.Title, subject)
+	assert.Equal(t, "bob@example.com", mustAddress(t, msg.Header.Get("To")))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+
+	unsubscribe := strings.Trim(msg.Header.Get("List-Unsubscribe"), "<>")
+	require.True(t, strings.HasPrefix(unsubscribe, "https://api.example.com/api/v1/email/unsubscribe?token="))
+
+	parts := decodeEmailParts(t, msg)
+	assert.Contains(t, parts["text/plain"], "Tagged v2.0 <finally>")
+	assert.Contains(t, parts["text/plain"], "https://app.example.com/tasks/"+taskID.String())
+	assert.Contains(t, parts["text/plain"], "Unsubscribe: "+unsubscribe)
+	assert.Contains(t, parts["text/html"], "Tagged v2.0 &lt;finally&gt;", "the HTML part is escaped")
+
+	parsed, err := url.Parse(unsubscribe)
+	require.NoError(t, err)
+	tokenUser, scope, err := services.ParseUnsubscribeToken("secret", parsed.Query().Get("token"))
+	require.NoError(t, err)
+	assert.Equal(t, userID, tokenUser)
+	assert.Equal(t, models.TaskEventStatusChanged, scope)
+
+	// After unsubscribing, queued emails of that type are dropped but in-app
+	// notifications stay on.
+	require.NoError(t, services.UnsubscribeEmail(db, tokenUser, scope))
+	require.NoError(t, notifier.Notify(db, recipient, notification))
+	require.NoError(t, handler(context.Background(), nextJob()))
+	assert.Len(t, box.received(), 1)
+
+	var pref models.NotificationPreference
+	require.NoError(t, db.First(&pref, "user_id = ?", userID).Error)
+	assert.False(t, pref.EmailEnabled)
+	assert.True(t, pref.InAppEnabled)
+}
+
+func TestEmailJobHandler_FileSinkAndTransactionalTemplates(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	dir := t.TempDir()
+
+	renderer, err := mailer.NewRenderer()
+	require.NoError(t, err)
+	handler := services.NewEmailJobHandler(db, mailer.NewFileSender(dir), renderer, services.EmailOptions{
+		From:              "no-reply@example.com",
+		APIURL:            "https://api.example.com",
+		UnsubscribeSecret: "secret",
+	})
+
+	userID := uuid.Must(uuid.NewV4())
+	// Turning off email for a template must not block password resets.
+	require.NoError(t, services.UnsubscribeEmail(db, userID, services.EmailTemplatePasswordReset))
+
+	job := &worker.Job{Payload: map[string]interface{}{
+		"user_id":  userID.String(),
+		"email":    "alice@example.com",
+		"template": services.EmailTemplatePasswordReset,
+		"data": map[string]interface{}{
+			"url":        "https://app.example.com/reset?token=abc",
+			"expires_in": "1 hour",
+		},
+	}}
+	require.NoError(t, handler(context.Background(), job))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	assert.Equal(t, "Reset your password", msg.Header.Get("Subject"))
+	assert.Empty(t, msg.Header.Get("List-Unsubscribe"), "transactional email has no unsubscribe link")
+	parts := decodeEmailParts(t, msg)
+	assert.Contains(t, parts["text/plain"], "https://app.example.com/reset?token=abc")
+	assert.Contains(t, parts["text/plain"], "expires in 1 hour")
+	assert.NotContains(t, parts["text/html"], "Unsubscribe")
+
+	for _, template := range []string{services.EmailTemplateAssignment, services.EmailTemplateMention, services.EmailTemplateReminder, services.EmailTemplateVerification} {
+		require.NoError(t, handler(context.Background(), &worker.Job{Payload: map[string]interface{}{
+			"email":    "alice@example.com",
+			"template": template,
+			"data":     map[string]interface{}{"task_title": "Write report", "url": "https://app.example.com/x"},
+		}}), template)
+	}
+
+	err = handler(context.Background(), &worker.Job{Payload: map[string]interface{}{"email": "alice@example.com", "template": "nope"}})
+	assert.ErrorIs(t, err, mailer.ErrUnknownTemplate)
+}
+
+func TestParseUnsubscribeToken_RejectsTampering(t *testing.T) {
+	userID := uuid.Must(uuid.NewV4())
+	token := services.SignUnsubscribeToken("secret", userID, "task.updated")
+
+	_, _, err := services.ParseUnsubscribeToken("other-secret", token)
+	assert.ErrorIs(t, err, services.ErrInvalidUnsubscribeToken)
+
+	forged := services.SignUnsubscribeToken("secret", uuid.Must(uuid.NewV4()), "task.updated")
+	payload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(token, ".")
+	_, _, err = services.ParseUnsubscribeToken("secret", payload+"."+signature)
+	assert.ErrorIs(t, err, services.ErrInvalidUnsubscribeToken)
+}
+
+// decodeEmailParts returns the decoded body of each part of a
+// multipart/alternative message, keyed by media type.
+func decodeEmailParts(t *testing.T, msg *mail.Message) map[string]string {
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err)
+
+		partType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts[partType] = string(body)
+	}
+}
+
+func mustAddress(t *testing.T, header string) string {
+	address, err := mail.ParseAddress(header)
+	require.NoError(t, err)
+	return address.Address
+}
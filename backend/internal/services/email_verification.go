@@ -0,0 +1,170 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultEmailVerificationTTL    = 48 * time.Hour
+	DefaultVerificationResendDelay = 2 * time.Minute
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrVerificationThrottled    = errors.New("a verification email was sent recently")
+)
+
+type EmailVerificationService interface {
+	// IssueToken records that a verification email is going out to the user
+	// and returns the signed token for its link. It fails with
+	// ErrVerificationThrottled while an earlier email is within the resend
+	// delay.
+	IssueToken(db *gorm.DB, user *models.User) (string, error)
+	// RequestResend issues a new token for the active, unverified user with
+	// the email. It returns a nil user and no error when there is no such
+	// user.
+	RequestResend(db *gorm.DB, email string) (*models.User, string, error)
+	// Verify marks the token's address as verified. Tokens stop working once
+	// the user's email changes.
+	Verify(db *gorm.DB, token string) (*models.User, error)
+	TTL() time.Duration
+	ResendDelay() time.Duration
+}
+
+type EmailVerificationServiceImpl struct {
+	secret      string
+	ttl         time.Duration
+	resendDelay time.Duration
+}
+
+func NewEmailVerificationService(secret string, ttl, resendDelay time.Duration) *EmailVerificationServiceImpl {
+	if ttl <= 0 {
+		ttl = DefaultEmailVerificationTTL
+	}
+	if resendDelay < 0 {
+		resendDelay = DefaultVerificationResendDelay
+	}
+	return &EmailVerificationServiceImpl{secret: secret, ttl: ttl, resendDelay: resendDelay}
+}
+
+func (s *EmailVerificationServiceImpl) TTL() time.Duration {
+	return s.ttl
+}
+
+func (s *EmailVerificationServiceImpl) ResendDelay() time.Duration {
+	return s.resendDelay
+}
+
+func (s *EmailVerificationServiceImpl) IssueToken(db *gorm.DB, user *models.User) (string, error) {
+	now := time.Now()
+	// The check and the update are one statement so concurrent resends
+	// cannot both get through.
+	result := db.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", user.ID).
+		Where("(verification_sent_at IS NULL OR verification_sent_at <= ?)", now.Add(-s.resendDelay)).
+		Update("verification_sent_at", now)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to record verification email: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", ErrVerificationThrottled
+	}
+	user.VerificationSentAt = &now
+	return SignVerificationToken(s.secret, user.ID, user.Email, now.Add(s.ttl)), nil
+}
+
+func (s *EmailVerificationServiceImpl) RequestResend(db *gorm.DB, email string) (*models.User, string, error) {
+	var user models.User
+	err := db.Where("email = ? AND is_active = ? AND email_verified_at IS NULL", strings.TrimSpace(email), true).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	token, err := s.IssueToken(db, &user)
+	if err != nil {
+		return nil, "", err
+	}
+	return &user, token, nil
+}
+
+func (s *EmailVerificationServiceImpl) Verify(db *gorm.DB, token string) (*models.User, error) {
+	userID, email, err := ParseVerificationToken(s.secret, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := db.Where("id = ? AND email = ?", userID, email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if user.EmailVerifiedAt != nil {
+		return &user, nil
+	}
+
+	now := time.Now()
+	if err := db.Model(&models.User{}).Where("id = ?", user.ID).Update("email_verified_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	user.EmailVerifiedAt = &now
+	return &user, nil
+}
+
+// SignVerificationToken creates a token confirming that userID owns email. It
+// is valid until expiresAt.
+func SignVerificationToken(secret string, userID uuid.UUID, email string, expiresAt time.Time) string {
+	raw := userID.String() + ":" + strconv.FormatInt(expiresAt.Unix(), 10) + ":" + email
+	payload := base64.RawURLEncoding.EncodeToString([]byte(raw))
+	return payload + "." + verificationSignature(secret, payload)
+}
+
+// ParseVerificationToken checks a token's signature and expiry and returns the
+// user and address it confirms.
+func ParseVerificationToken(secret, token string, now time.Time) (uuid.UUID, string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(verificationSignature(secret, payload))) {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+	userID, err := uuid.FromString(parts[0])
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !now.Before(time.Unix(expiresAt, 0)) {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+	return userID, parts[2], nil
+}
+
+func verificationSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte("email-verification:"+secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
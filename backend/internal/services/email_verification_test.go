@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func setupEmailVerificationTestDB(t *testing.T) (*gorm.DB, uuid.UUID) {
+	db := openTestDB(t, "users")
+	return db, createTestUser(t, db, "alice")
+}
+
+func TestEmailVerificationService_VerifiesAndThrottles(t *testing.T) {
+	db, userID := setupEmailVerificationTestDB(t)
+	verification := services.NewEmailVerificationService("secret", time.Hour, time.Minute)
+
+	user, _, err := verification.RequestResend(db, "nobody@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, user)
+
+	user, token, err := verification.RequestResend(db, "alice@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, userID, user.ID)
+
+	_, _, err = verification.RequestResend(db, "alice@example.com")
+	assert.ErrorIs(t, err, services.ErrVerificationThrottled)
+
+	_, err = verification.Verify(db, token+"x")
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken)
+
+	verified, err := verification.Verify(db, token)
+	require.NoError(t, err)
+	assert.True(t, verified.IsEmailVerified())
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, "id = ?", userID).Error)
+	assert.NotNil(t, stored.EmailVerifiedAt)
+
+	user, _, err = verification.RequestResend(db, "alice@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, user, "verified users get no more emails")
+}
+
+func TestEmailVerificationService_RejectsStaleTokens(t *testing.T) {
+	db, userID := setupEmailVerificationTestDB(t)
+	verification := services.NewEmailVerificationService("secret", time.Hour, 0)
+
+	expired := services.SignVerificationToken("secret", userID, "alice@example.com", time.Now().Add(-time.Second))
+	_, err := verification.Verify(db, expired)
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken)
+
+	otherSecret := services.SignVerificationToken("other", userID, "alice@example.com", time.Now().Add(time.Hour))
+	_, err = verification.Verify(db, otherSecret)
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken)
+
+	oldAddress := services.SignVerificationToken("secret", userID, "old@example.com", time.Now().Add(time.Hour))
+	_, err = verification.Verify(db, oldAddress)
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken, "links stop working when the email changes")
+}
+
+func TestAuthService_LoginRequiresVerifiedEmail(t *testing.T) {
+	db, userID := setupEmailVerificationTestDB(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("Passw0rd!"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("UPDATE users SET password = ? WHERE id = ?", string(hash), userID).Error)
+
+	_, err = services.NewAuthService(services.AuthOptions{}).LoginUser(db, "alice@example.com", "Passw0rd!")
+	assert.NoError(t, err)
+
+	strict := services.NewAuthService(services.AuthOptions{RequireEmailVerification: true})
+	_, err = strict.LoginUser(db, "alice@example.com", "Passw0rd!")
+	assert.ErrorIs(t, err, services.ErrEmailNotVerified)
+
+	require.NoError(t, db.Exec("UPDATE users SET email_verified_at = ? WHERE id = ?", time.Now(), userID).Error)
+	_, err = strict.LoginUser(db, "alice@example.com", "Passw0rd!")
+	assert.NoError(t, err)
+}
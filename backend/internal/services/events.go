@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// AllEvents subscribes a handler to every event published on the bus.
+const AllEvents = "*"
+
+// DomainEvent is something that happened to an aggregate. Events relayed from
+// the outbox carry the outbox message ID, so subscribers can dedupe on EventID.
+type DomainEvent interface {
+	EventName() string
+	EventID() uuid.UUID
+	AggregateType() string
+	AggregateID() uuid.UUID
+}
+
+type TaskCreated struct{ models.TaskEvent }
+type TaskUpdated struct{ models.TaskEvent }
+type TaskStatusChanged struct{ models.TaskEvent }
+type TaskDeleted struct{ models.TaskEvent }
+
+// taskDomainEvent lets task subscribers recover the shared payload whichever
+// task event they were handed.
+type taskDomainEvent interface {
+	DomainEvent
+	Task() models.TaskEvent
+}
+
+func (e TaskCreated) EventName() string       { return models.TaskEventCreated }
+func (e TaskUpdated) EventName() string       { return models.TaskEventUpdated }
+func (e TaskStatusChanged) EventName() string { return models.TaskEventStatusChanged }
+func (e TaskDeleted) EventName() string       { return models.TaskEventDeleted }
+
+func (e TaskCreated) Task() models.TaskEvent       { return e.TaskEvent }
+func (e TaskUpdated) Task() models.TaskEvent       { return e.TaskEvent }
+func (e TaskStatusChanged) Task() models.TaskEvent { return e.TaskEvent }
+func (e TaskDeleted) Task() models.TaskEvent       { return e.TaskEvent }
+
+func (e TaskCreated) EventID() uuid.UUID       { return e.TaskEvent.EventID }
+func (e TaskUpdated) EventID() uuid.UUID       { return e.TaskEvent.EventID }
+func (e TaskStatusChanged) EventID() uuid.UUID { return e.TaskEvent.EventID }
+func (e TaskDeleted) EventID() uuid.UUID       { return e.TaskEvent.EventID }
+
+func (e TaskCreated) AggregateType() string       { return models.OutboxAggregateTask }
+func (e TaskUpdated) AggregateType() string       { return models.OutboxAggregateTask }
+func (e TaskStatusChanged) AggregateType() string { return models.OutboxAggregateTask }
+func (e TaskDeleted) AggregateType() string       { return models.OutboxAggregateTask }
+
+func (e TaskCreated) AggregateID() uuid.UUID       { return e.TaskID }
+func (e TaskUpdated) AggregateID() uuid.UUID       { return e.TaskID }
+func (e TaskStatusChanged) AggregateID() uuid.UUID { return e.TaskID }
+func (e TaskDeleted) AggregateID() uuid.UUID       { return e.TaskID }
+
+// NewTaskDomainEvent wraps a task event in the typed event for its Type.
+func NewTaskDomainEvent(event models.TaskEvent) (DomainEvent, error) {
+	switch event.Type {
+	case models.TaskEventCreated:
+		return TaskCreated{event}, nil
+	case models.TaskEventUpdated:
+		return TaskUpdated{event}, nil
+	case models.TaskEventStatusChanged:
+		return TaskStatusChanged{event}, nil
+	case models.TaskEventDeleted:
+		return TaskDeleted{event}, nil
+	}
+	return nil, fmt.Errorf("unknown task event type: %q", event.Type)
+}
+
+type UserUpdated struct {
+	ID     uuid.UUID `json:"-"`
+	UserID uuid.UUID `json:"user_id"`
+	Fields []string  `json:"fields"`
+}
+
+type UserDeleted struct {
+	ID     uuid.UUID `json:"-"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (e UserUpdated) EventName() string      { return models.UserEventUpdated }
+func (e UserUpdated) EventID() uuid.UUID     { return e.ID }
+func (e UserUpdated) AggregateType() string  { return models.OutboxAggregateUser }
+func (e UserUpdated) AggregateID() uuid.UUID { return e.UserID }
+
+func (e UserDeleted) EventName() string      { return models.UserEventDeleted }
+func (e UserDeleted) EventID() uuid.UUID     { return e.ID }
+func (e UserDeleted) AggregateType() string  { return models.OutboxAggregateUser }
+func (e UserDeleted) AggregateID() uuid.UUID { return e.UserID }
+
+type RoleAssigned struct {
+	ID         uuid.UUID `json:"-"`
+	UserID     uuid.UUID `json:"user_id"`
+	RoleID     uuid.UUID `json:"role_id"`
+	AssignedBy uuid.UUID `json:"assigned_by"`
+}
+
+type RoleRevoked struct {
+	ID     uuid.UUID `json:"-"`
+	UserID uuid.UUID `json:"user_id"`
+	RoleID uuid.UUID `json:"role_id"`
+}
+
+func (e RoleAssigned) EventName() string      { return models.RoleEventAssigned }
+func (e RoleAssigned) EventID() uuid.UUID     { return e.ID }
+func (e RoleAssigned) AggregateType() string  { return models.OutboxAggregateRole }
+func (e RoleAssigned) AggregateID() uuid.UUID { return e.RoleID }
+
+func (e RoleRevoked) EventName() string      { return models.RoleEventRevoked }
+func (e RoleRevoked) EventID() uuid.UUID     { return e.ID }
+func (e RoleRevoked) AggregateType() string  { return models.OutboxAggregateRole }
+func (e RoleRevoked) AggregateID() uuid.UUID { return e.RoleID }
+
+// DecodeOutboxEvent turns an outbox message back into its typed event.
+func DecodeOutboxEvent(message models.OutboxMessage) (DomainEvent, error) {
+	var (
+		event DomainEvent
+		err   error
+	)
+
+	switch message.EventType {
+	case models.TaskEventCreated, models.TaskEventUpdated, models.TaskEventStatusChanged, models.TaskEventDeleted:
+		var task models.TaskEvent
+		if err = json.Unmarshal([]byte(message.Payload), &task); err == nil {
+			task.EventID = message.ID
+			event, err = NewTaskDomainEvent(task)
+		}
+	case models.UserEventUpdated:
+		e := UserUpdated{ID: message.ID}
+		err = json.Unmarshal([]byte(message.Payload), &e)
+		event = e
+	case models.UserEventDeleted:
+		e := UserDeleted{ID: message.ID}
+		err = json.Unmarshal([]byte(message.Payload), &e)
+		event = e
+	case models.RoleEventAssigned:
+		e := RoleAssigned{ID: message.ID}
+		err = json.Unmarshal([]byte(message.Payload), &e)
+		event = e
+	case models.RoleEventRevoked:
+		e := RoleRevoked{ID: message.ID}
+		err = json.Unmarshal([]byte(message.Payload), &e)
+		event = e
+	default:
+		return nil, fmt.Errorf("unknown outbox event type: %q", message.EventType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", message.EventType, err)
+	}
+	return event, nil
+}
+
+// EventHandler reacts to one event. db is not a transaction: by the time an
+// event is published the change that caused it has committed.
+type EventHandler func(db *gorm.DB, event DomainEvent) error
+
+type queuedEvent struct {
+	db       *gorm.DB
+	event    DomainEvent
+	handlers []EventHandler
+}
+
+// EventBus dispatches domain events to subscribers. Synchronous subscribers run
+// before Publish returns and their errors are returned, so a relayed outbox
+// message is retried until they all succeed; they should be idempotent.
+// Asynchronous subscribers run on the bus's workers and their errors are only
+// logged.
+type EventBus struct {
+	mu     sync.RWMutex
+	sync   map[string][]EventHandler
+	async  map[string][]EventHandler
+	queue  chan queuedEvent
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewEventBus(workers, bufferSize int) *EventBus {
+	if workers <= 0 {
+		workers = 1
+	}
+	if bufferSize <= 0 {
+		bufferSize = 256
+	}
+
+	b := &EventBus{
+		sync:  make(map[string][]EventHandler),
+		async: make(map[string][]EventHandler),
+		queue: make(chan queuedEvent, bufferSize),
+	}
+
+	for i := 0; i < workers; i++ {
+		b.wg.Add(1)
+		go b.work()
+	}
+	return b
+}
+
+// Subscribe adds a synchronous handler for eventName, or AllEvents.
+func (b *EventBus) Subscribe(eventName string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync[eventName] = append(b.sync[eventName], handler)
+}
+
+// SubscribeAsync adds a handler for eventName, or AllEvents, that runs in the
+// background.
+func (b *EventBus) SubscribeAsync(eventName string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.async[eventName] = append(b.async[eventName], handler)
+}
+
+// On subscribes a handler for one event type, e.g.
+//
+//	services.On(bus, func(db *gorm.DB, e services.UserDeleted) error { ... })
+func On[E DomainEvent](b *EventBus, handler func(db *gorm.DB, event E) error) {
+	var zero E
+	b.Subscribe(zero.EventName(), typedHandler(handler))
+}
+
+// OnAsync is On for an asynchronous subscriber.
+func OnAsync[E DomainEvent](b *EventBus, handler func(db *gorm.DB, event E) error) {
+	var zero E
+	b.SubscribeAsync(zero.EventName(), typedHandler(handler))
+}
+
+func typedHandler[E DomainEvent](handler func(db *gorm.DB, event E) error) EventHandler {
+	return func(db *gorm.DB, event DomainEvent) error {
+		typed, ok := event.(E)
+		if !ok {
+			return fmt.Errorf("unexpected %T for %s", event, event.EventName())
+		}
+		return handler(db, typed)
+	}
+}
+
+// SubscribeTaskEvents hands every task event to publisher, so the existing
+// TaskEventPublisher implementations can sit on the bus unchanged.
+func (b *EventBus) SubscribeTaskEvents(publisher TaskEventPublisher, async bool) {
+	handler := func(db *gorm.DB, event DomainEvent) error {
+		task, ok := event.(taskDomainEvent)
+		if !ok {
+			return nil
+		}
+		return publisher.PublishTaskEvent(db, task.Task())
+	}
+
+	for _, name := range []string{models.TaskEventCreated, models.TaskEventUpdated, models.TaskEventStatusChanged, models.TaskEventDeleted} {
+		if async {
+			b.SubscribeAsync(name, handler)
+		} else {
+			b.Subscribe(name, handler)
+		}
+	}
+}
+
+// Publish runs the synchronous subscribers in order and queues the event for
+// the asynchronous ones. A full queue blocks until there is room or ctx ends.
+func (b *EventBus) Publish(ctx context.Context, db *gorm.DB, event DomainEvent) error {
+	b.mu.RLock()
+	syncHandlers := append(append([]EventHandler{}, b.sync[event.EventName()]...), b.sync[AllEvents]...)
+	asyncHandlers := append(append([]EventHandler{}, b.async[event.EventName()]...), b.async[AllEvents]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range syncHandlers {
+		if err := handler(db, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// A failed publish is retried, so async subscribers only see the event
+	// once every synchronous one has accepted it.
+	if len(errs) > 0 || len(asyncHandlers) == 0 {
+		return errors.Join(errs...)
+	}
+
+	// Hold the read lock while queueing so Close cannot close the channel
+	// under a pending send.
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		log.Printf("⚠️  Event bus closed, dropping async delivery of %s %s", event.EventName(), event.EventID())
+		return nil
+	}
+
+	// Async work outlives the caller, so it must not inherit its context.
+	queued := queuedEvent{db: db.WithContext(context.Background()), event: event, handlers: asyncHandlers}
+	select {
+	case b.queue <- queued:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting async work and waits for queued events to finish.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.queue)
+	b.wg.Wait()
+}
+
+func (b *EventBus) work() {
+	defer b.wg.Done()
+
+	for queued := range b.queue {
+		for _, handler := range queued.handlers {
+			b.runAsync(handler, queued)
+		}
+	}
+}
+
+func (b *EventBus) runAsync(handler EventHandler, queued queuedEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️  Event subscriber panicked on %s %s: %v", queued.event.EventName(), queued.event.EventID(), r)
+		}
+	}()
+
+	if err := handler(queued.db, queued.event); err != nil {
+		log.Printf("⚠️  Event subscriber failed on %s %s: %v", queued.event.EventName(), queued.event.EventID(), err)
+	}
+}
+
+// NewEventBusOutboxHandler returns the relay handler that decodes messages and
+// publishes them on bus.
+func NewEventBusOutboxHandler(bus *EventBus) OutboxHandler {
+	return func(db *gorm.DB, message models.OutboxMessage) error {
+		event, err := DecodeOutboxEvent(message)
+		if err != nil {
+			return err
+		}
+		return bus.Publish(db.Statement.Context, db, event)
+	}
+}
+
+// NewAuditLogSubscriber records every domain event in audit_logs. The entry is
+// attributed to the actor when the event names one, otherwise to the user it
+// concerns. Task events are filed under the task's workspace; user and role
+// changes are not in any.
+func NewAuditLogSubscriber() EventHandler {
+	return func(db *gorm.DB, event DomainEvent) error {
+		var userID uuid.UUID
+		var workspaceID *uuid.UUID
+		switch e := event.(type) {
+		case taskDomainEvent:
+			userID = e.Task().ActorID
+			if userID == uuid.Nil {
+				userID = e.Task().OwnerID
+			}
+			workspaceID = e.Task().WorkspaceID
+		case UserUpdated:
+			userID = e.UserID
+		case UserDeleted:
+			userID = e.UserID
+		case RoleAssigned:
+			userID = e.AssignedBy
+			if userID == uuid.Nil {
+				userID = e.UserID
+			}
+		case RoleRevoked:
+			userID = e.UserID
+		}
+
+		detail, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit context: %w", err)
+		}
+
+		return db.Create(&models.AuditLog{
+			ID:          uuid.Must(uuid.NewV4()),
+			UserID:      userID,
+			Action:      event.EventName(),
+			Resource:    event.AggregateType(),
+			ResourceID:  event.AggregateID(),
+			Decision:    models.AuditDecisionRecorded,
+			Reason:      "event " + event.EventID().String(),
+			Context:     string(detail),
+			Timestamp:   time.Now(),
+			WorkspaceID: workspaceID,
+		}).Error
+	}
+}
@@ -0,0 +1,191 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/cache"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestEventBus_SyncAndAsyncSubscribers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	bus := services.NewEventBus(1, 0)
+
+	var deleted []uuid.UUID
+	services.On(bus, func(db *gorm.DB, event services.UserDeleted) error {
+		deleted = append(deleted, event.UserID)
+		return nil
+	})
+
+	failing := errors.New("subscriber down")
+	var failNext bool
+	bus.Subscribe(services.AllEvents, func(db *gorm.DB, event services.DomainEvent) error {
+		if failNext {
+			return failing
+		}
+		return nil
+	})
+
+	async := make(chan services.DomainEvent, 4)
+	bus.SubscribeAsync(services.AllEvents, func(db *gorm.DB, event services.DomainEvent) error {
+		async <- event
+		return nil
+	})
+
+	userID := uuid.Must(uuid.NewV4())
+	require.NoError(t, bus.Publish(context.Background(), db, services.UserDeleted{ID: uuid.Must(uuid.NewV4()), UserID: userID}))
+	assert.Equal(t, []uuid.UUID{userID}, deleted, "sync subscribers run before Publish returns")
+
+	// A failed publish will be retried, so async subscribers must not see it yet.
+	failNext = true
+	err = bus.Publish(context.Background(), db, services.RoleRevoked{ID: uuid.Must(uuid.NewV4()), UserID: userID})
+	require.ErrorIs(t, err, failing)
+
+	bus.Close()
+	close(async)
+
+	var received []string
+	for event := range async {
+		received = append(received, event.EventName())
+	}
+	assert.Equal(t, []string{models.UserEventDeleted}, received)
+}
+
+func TestDecodeOutboxEvent_RoundTripsTypedEvents(t *testing.T) {
+	db := setupOutboxTestDB(t)
+
+	taskID, workspaceID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	require.NoError(t, services.NewTaskEventOutbox().PublishTaskEvent(db, models.TaskEvent{
+		Type:        models.TaskEventStatusChanged,
+		TaskID:      taskID,
+		WorkspaceID: &workspaceID,
+		Changes:     map[string]string{"status": "done"},
+	}))
+
+	roleID, userID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	_, err := services.WriteOutbox(db, models.OutboxAggregateRole, roleID, models.RoleEventAssigned, map[string]interface{}{
+		"user_id": userID.String(),
+		"role_id": roleID.String(),
+	})
+	require.NoError(t, err)
+
+	var messages []models.OutboxMessage
+	require.NoError(t, db.Order("created_at ASC").Find(&messages).Error)
+	require.Len(t, messages, 2)
+
+	event, err := services.DecodeOutboxEvent(messages[0])
+	require.NoError(t, err)
+	changed, ok := event.(services.TaskStatusChanged)
+	require.True(t, ok, "got %T", event)
+	assert.Equal(t, taskID, changed.TaskID)
+	assert.Equal(t, messages[0].ID, changed.EventID())
+	assert.Equal(t, "done", changed.Changes["status"])
+	require.NotNil(t, changed.WorkspaceID)
+	assert.Equal(t, workspaceID, *changed.WorkspaceID)
+
+	event, err = services.DecodeOutboxEvent(messages[1])
+	require.NoError(t, err)
+	assigned, ok := event.(services.RoleAssigned)
+	require.True(t, ok, "got %T", event)
+	assert.Equal(t, userID, assigned.UserID)
+	assert.Equal(t, roleID, assigned.AggregateID())
+	assert.Equal(t, messages[1].ID, assigned.EventID())
+}
+
+func TestAuditLogSubscriber_RecordsEvents(t *testing.T) {
+	db := openTestDB(t, "audit_logs")
+
+	actorID, taskID, workspaceID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	event := services.TaskDeleted{TaskEvent: models.TaskEvent{
+		EventID:     uuid.Must(uuid.NewV4()),
+		Type:        models.TaskEventDeleted,
+		TaskID:      taskID,
+		ActorID:     actorID,
+		WorkspaceID: &workspaceID,
+	}}
+	require.NoError(t, services.NewAuditLogSubscriber()(db, event))
+
+	var entry models.AuditLog
+	require.NoError(t, db.First(&entry).Error)
+	assert.Equal(t, actorID, entry.UserID)
+	require.NotNil(t, entry.WorkspaceID, "task events are filed under the task's workspace")
+	assert.Equal(t, workspaceID, *entry.WorkspaceID)
+	assert.Equal(t, models.TaskEventDeleted, entry.Action)
+	assert.Equal(t, "task", entry.Resource)
+	assert.Equal(t, taskID, entry.ResourceID)
+	assert.Equal(t, models.AuditDecisionRecorded, entry.Decision)
+	assert.Contains(t, entry.Reason, event.EventID().String())
+}
+
+func TestCachedTaskService_InvalidatesOnEvents(t *testing.T) {
+	multiCache := cache.NewMultiLevelCache(nil)
+	cached := services.NewCachedTaskService(services.NewTaskService(), multiCache)
+
+	bus := services.NewEventBus(1, 0)
+	defer bus.Close()
+	cached.SubscribeInvalidation(bus)
+
+	taskID, ownerID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	taskKey := "task:" + taskID.String()
+	userKey := "user_tasks:" + ownerID.String()
+	for _, key := range []string{taskKey, userKey, "all_tasks", "tasks_paginated:created_at:desc:1:10"} {
+		require.NoError(t, multiCache.Set(key, "cached", time.Minute))
+	}
+
+	err := bus.Publish(context.Background(), nil, services.TaskUpdated{TaskEvent: models.TaskEvent{
+		Type:    models.TaskEventUpdated,
+		TaskID:  taskID,
+		OwnerID: ownerID,
+	}})
+	require.NoError(t, err)
+
+	for _, key := range []string{taskKey, userKey, "all_tasks", "tasks_paginated:created_at:desc:1:10"} {
+		var value string
+		assert.Error(t, multiCache.Get(key, &value), "%s should be evicted", key)
+	}
+}
+
+func TestCachedTaskService_InvalidatesOnWrite(t *testing.T) {
+	db := openTestDB(t, "tasks")
+	multiCache := cache.NewMultiLevelCache(nil)
+	cached := services.NewCachedTaskService(services.NewTaskService(), multiCache)
+
+	taskID, ownerID, newOwnerID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	taskKey := "task:" + taskID.String()
+	ownerKey, newOwnerKey := "user_tasks:"+ownerID.String(), "user_tasks:"+newOwnerID.String()
+	// No event is published, so only the write itself can evict.
+	writeEvicts := func(write func() error, keys ...string) {
+		t.Helper()
+		keys = append(keys, taskKey, "all_tasks", "tasks_paginated:created_at:desc:1:10")
+		for _, key := range keys {
+			require.NoError(t, multiCache.Set(key, "cached", time.Minute))
+		}
+		require.NoError(t, write())
+		for _, key := range keys {
+			var value string
+			assert.Error(t, multiCache.Get(key, &value), "%s should be evicted", key)
+		}
+	}
+
+	writeEvicts(func() error {
+		return cached.CreateTask(db, models.Task{ID: taskID, UserID: ownerID, Title: "Write report", Status: "todo"})
+	}, ownerKey)
+	writeEvicts(func() error {
+		return cached.UpdateTask(db, taskID, models.Task{UserID: newOwnerID})
+	}, ownerKey, newOwnerKey)
+	writeEvicts(func() error {
+		return cached.DeleteTask(db, taskID)
+	}, newOwnerKey)
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	inboundLocalPartPrefix = "task-"
+	inboundMaxTitleLength  = 255
+	inboundMaxPartDepth    = 5
+)
+
+var (
+	ErrInboundRecipientUnknown = errors.New("no inbound address matches the recipient")
+	ErrInboundSenderMismatch   = errors.New("sender does not own the inbound address")
+	ErrInboundMessageInvalid   = errors.New("malformed email message")
+	ErrInboundMessageTooLarge  = errors.New("email attachments exceed the size limit")
+)
+
+// InboundEmail is a parsed message. Body prefers the text/plain part and falls
+// back to the text of the HTML part.
+type InboundEmail struct {
+	From        string
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []InboundAttachment
+}
+
+type InboundAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type InboundEmailOptions struct {
+	// Domain is the mail domain of inbound addresses. Recipients at other
+	// domains are ignored.
+	Domain            string
+	MaxAttachments    int
+	MaxAttachmentSize int64
+}
+
+type InboundEmailService interface {
+	GetAddress(db *gorm.DB, userID uuid.UUID) (string, error)
+	RegenerateAddress(db *gorm.DB, userID uuid.UUID) (string, error)
+	ResolveRecipient(db *gorm.DB, address string) (uuid.UUID, error)
+	Ingest(db *gorm.DB, raw io.Reader, recipients []string) (*models.Task, error)
+}
+
+type InboundEmailServiceImpl struct {
+	taskService       TaskService
+	attachmentService AttachmentService
+	events            TaskEventPublisher
+	options           InboundEmailOptions
+}
+
+// NewInboundEmailService creates the email-to-task service. events receives
+// the created event inside the task's transaction and may be nil.
+func NewInboundEmailService(taskService TaskService, attachmentService AttachmentService, events TaskEventPublisher, options InboundEmailOptions) *InboundEmailServiceImpl {
+	if options.MaxAttachments <= 0 {
+		options.MaxAttachments = 10
+	}
+	if options.MaxAttachmentSize <= 0 {
+		options.MaxAttachmentSize = 10 << 20
+	}
+	options.Domain = strings.ToLower(options.Domain)
+
+	return &InboundEmailServiceImpl{
+		taskService:       taskService,
+		attachmentService: attachmentService,
+		events:            events,
+		options:           options,
+	}
+}
+
+// GetAddress returns the user's inbound address, creating one on first use.
+func (s *InboundEmailServiceImpl) GetAddress(db *gorm.DB, userID uuid.UUID) (string, error) {
+	var address models.InboundEmailAddress
+	err := db.Where("user_id = ?", userID).First(&address).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.RegenerateAddress(db, userID)
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.formatAddress(address.LocalPart), nil
+}
+
+// RegenerateAddress replaces the user's inbound address. Mail sent to the old
+// address is rejected from then on.
+func (s *InboundEmailServiceImpl) RegenerateAddress(db *gorm.DB, userID uuid.UUID) (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate inbound address: %w", err)
+	}
+
+	address := models.InboundEmailAddress{UserID: userID, LocalPart: inboundLocalPartPrefix + hex.EncodeToString(buf)}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"local_part": address.LocalPart, "updated_at": time.Now()}),
+	}).Create(&address).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to save inbound address: %w", err)
+	}
+
+	return s.formatAddress(address.LocalPart), nil
+}
+
+func (s *InboundEmailServiceImpl) formatAddress(localPart string) string {
+	if s.options.Domain == "" {
+		return localPart
+	}
+	return localPart + "@" + s.options.Domain
+}
+
+// ResolveRecipient maps an inbound address to the user who owns it.
+func (s *InboundEmailServiceImpl) ResolveRecipient(db *gorm.DB, address string) (uuid.UUID, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return uuid.Nil, ErrInboundRecipientUnknown
+	}
+
+	local, domain, ok := strings.Cut(strings.ToLower(parsed.Address), "@")
+	if !ok || (s.options.Domain != "" && domain != s.options.Domain) || !strings.HasPrefix(local, inboundLocalPartPrefix) {
+		return uuid.Nil, ErrInboundRecipientUnknown
+	}
+
+	var owner models.InboundEmailAddress
+	if err := db.Where("local_part = ?", local).First(&owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrInboundRecipientUnknown
+		}
+		return uuid.Nil, err
+	}
+	return owner.UserID, nil
+}
+
+// Ingest turns a raw RFC 5322 message into a task owned by the user whose
+// inbound address it was sent to. recipients are the envelope recipients;
+// when empty the To and Cc headers are used. The From address must be the
+// owner's registered email.
+func (s *InboundEmailServiceImpl) Ingest(db *gorm.DB, raw io.Reader, recipients []string) (*models.Task, error) {
+	email, err := ParseInboundEmail(raw, s.options.MaxAttachmentSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(email.Attachments) > s.options.MaxAttachments {
+		return nil, fmt.Errorf("%w: at most %d attachments", ErrInboundMessageTooLarge, s.options.MaxAttachments)
+	}
+
+	if len(recipients) == 0 {
+		recipients = email.To
+	}
+	userID := uuid.Nil
+	for _, recipient := range recipients {
+		userID, err = s.ResolveRecipient(db, recipient)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrInboundRecipientUnknown) {
+			return nil, err
+		}
+	}
+	if userID == uuid.Nil {
+		return nil, ErrInboundRecipientUnknown
+	}
+
+	var owner models.User
+	if err := db.Select("id", "email").Where("id = ?", userID).First(&owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInboundRecipientUnknown
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(owner.Email, email.From) {
+		return nil, ErrInboundSenderMismatch
+	}
+
+	taskID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate task ID: %w", err)
+	}
+	task := models.Task{
+		ID:          taskID,
+		UserID:      userID,
+		Title:       inboundTitle(email.Subject),
+		Description: email.Body,
+		Status:      "pending",
+	}
+	// Mail arrives outside any request, so the task goes to the owner's
+	// default workspace.
+	if member, err := defaultMembership(db, userID); err == nil {
+		task.WorkspaceID = &member.WorkspaceID
+	} else if !errors.Is(err, ErrNotWorkspaceMember) {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := s.taskService.CreateTask(tx, task); err != nil {
+			return err
+		}
+		for _, file := range email.Attachments {
+			attachment := models.TaskAttachment{
+				TaskID:      task.ID,
+				Filename:    file.Filename,
+				ContentType: file.ContentType,
+				Data:        file.Data,
+				UploadedBy:  &userID,
+			}
+			if err := s.attachmentService.AddAttachment(tx, &attachment); err != nil {
+				return fmt.Errorf("failed to save attachment %q: %w", file.Filename, err)
+			}
+		}
+		if s.events == nil {
+			return nil
+		}
+		return s.events.PublishTaskEvent(tx, models.TaskEvent{
+			Type:        models.TaskEventCreated,
+			TaskID:      task.ID,
+			WorkspaceID: task.WorkspaceID,
+			OwnerID:     userID,
+			ActorID:     userID,
+			Title:       task.Title,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func inboundTitle(subject string) string {
+	subject = strings.Join(strings.Fields(subject), " ")
+	if subject == "" {
+		return "(no subject)"
+	}
+	if utf8.RuneCountInString(subject) > inboundMaxTitleLength {
+		subject = string([]rune(subject)[:inboundMaxTitleLength])
+	}
+	return subject
+}
+
+// ParseInboundEmail reads a raw RFC 5322 message, decoding MIME parts,
+// transfer encodings and encoded headers. Attachments larger than
+// maxAttachmentSize fail with ErrInboundMessageTooLarge.
+func ParseInboundEmail(raw io.Reader, maxAttachmentSize int64) (*InboundEmail, error) {
+	msg, err := mail.ReadMessage(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInboundMessageInvalid, err)
+	}
+
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return nil, fmt.Errorf("%w: missing or invalid From header", ErrInboundMessageInvalid)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	email := &InboundEmail{From: strings.ToLower(from[0].Address), Subject: subject}
+	for _, field := range []string{"To", "Cc"} {
+		addresses, _ := msg.Header.AddressList(field)
+		for _, address := range addresses {
+			email.To = append(email.To, address.Address)
+		}
+	}
+
+	parser := inboundParser{email: email, maxAttachmentSize: maxAttachmentSize, words: decoder}
+	if err := parser.walk(msg.Header, msg.Body, 0); err != nil {
+		return nil, err
+	}
+
+	email.Body = strings.TrimSpace(parser.text)
+	if email.Body == "" {
+		email.Body = strings.TrimSpace(htmlToText(parser.html))
+	}
+	return email, nil
+}
+
+type mimeHeader interface {
+	Get(key string) string
+}
+
+type inboundParser struct {
+	email             *InboundEmail
+	maxAttachmentSize int64
+	words             *mime.WordDecoder
+	text              string
+	html              string
+}
+
+func (p *inboundParser) walk(header mimeHeader, body io.Reader, depth int) error {
+	if depth > inboundMaxPartDepth {
+		return fmt.Errorf("%w: MIME parts nested too deeply", ErrInboundMessageInvalid)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInboundMessageInvalid, err)
+			}
+			if err := p.walk(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if decoded, err := p.words.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if disposition == "attachment" || (filename != "" && !(isText && disposition == "inline")) || !isText {
+		return p.attach(filename, mediaType, body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, p.maxAttachmentSize+1))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInboundMessageInvalid, err)
+	}
+	text := decodeCharset(data, params["charset"])
+	if mediaType == "text/html" {
+		if p.html == "" {
+			p.html = text
+		}
+	} else if p.text == "" {
+		p.text = text
+	}
+	return nil
+}
+
+func (p *inboundParser) attach(filename, contentType string, body io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(body, p.maxAttachmentSize+1))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInboundMessageInvalid, err)
+	}
+	if int64(len(data)) > p.maxAttachmentSize {
+		return fmt.Errorf("%w: %q is larger than %d bytes", ErrInboundMessageTooLarge, filename, p.maxAttachmentSize)
+	}
+
+	if filename = sanitizeFilename(filename); filename == "" {
+		filename = fmt.Sprintf("attachment-%d", len(p.email.Attachments)+1)
+	}
+	p.email.Attachments = append(p.email.Attachments, InboundAttachment{
+		Filename:    filename,
+		ContentType: contentType,
+		Data:        data,
+	})
+	return nil
+}
+
+// sanitizeFilename drops any path and control characters from a client
+// supplied filename.
+func sanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == ".." {
+		return ""
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		name = string([]rune(name)[:255])
+	}
+	return name
+}
+
+// decodeCharset converts Latin-1 text to UTF-8. Other charsets are assumed to
+// be UTF-8 compatible; invalid sequences are replaced.
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+	return string(bytes.ToValidUTF8(data, []byte("�")))
+}
+
+var (
+	htmlBlockTags = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6])\b[^>]*>`)
+	htmlDropped   = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)>`)
+	htmlTags      = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines    = regexp.MustCompile(`\n\s*\n\s*(\n\s*)+`)
+)
+
+// htmlToText is a rough conversion used only when a message has no plain
+// text part.
+func htmlToText(s string) string {
+	s = htmlDropped.ReplaceAllString(s, "")
+	s = htmlBlockTags.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return blankLines.ReplaceAllString(s, "\n\n")
+}
@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const inboundTestMessage = "From: Alice <Alice@Example.com>\r\n" +
+	"To: %s\r\n" +
+	"Subject: =?UTF-8?Q?Quarterly_r=C3=A9view?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Please review the numbers =E2=80=94 due Friday.\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Please review the numbers</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/csv; name=\"q1.csv\"\r\n" +
+	"Content-Disposition: attachment; filename=\"../q1.csv\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"cmVnaW9uLHRvdGFsCm5vcnRoLDQy\r\n" +
+	"--outer--\r\n"
+
+func setupInboundTestDB(t *testing.T) *gorm.DB {
+	db := openTestDB(t, "outbox", "users", "tasks", "task_attachments", "workspace_members")
+	require.NoError(t, db.Exec(`CREATE TABLE inbound_email_addresses (
+		user_id TEXT PRIMARY KEY,
+		local_part TEXT NOT NULL UNIQUE,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`).Error)
+	return db
+}
+
+func newInboundTestService(db *gorm.DB) *services.InboundEmailServiceImpl {
+	return services.NewInboundEmailService(services.NewTaskService(), services.NewAttachmentService(), services.NewTaskEventOutbox(), services.InboundEmailOptions{
+		Domain: "inbound.example.com",
+	})
+}
+
+func TestParseInboundEmail(t *testing.T) {
+	email, err := services.ParseInboundEmail(strings.NewReader(strings.Replace(inboundTestMessage, "%s", "task-abc@inbound.example.com", 1)), 1024)
+	require.NoError(t, err)
+
+	assert.Equal(t, "alice@example.com", email.From)
+	assert.Equal(t, []string{"task-abc@inbound.example.com"}, email.To)
+	assert.Equal(t, "Quarterly réview", email.Subject)
+	assert.Equal(t, "Please review the numbers — due Friday.", email.Body)
+	require.Len(t, email.Attachments, 1)
+	assert.Equal(t, "q1.csv", email.Attachments[0].Filename, "paths are stripped from filenames")
+	assert.Equal(t, "text/csv", email.Attachments[0].ContentType)
+	assert.Equal(t, "region,total\nnorth,42", string(email.Attachments[0].Data))
+
+	_, err = services.ParseInboundEmail(strings.NewReader(strings.Replace(inboundTestMessage, "%s", "x@y", 1)), 8)
+	assert.ErrorIs(t, err, services.ErrInboundMessageTooLarge)
+
+	htmlOnly := "From: bob@example.com\r\nContent-Type: text/html\r\n\r\n<p>Hello &amp; welcome</p><script>x()</script>"
+	email, err = services.ParseInboundEmail(strings.NewReader(htmlOnly), 1024)
+	require.NoError(t, err)
+	assert.Empty(t, email.Subject)
+	assert.Equal(t, "Hello & welcome", email.Body)
+
+	_, err = services.ParseInboundEmail(strings.NewReader("Subject: no sender\r\n\r\nbody"), 1024)
+	assert.ErrorIs(t, err, services.ErrInboundMessageInvalid)
+}
+
+func TestInboundEmailService_AddressLifecycle(t *testing.T) {
+	db := setupInboundTestDB(t)
+	inbound := newInboundTestService(db)
+	userID := uuid.Must(uuid.NewV4())
+
+	address, err := inbound.GetAddress(db, userID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(address, "@inbound.example.com"))
+
+	again, err := inbound.GetAddress(db, userID)
+	require.NoError(t, err)
+	assert.Equal(t, address, again)
+
+	resolved, err := inbound.ResolveRecipient(db, strings.ToUpper(address))
+	require.NoError(t, err)
+	assert.Equal(t, userID, resolved)
+
+	_, err = inbound.ResolveRecipient(db, strings.Replace(address, "inbound.example.com", "example.org", 1))
+	assert.ErrorIs(t, err, services.ErrInboundRecipientUnknown)
+
+	regenerated, err := inbound.RegenerateAddress(db, userID)
+	require.NoError(t, err)
+	assert.NotEqual(t, address, regenerated)
+	_, err = inbound.ResolveRecipient(db, address)
+	assert.ErrorIs(t, err, services.ErrInboundRecipientUnknown, "the old address stops working")
+}
+
+func TestInboundSMTPServer_CreatesTasks(t *testing.T) {
+	db := setupInboundTestDB(t)
+	inbound := newInboundTestService(db)
+
+	userID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec("INSERT INTO users (id, username, email) VALUES (?, 'alice', 'alice@example.com')", userID).Error)
+	address, err := inbound.GetAddress(db, userID)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := services.NewInboundSMTPServer(db, inbound, services.InboundSMTPOptions{Hostname: "inbound.example.com"})
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	addr := listener.Addr().String()
+
+	message := strings.Replace(inboundTestMessage, "%s", address, 1)
+	require.NoError(t, smtp.SendMail(addr, nil, "alice@example.com", []string{address}, []byte(message)))
+
+	var task models.Task
+	require.NoError(t, db.Where("user_id = ?", userID).First(&task).Error)
+	assert.Equal(t, "Quarterly réview", task.Title)
+	assert.Equal(t, "Please review the numbers — due Friday.", task.Description)
+	assert.Equal(t, "pending", task.Status)
+
+	attachments, err := services.NewAttachmentService().GetAttachments(db, task.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "q1.csv", attachments[0].Filename)
+	assert.Equal(t, int64(len("region,total\nnorth,42")), attachments[0].Size)
+	assert.Empty(t, attachments[0].Data, "listing does not load file contents")
+
+	var outbox models.OutboxMessage
+	require.NoError(t, db.First(&outbox).Error)
+	assert.Equal(t, models.TaskEventCreated, outbox.EventType)
+	assert.Equal(t, task.ID, outbox.AggregateID)
+
+	// Unknown addresses are refused at RCPT time.
+	err = smtp.SendMail(addr, nil, "alice@example.com", []string{"task-000000@inbound.example.com"}, []byte(message))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "550")
+
+	// Knowing the address is not enough; the sender must be its owner.
+	spoofed := strings.Replace(message, "Alice <Alice@Example.com>", "mallory@example.net", 1)
+	err = smtp.SendMail(addr, nil, "mallory@example.net", []string{address}, []byte(spoofed))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "5.7.1")
+
+	var count int64
+	require.NoError(t, db.Model(&models.Task{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
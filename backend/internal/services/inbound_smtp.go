@@ -0,0 +1,348 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InboundSMTPOptions struct {
+	Hostname       string
+	MaxMessageSize int64
+	MaxRecipients  int
+	// Timeout bounds how long the server waits for each command or message.
+	Timeout time.Duration
+}
+
+// InboundSMTPServer is a minimal SMTP receiver that feeds messages to an
+// InboundEmailService. It is meant to sit behind the organisation's MTA, so it
+// does not relay, authenticate or offer STARTTLS. Recipients are checked at
+// RCPT time so that unknown addresses are rejected before any data is sent.
+type InboundSMTPServer struct {
+	db      *gorm.DB
+	service InboundEmailService
+	options InboundSMTPOptions
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func NewInboundSMTPServer(db *gorm.DB, service InboundEmailService, options InboundSMTPOptions) *InboundSMTPServer {
+	if options.Hostname == "" {
+		options.Hostname = "localhost"
+	}
+	if options.MaxMessageSize <= 0 {
+		options.MaxMessageSize = 25 << 20
+	}
+	if options.MaxRecipients <= 0 {
+		options.MaxRecipients = 10
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 5 * time.Minute
+	}
+
+	return &InboundSMTPServer{
+		db:      db,
+		service: service,
+		options: options,
+		conns:   make(map[net.Conn]struct{}),
+	}
+}
+
+func (s *InboundSMTPServer) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections until Close is called, then returns nil.
+func (s *InboundSMTPServer) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return nil
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				conn.Close()
+			}()
+			s.handle(conn)
+		}()
+	}
+}
+
+// Addr returns the listening address, or nil before Serve is called.
+func (s *InboundSMTPServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close stops accepting mail and drops open sessions.
+func (s *InboundSMTPServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+type smtpSession struct {
+	server     *InboundSMTPServer
+	text       *textproto.Conn
+	greeted    bool
+	hasSender  bool
+	recipients []string
+}
+
+func (s *InboundSMTPServer) handle(conn net.Conn) {
+	session := &smtpSession{server: s, text: textproto.NewConn(conn)}
+	session.reply(220, s.options.Hostname+" ESMTP ready")
+
+	for {
+		conn.SetDeadline(time.Now().Add(s.options.Timeout))
+		line, err := session.text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			session.greeted = true
+			session.reset()
+			session.reply(250, s.options.Hostname)
+		case "EHLO":
+			session.greeted = true
+			session.reset()
+			session.reply(250, s.options.Hostname, "SIZE "+strconv.FormatInt(s.options.MaxMessageSize, 10), "8BITMIME", "PIPELINING")
+		case "MAIL":
+			session.mail(arg)
+		case "RCPT":
+			session.rcpt(arg)
+		case "DATA":
+			if !session.data() {
+				return
+			}
+		case "RSET":
+			session.reset()
+			session.reply(250, "2.0.0 OK")
+		case "NOOP":
+			session.reply(250, "2.0.0 OK")
+		case "VRFY":
+			session.reply(252, "2.5.2 Cannot verify user")
+		case "QUIT":
+			session.reply(221, "2.0.0 Bye")
+			return
+		default:
+			session.reply(502, "5.5.2 Command not recognized")
+		}
+	}
+}
+
+func (s *smtpSession) reset() {
+	s.hasSender = false
+	s.recipients = nil
+}
+
+func (s *smtpSession) reply(code int, lines ...string) {
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+		s.text.PrintfLine("%d%s%s", code, separator, line)
+	}
+}
+
+func (s *smtpSession) mail(arg string) {
+	if !s.greeted {
+		s.reply(503, "5.5.1 Send HELO first")
+		return
+	}
+	if s.hasSender {
+		s.reply(503, "5.5.1 Sender already given")
+		return
+	}
+
+	_, params, ok := parseSMTPPath(arg, "FROM:")
+	if !ok {
+		s.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+		return
+	}
+	for _, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "SIZE") {
+			if size, err := strconv.ParseInt(value, 10, 64); err == nil && size > s.server.options.MaxMessageSize {
+				s.reply(552, "5.3.4 Message too big")
+				return
+			}
+		}
+	}
+
+	s.hasSender = true
+	s.reply(250, "2.1.0 OK")
+}
+
+func (s *smtpSession) rcpt(arg string) {
+	if !s.hasSender {
+		s.reply(503, "5.5.1 Send MAIL first")
+		return
+	}
+	if len(s.recipients) >= s.server.options.MaxRecipients {
+		s.reply(452, "4.5.3 Too many recipients")
+		return
+	}
+
+	address, _, ok := parseSMTPPath(arg, "TO:")
+	if !ok || address == "" {
+		s.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+		return
+	}
+
+	if _, err := s.server.service.ResolveRecipient(s.server.db, address); err != nil {
+		if errors.Is(err, ErrInboundRecipientUnknown) {
+			s.reply(550, "5.1.1 Mailbox unavailable")
+		} else {
+			log.Printf("inbound smtp: failed to resolve %s: %v", address, err)
+			s.reply(451, "4.3.0 Temporary failure")
+		}
+		return
+	}
+
+	s.recipients = append(s.recipients, address)
+	s.reply(250, "2.1.5 OK")
+}
+
+// data reads the message and creates one task per accepted recipient. It
+// returns false when the connection is no longer usable.
+func (s *smtpSession) data() bool {
+	if len(s.recipients) == 0 {
+		s.reply(503, "5.5.1 Send RCPT first")
+		return true
+	}
+	s.reply(354, "Start mail input; end with <CRLF>.<CRLF>")
+
+	limit := s.server.options.MaxMessageSize
+	dot := s.text.DotReader()
+	message, err := io.ReadAll(io.LimitReader(dot, limit+1))
+	if err == nil && int64(len(message)) > limit {
+		_, err = io.Copy(io.Discard, dot)
+		if err == nil {
+			s.reset()
+			s.reply(552, "5.3.4 Message too big")
+			return true
+		}
+	}
+	if err != nil {
+		return false
+	}
+
+	recipients := s.recipients
+	s.reset()
+
+	code, status := 250, "2.0.0 OK: task created"
+	for _, recipient := range recipients {
+		_, err := s.server.service.Ingest(s.server.db, bytes.NewReader(message), []string{recipient})
+		if err == nil {
+			continue
+		}
+
+		switch {
+		case errors.Is(err, ErrInboundSenderMismatch):
+			code, status = 550, "5.7.1 Sender is not allowed to post to this address"
+		case errors.Is(err, ErrInboundRecipientUnknown):
+			code, status = 550, "5.1.1 Mailbox unavailable"
+		case errors.Is(err, ErrInboundMessageTooLarge):
+			code, status = 552, "5.3.4 Attachments too large"
+		case errors.Is(err, ErrInboundMessageInvalid):
+			code, status = 554, "5.6.0 Malformed message"
+		default:
+			log.Printf("inbound smtp: failed to create task for %s: %v", recipient, err)
+			code, status = 451, "4.3.0 Temporary failure, try again later"
+		}
+		break
+	}
+
+	s.reply(code, status)
+	return true
+}
+
+// parseSMTPPath parses "FROM:<addr> PARAM=x" style arguments.
+func parseSMTPPath(arg, prefix string) (string, []string, bool) {
+	arg = strings.TrimSpace(arg)
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+
+	if !strings.HasPrefix(arg, "<") {
+		return "", nil, false
+	}
+	end := strings.IndexByte(arg, '>')
+	if end < 0 {
+		return "", nil, false
+	}
+
+	return arg[1:end], strings.Fields(arg[end+1:]), true
+}
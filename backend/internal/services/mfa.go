@@ -0,0 +1,511 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultMFATokenTTL = 5 * time.Minute
+	DefaultMFAIssuer   = "Taskify"
+
+	mfaTokenType       = "mfa"
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryCodeChars  = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+var (
+	ErrInvalidMFACode     = errors.New("invalid MFA code")
+	ErrInvalidMFAToken    = errors.New("invalid or expired MFA token")
+	ErrMFANotEnrolled     = errors.New("MFA is not set up")
+	ErrMFAAlreadyEnabled  = errors.New("MFA is already enabled")
+	ErrMFARequiredForRole = errors.New("MFA is required for the user's role")
+	ErrMFALocked          = errors.New("too many failed MFA attempts")
+)
+
+type MFAOptions struct {
+	// Secret signs MFA tokens and derives the key that encrypts TOTP secrets.
+	Secret string
+	// Issuer names the account in authenticator apps.
+	Issuer   string
+	TokenTTL time.Duration
+	// RequiredRoles lists roles that may not sign in without MFA. Users in
+	// them who have not set it up are sent through enrollment at login.
+	RequiredRoles []string
+	BCryptCost    int
+	// Attempts limits failed codes per user. Without one, failures are
+	// counted in process with the default limit.
+	Attempts *MFAAttemptLimiter
+}
+
+// MFAEnrollment is a TOTP secret waiting to be confirmed. QRPayload is the text
+// to encode in the QR code shown to the user.
+type MFAEnrollment struct {
+	Secret    string `json:"secret"`
+	URI       string `json:"otpauth_uri"`
+	QRPayload string `json:"qr_payload"`
+}
+
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// MFAChallenge is what an MFA token entitles its holder to: proving the
+// second factor, or setting one up when Enroll is true.
+type MFAChallenge struct {
+	UserID uuid.UUID
+	Enroll bool
+}
+
+type MFAService interface {
+	IsEnabled(db *gorm.DB, userID uuid.UUID) (bool, error)
+	// RequiredFor reports whether one of the user's roles requires MFA.
+	RequiredFor(db *gorm.DB, userID uuid.UUID) (bool, error)
+	Status(db *gorm.DB, userID uuid.UUID) (*MFAStatus, error)
+
+	// IssueToken creates the short-lived token login returns in place of
+	// credentials until the second factor passes.
+	IssueToken(userID uuid.UUID, enroll bool) (string, error)
+	ParseToken(token string) (*MFAChallenge, error)
+	TokenTTL() time.Duration
+
+	// BeginEnrollment creates a new TOTP secret for the user, replacing any
+	// unconfirmed one.
+	BeginEnrollment(db *gorm.DB, user *models.User) (*MFAEnrollment, error)
+	// ConfirmEnrollment enables MFA once the user proves their authenticator
+	// works, and returns their recovery codes.
+	ConfirmEnrollment(db *gorm.DB, userID uuid.UUID, code string) ([]string, error)
+	// Verify accepts a current TOTP code or an unused recovery code. Each is
+	// only accepted once. After too many failures it returns ErrMFALocked
+	// until the lockout passes.
+	Verify(db *gorm.DB, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(db *gorm.DB, userID uuid.UUID, code string) ([]string, error)
+	Disable(db *gorm.DB, userID uuid.UUID, code string) error
+}
+
+type MFAServiceImpl struct {
+	options       MFAOptions
+	requiredRoles map[string]bool
+	aead          cipher.AEAD
+}
+
+func NewMFAService(options MFAOptions) *MFAServiceImpl {
+	if options.Issuer == "" {
+		options.Issuer = DefaultMFAIssuer
+	}
+	if options.TokenTTL <= 0 {
+		options.TokenTTL = DefaultMFATokenTTL
+	}
+	if options.BCryptCost < bcrypt.MinCost {
+		options.BCryptCost = bcrypt.DefaultCost
+	}
+	if options.Attempts == nil {
+		options.Attempts = NewMFAAttemptLimiter(nil, DefaultMFAMaxFailedAttempts, DefaultMFALockout)
+	}
+
+	requiredRoles := make(map[string]bool, len(options.RequiredRoles))
+	for _, role := range options.RequiredRoles {
+		if role = strings.TrimSpace(role); role != "" {
+			requiredRoles[role] = true
+		}
+	}
+
+	key := sha256.Sum256([]byte("mfa-secret:" + options.Secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err) // unreachable: the key is always 32 bytes
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+
+	return &MFAServiceImpl{options: options, requiredRoles: requiredRoles, aead: aead}
+}
+
+func (s *MFAServiceImpl) TokenTTL() time.Duration {
+	return s.options.TokenTTL
+}
+
+func (s *MFAServiceImpl) IsEnabled(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.UserMFA{}).Where("user_id = ? AND enabled_at IS NOT NULL", userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *MFAServiceImpl) RequiredFor(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	if len(s.requiredRoles) == 0 {
+		return false, nil
+	}
+
+	var roles []string
+	err := db.Raw("SELECT r.name FROM roles r JOIN user_roles ur ON r.id = ur.role_id WHERE ur.user_id = ?", userID).
+		Scan(&roles).Error
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if s.requiredRoles[role] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *MFAServiceImpl) Status(db *gorm.DB, userID uuid.UUID) (*MFAStatus, error) {
+	status := &MFAStatus{}
+
+	var mfa models.UserMFA
+	if err := db.Where("user_id = ? AND enabled_at IS NOT NULL", userID).Limit(1).Find(&mfa).Error; err != nil {
+		return nil, err
+	}
+	if mfa.Enabled() {
+		status.Enabled = true
+		status.EnabledAt = mfa.EnabledAt
+		err := db.Model(&models.MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).
+			Count(&status.RecoveryCodesRemaining).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	required, err := s.RequiredFor(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Required = required
+	return status, nil
+}
+
+func (s *MFAServiceImpl) IssueToken(userID uuid.UUID, enroll bool) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id": userID.String(),
+		"type":    mfaTokenType,
+		"enroll":  enroll,
+		"iat":     now.Unix(),
+		"exp":     now.Add(s.options.TokenTTL).Unix(),
+		"iss":     "taskify-backend",
+		"aud":     "taskify-users",
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.options.Secret))
+}
+
+func (s *MFAServiceImpl) ParseToken(token string) (*MFAChallenge, error) {
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.options.Secret), nil
+	})
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidMFAToken
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidMFAToken
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != mfaTokenType {
+		return nil, ErrInvalidMFAToken
+	}
+	userID, err := uuid.FromString(fmt.Sprintf("%v", claims["user_id"]))
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	enroll, _ := claims["enroll"].(bool)
+	return &MFAChallenge{UserID: userID, Enroll: enroll}, nil
+}
+
+func (s *MFAServiceImpl) BeginEnrollment(db *gorm.DB, user *models.User) (*MFAEnrollment, error) {
+	enabled, err := s.IsEnabled(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// The upsert leaves confirmed enrollments alone, in case one was
+	// confirmed since the check above.
+	now := time.Now()
+	mfa := models.UserMFA{UserID: user.ID, Secret: encrypted, CreatedAt: now, UpdatedAt: now}
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "last_used_step", "updated_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.enabled_at IS NULL"}}},
+	}).Create(&mfa)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to save MFA enrollment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	uri := TOTPURI(s.options.Issuer, user.Email, secret)
+	return &MFAEnrollment{Secret: secret, URI: uri, QRPayload: uri}, nil
+}
+
+func (s *MFAServiceImpl) ConfirmEnrollment(db *gorm.DB, userID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var mfa models.UserMFA
+		if err := tx.Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMFANotEnrolled
+			}
+			return err
+		}
+		if mfa.Enabled() {
+			return ErrMFAAlreadyEnabled
+		}
+		if err := s.useTOTPCode(tx, &mfa, code); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.UserMFA{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"enabled_at": time.Now(), "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *MFAServiceImpl) Verify(db *gorm.DB, userID uuid.UUID, code string) error {
+	ctx := db.Statement.Context
+	if s.options.Attempts.Locked(ctx, userID) {
+		return ErrMFALocked
+	}
+
+	err := s.verify(db, userID, code)
+	switch {
+	case errors.Is(err, ErrInvalidMFACode):
+		if failErr := s.options.Attempts.Fail(ctx, userID); failErr != nil {
+			log.Printf("⚠️  Failed to record MFA attempt: %v", failErr)
+		}
+	case err == nil:
+		if resetErr := s.options.Attempts.Reset(ctx, userID); resetErr != nil {
+			log.Printf("⚠️  Failed to reset MFA attempts: %v", resetErr)
+		}
+	}
+	return err
+}
+
+func (s *MFAServiceImpl) verify(db *gorm.DB, userID uuid.UUID, code string) error {
+	var mfa models.UserMFA
+	if err := db.Where("user_id = ? AND enabled_at IS NOT NULL", userID).First(&mfa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMFANotEnrolled
+		}
+		return err
+	}
+
+	code = normalizeMFACode(code)
+	if len(code) == totpDigits {
+		return s.useTOTPCode(db, &mfa, code)
+	}
+	return s.useRecoveryCode(db, userID, code)
+}
+
+func (s *MFAServiceImpl) RegenerateRecoveryCodes(db *gorm.DB, userID uuid.UUID, code string) ([]string, error) {
+	if err := s.Verify(db, userID, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *MFAServiceImpl) Disable(db *gorm.DB, userID uuid.UUID, code string) error {
+	required, err := s.RequiredFor(db, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredForRole
+	}
+	if err := s.Verify(db, userID, code); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserMFA{}).Error
+	})
+}
+
+// useTOTPCode accepts code if it is current and newer than the last code the
+// user signed in with.
+func (s *MFAServiceImpl) useTOTPCode(db *gorm.DB, mfa *models.UserMFA, code string) error {
+	secret, err := s.decrypt(mfa.Secret)
+	if err != nil {
+		return err
+	}
+	step, ok := ValidateTOTP(secret, normalizeMFACode(code), time.Now())
+	if !ok || step <= mfa.LastUsedStep {
+		return ErrInvalidMFACode
+	}
+
+	// Conditional on the step so a code racing itself only passes once.
+	result := db.Model(&models.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", mfa.UserID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	mfa.LastUsedStep = step
+	return nil
+}
+
+func (s *MFAServiceImpl) useRecoveryCode(db *gorm.DB, userID uuid.UUID, code string) error {
+	if len(code) != recoveryCodeLength {
+		return ErrInvalidMFACode
+	}
+
+	var candidates []models.MFARecoveryCode
+	if err := db.Where("user_id = ? AND used_at IS NULL", userID).Find(&candidates).Error; err != nil {
+		return err
+	}
+	for _, candidate := range candidates {
+		if bcrypt.CompareHashAndPassword([]byte(candidate.CodeHash), []byte(code)) != nil {
+			continue
+		}
+		result := db.Model(&models.MFARecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", candidate.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+	return ErrInvalidMFACode
+}
+
+// replaceRecoveryCodes issues a new set of recovery codes, invalidating the
+// old ones, and returns them formatted for display.
+func (s *MFAServiceImpl) replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	now := time.Now()
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), s.options.BCryptCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+		record := models.MFARecoveryCode{
+			ID:        uuid.Must(uuid.NewV4()),
+			UserID:    userID,
+			CodeHash:  string(hash),
+			CreatedAt: now,
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	return codes, nil
+}
+
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	for i, b := range buf {
+		// 256 is a multiple of the alphabet's 32 characters, so there is no bias.
+		buf[i] = recoveryCodeChars[int(b)%len(recoveryCodeChars)]
+	}
+	return string(buf), nil
+}
+
+// normalizeMFACode drops the spacing and dashes users copy along with codes.
+func normalizeMFACode(code string) string {
+	code = strings.ToLower(code)
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+func (s *MFAServiceImpl) encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt MFA secret: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *MFAServiceImpl) decrypt(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", errors.New("failed to decrypt MFA secret")
+	}
+	nonce, sealed := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt MFA secret")
+	}
+	return string(plaintext), nil
+}
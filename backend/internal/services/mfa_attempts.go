@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultMFAMaxFailedAttempts = 5
+	DefaultMFALockout           = 15 * time.Minute
+
+	mfaFailuresKeyPrefix = "auth:mfa:failures:"
+)
+
+// MFAAttemptLimiter counts failed second-factor codes per user. Once a user
+// reaches the limit, codes are refused until the lockout passes, so a stolen
+// password cannot be paired with guessed codes; the count starts over after a
+// code is accepted. Like TokenDenylist it shares counts through Redis when a
+// client is given, and also keeps its own to fall back on while Redis is
+// unreachable.
+type MFAAttemptLimiter struct {
+	client      *redis.Client
+	maxFailures int
+	lockout     time.Duration
+
+	mu       sync.Mutex
+	failures map[uuid.UUID]mfaFailures
+}
+
+type mfaFailures struct {
+	count     int
+	expiresAt time.Time
+}
+
+func NewMFAAttemptLimiter(client *redis.Client, maxFailures int, lockout time.Duration) *MFAAttemptLimiter {
+	if maxFailures <= 0 {
+		maxFailures = DefaultMFAMaxFailedAttempts
+	}
+	if lockout <= 0 {
+		lockout = DefaultMFALockout
+	}
+	return &MFAAttemptLimiter{
+		client:      client,
+		maxFailures: maxFailures,
+		lockout:     lockout,
+		failures:    make(map[uuid.UUID]mfaFailures),
+	}
+}
+
+// Locked reports whether the user has used up their failed attempts.
+func (l *MFAAttemptLimiter) Locked(ctx context.Context, userID uuid.UUID) bool {
+	l.mu.Lock()
+	entry, ok := l.failures[userID]
+	locked := ok && time.Now().Before(entry.expiresAt) && entry.count >= l.maxFailures
+	l.mu.Unlock()
+
+	if l.client == nil {
+		return locked
+	}
+
+	raw, err := l.client.Get(ctx, mfaFailuresKeyPrefix+userID.String()).Result()
+	if errors.Is(err, redis.Nil) {
+		return false
+	}
+	if err != nil {
+		log.Printf("⚠️  MFA attempt lookup failed: %v", err)
+		return locked
+	}
+	count, err := strconv.Atoi(raw)
+	return err == nil && count >= l.maxFailures
+}
+
+// Fail records a rejected code. The lockout runs from the user's first
+// failure, so it ends at most one lockout period after guessing began.
+func (l *MFAAttemptLimiter) Fail(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+
+	l.mu.Lock()
+	l.pruneLocked(now)
+	entry, ok := l.failures[userID]
+	if !ok {
+		entry.expiresAt = now.Add(l.lockout)
+	}
+	entry.count++
+	l.failures[userID] = entry
+	l.mu.Unlock()
+
+	if l.client == nil {
+		return nil
+	}
+	key := mfaFailuresKeyPrefix + userID.String()
+	count, err := l.client.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		return l.client.Expire(ctx, key, l.lockout).Err()
+	}
+	return nil
+}
+
+// Reset clears the user's failed attempts.
+func (l *MFAAttemptLimiter) Reset(ctx context.Context, userID uuid.UUID) error {
+	l.mu.Lock()
+	delete(l.failures, userID)
+	l.mu.Unlock()
+
+	if l.client == nil {
+		return nil
+	}
+	return l.client.Del(ctx, mfaFailuresKeyPrefix+userID.String()).Err()
+}
+
+func (l *MFAAttemptLimiter) pruneLocked(now time.Time) {
+	for userID, entry := range l.failures {
+		if !now.Before(entry.expiresAt) {
+			delete(l.failures, userID)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAAttemptLimiter_InProcess(t *testing.T) {
+	ctx := context.Background()
+	limiter := services.NewMFAAttemptLimiter(nil, 2, time.Hour)
+	userID := uuid.Must(uuid.NewV4())
+
+	require.NoError(t, limiter.Fail(ctx, userID))
+	assert.False(t, limiter.Locked(ctx, userID))
+	require.NoError(t, limiter.Fail(ctx, userID))
+	assert.True(t, limiter.Locked(ctx, userID))
+	assert.False(t, limiter.Locked(ctx, uuid.Must(uuid.NewV4())))
+
+	require.NoError(t, limiter.Reset(ctx, userID))
+	assert.False(t, limiter.Locked(ctx, userID))
+
+	short := services.NewMFAAttemptLimiter(nil, 1, 10*time.Millisecond)
+	require.NoError(t, short.Fail(ctx, userID))
+	assert.True(t, short.Locked(ctx, userID))
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, short.Locked(ctx, userID), "the lockout ends")
+}
+
+func TestMFAAttemptLimiter_SharedThroughRedis(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	newClient := func() *redis.Client {
+		return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	}
+
+	first := services.NewMFAAttemptLimiter(newClient(), 2, time.Hour)
+	second := services.NewMFAAttemptLimiter(newClient(), 2, time.Hour)
+	userID := uuid.Must(uuid.NewV4())
+
+	require.NoError(t, first.Fail(ctx, userID))
+	require.NoError(t, second.Fail(ctx, userID))
+	assert.True(t, first.Locked(ctx, userID), "failures on every instance count")
+	assert.True(t, mr.TTL("auth:mfa:failures:"+userID.String()) > 0, "the count expires with the lockout")
+
+	require.NoError(t, first.Reset(ctx, userID))
+	require.NoError(t, second.Fail(ctx, userID))
+	assert.False(t, second.Locked(ctx, userID), "a reset on any instance starts the count over")
+
+	require.NoError(t, first.Fail(ctx, userID))
+	require.NoError(t, first.Fail(ctx, userID))
+	mr.Close()
+	assert.True(t, first.Locked(ctx, userID), "local failures still count while Redis is down")
+}
@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"context"
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func setupMFATestDB(t *testing.T) (*gorm.DB, *models.User) {
+	db := openTestDB(t, "roles", "user_roles")
+	for _, statement := range []string{
+		`CREATE TABLE user_mfa (
+			user_id TEXT PRIMARY KEY,
+			secret TEXT NOT NULL,
+			last_used_step INTEGER NOT NULL DEFAULT 0,
+			enabled_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE mfa_recovery_codes (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			code_hash TEXT NOT NULL,
+			used_at DATETIME,
+			created_at DATETIME
+		)`,
+	} {
+		require.NoError(t, db.Exec(statement).Error)
+	}
+
+	user := &models.User{ID: uuid.Must(uuid.NewV4()), Email: "admin@example.com"}
+	return db, user
+}
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// Test vector from RFC 6238 appendix B, truncated to six digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	code, err := services.TOTPCode(secret, time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	code, err = services.TOTPCode(secret, time.Unix(1111111109, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "081804", code)
+
+	_, ok := services.ValidateTOTP(secret, "081804", time.Unix(1111111109+30, 0))
+	assert.True(t, ok, "the previous step is accepted for clock drift")
+	_, ok = services.ValidateTOTP(secret, "081804", time.Unix(1111111109+90, 0))
+	assert.False(t, ok)
+}
+
+func TestMFAService_EnrollAndVerify(t *testing.T) {
+	db, user := setupMFATestDB(t)
+	mfa := services.NewMFAService(services.MFAOptions{Secret: "secret", BCryptCost: bcrypt.MinCost})
+
+	enrollment, err := mfa.BeginEnrollment(db, user)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Taskify:admin@example.com?"))
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	assert.Equal(t, enrollment.URI, enrollment.QRPayload)
+
+	var stored models.UserMFA
+	require.NoError(t, db.First(&stored, "user_id = ?", user.ID).Error)
+	assert.NotContains(t, stored.Secret, enrollment.Secret, "secrets are encrypted at rest")
+
+	enabled, err := mfa.IsEnabled(db, user.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled, "enrollment waits for confirmation")
+
+	_, err = mfa.ConfirmEnrollment(db, user.ID, "000000")
+	assert.ErrorIs(t, err, services.ErrInvalidMFACode)
+
+	code, err := services.TOTPCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := mfa.ConfirmEnrollment(db, user.ID, code)
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, 10)
+
+	enabled, err = mfa.IsEnabled(db, user.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, err = mfa.BeginEnrollment(db, user)
+	assert.ErrorIs(t, err, services.ErrMFAAlreadyEnabled)
+
+	assert.ErrorIs(t, mfa.Verify(db, user.ID, code), services.ErrInvalidMFACode, "codes cannot be replayed")
+
+	assert.NoError(t, mfa.Verify(db, user.ID, strings.ToUpper(recoveryCodes[0])))
+	assert.ErrorIs(t, mfa.Verify(db, user.ID, recoveryCodes[0]), services.ErrInvalidMFACode, "recovery codes work once")
+
+	status, err := mfa.Status(db, user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.EqualValues(t, 9, status.RecoveryCodesRemaining)
+
+	assert.ErrorIs(t, mfa.Disable(db, user.ID, "wrong"), services.ErrInvalidMFACode)
+	require.NoError(t, mfa.Disable(db, user.ID, recoveryCodes[1]))
+	enabled, err = mfa.IsEnabled(db, user.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestMFAService_TokensAndRequiredRoles(t *testing.T) {
+	db, user := setupMFATestDB(t)
+	roleID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec("INSERT INTO roles (id, name) VALUES (?, 'admin')", roleID).Error)
+	require.NoError(t, db.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", user.ID, roleID).Error)
+
+	optional := services.NewMFAService(services.MFAOptions{Secret: "secret"})
+	required, err := optional.RequiredFor(db, user.ID)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	strict := services.NewMFAService(services.MFAOptions{Secret: "secret", RequiredRoles: []string{"admin"}, BCryptCost: bcrypt.MinCost})
+	required, err = strict.RequiredFor(db, user.ID)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	token, err := strict.IssueToken(user.ID, true)
+	require.NoError(t, err)
+	challenge, err := strict.ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, challenge.UserID)
+	assert.True(t, challenge.Enroll)
+
+	_, err = services.NewMFAService(services.MFAOptions{Secret: "other"}).ParseToken(token)
+	assert.ErrorIs(t, err, services.ErrInvalidMFAToken)
+
+	enrollment, err := strict.BeginEnrollment(db, user)
+	require.NoError(t, err)
+	code, err := services.TOTPCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := strict.ConfirmEnrollment(db, user.ID, code)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, strict.Disable(db, user.ID, recoveryCodes[0]), services.ErrMFARequiredForRole)
+}
+
+func TestMFAService_LocksAfterFailedAttempts(t *testing.T) {
+	db, user := setupMFATestDB(t)
+	attempts := services.NewMFAAttemptLimiter(nil, 3, time.Hour)
+	mfa := services.NewMFAService(services.MFAOptions{Secret: "secret", BCryptCost: bcrypt.MinCost, Attempts: attempts})
+
+	enrollment, err := mfa.BeginEnrollment(db, user)
+	require.NoError(t, err)
+	code, err := services.TOTPCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := mfa.ConfirmEnrollment(db, user.ID, code)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, mfa.Verify(db, user.ID, "000000"), services.ErrInvalidMFACode)
+	assert.ErrorIs(t, mfa.Verify(db, user.ID, "000000"), services.ErrInvalidMFACode)
+	assert.NoError(t, mfa.Verify(db, user.ID, recoveryCodes[0]), "an accepted code starts the count over")
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, mfa.Verify(db, user.ID, "000000"), services.ErrInvalidMFACode)
+	}
+	assert.ErrorIs(t, mfa.Verify(db, user.ID, recoveryCodes[1]), services.ErrMFALocked, "valid codes are refused during the lockout")
+	assert.ErrorIs(t, mfa.Disable(db, user.ID, recoveryCodes[1]), services.ErrMFALocked)
+
+	require.NoError(t, attempts.Reset(context.Background(), user.ID))
+	assert.NoError(t, mfa.Verify(db, user.ID, recoveryCodes[1]), "the code was not used up while locked")
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRecipient struct {
+	UserID uuid.UUID
+	Email  string
+}
+
+// Notifier delivers a notification to one recipient over a single channel.
+// Every channel shares the same fan-out path in NotificationService.Dispatch.
+type Notifier interface {
+	Channel() string
+	Notify(db *gorm.DB, recipient NotificationRecipient, notification models.Notification) error
+}
+
+type NotificationService interface {
+	Dispatch(db *gorm.DB, recipients []NotificationRecipient, notification models.Notification) error
+
+	GetNotifications(db *gorm.DB, userID uuid.UUID, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error)
+	CountUnread(db *gorm.DB, userID uuid.UUID) (int64, error)
+	MarkRead(db *gorm.DB, userID, notificationID uuid.UUID) error
+	MarkAllRead(db *gorm.DB, userID uuid.UUID) (int64, error)
+	DeleteOlderThan(db *gorm.DB, cutoff time.Time) (int64, error)
+
+	GetNotificationPreferences(db *gorm.DB, userID uuid.UUID) ([]models.NotificationPreference, error)
+	SetNotificationPreference(db *gorm.DB, pref models.NotificationPreference) error
+}
+
+type NotificationServiceImpl struct {
+	notifiers []Notifier
+}
+
+func NewNotificationService(notifiers ...Notifier) *NotificationServiceImpl {
+	return &NotificationServiceImpl{notifiers: notifiers}
+}
+
+// Dispatch sends the notification to every recipient on every channel the
+// recipient has not disabled for the notification type. A failing channel does
+// not stop delivery on the others; all failures are returned together.
+func (s *NotificationServiceImpl) Dispatch(db *gorm.DB, recipients []NotificationRecipient, notification models.Notification) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(recipients))
+	for _, r := range recipients {
+		userIDs = append(userIDs, r.UserID)
+	}
+
+	var prefs []models.NotificationPreference
+	if err := db.Where("user_id IN ? AND event_type = ?", userIDs, notification.Type).Find(&prefs).Error; err != nil {
+		return fmt.Errorf("failed to load notification preferences: %w", err)
+	}
+
+	prefByUser := make(map[uuid.UUID]models.NotificationPreference, len(prefs))
+	for _, p := range prefs {
+		prefByUser[p.UserID] = p
+	}
+
+	var errs []error
+	for _, recipient := range recipients {
+		pref, hasPref := prefByUser[recipient.UserID]
+
+		for _, notifier := range s.notifiers {
+			if hasPref && !pref.ChannelEnabled(notifier.Channel()) {
+				continue
+			}
+
+			if err := notifier.Notify(db, recipient, notification); err != nil {
+				errs = append(errs, fmt.Errorf("%s delivery to %s failed: %w", notifier.Channel(), recipient.UserID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *NotificationServiceImpl) GetNotifications(db *gorm.DB, userID uuid.UUID, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+func (s *NotificationServiceImpl) CountUnread(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (s *NotificationServiceImpl) MarkRead(db *gorm.DB, userID, notificationID uuid.UUID) error {
+	var notification models.Notification
+	if err := db.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		return err
+	}
+
+	if notification.IsRead() {
+		return nil
+	}
+
+	return db.Model(&models.Notification{}).
+		Where("id = ?", notificationID).
+		Update("read_at", time.Now()).Error
+}
+
+func (s *NotificationServiceImpl) MarkAllRead(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	result := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (s *NotificationServiceImpl) DeleteOlderThan(db *gorm.DB, cutoff time.Time) (int64, error) {
+	result := db.Where("created_at < ?", cutoff).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *NotificationServiceImpl) GetNotificationPreferences(db *gorm.DB, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := db.Where("user_id = ?", userID).Order("event_type").Find(&prefs).Error
+	return prefs, err
+}
+
+func (s *NotificationServiceImpl) SetNotificationPreference(db *gorm.DB, pref models.NotificationPreference) error {
+	now := time.Now()
+	pref.CreatedAt = now
+	pref.UpdatedAt = now
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email_enabled", "in_app_enabled", "updated_at"}),
+	}).Create(&pref).Error
+}
+
+// InAppNotifier stores notifications for the notification center.
+type InAppNotifier struct{}
+
+func NewInAppNotifier() *InAppNotifier {
+	return &InAppNotifier{}
+}
+
+func (n *InAppNotifier) Channel() string {
+	return models.NotificationChannelInApp
+}
+
+func (n *InAppNotifier) Notify(db *gorm.DB, recipient NotificationRecipient, notification models.Notification) error {
+	notification.ID = uuid.Must(uuid.NewV4())
+	notification.UserID = recipient.UserID
+	notification.ReadAt = nil
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	return db.Create(&notification).Error
+}
+
+// EmailNotifier hands notifications to the worker as email jobs.
+type EmailNotifier struct {
+	queue *worker.JobQueue
+}
+
+func NewEmailNotifier(queue *worker.JobQueue) *EmailNotifier {
+	return &EmailNotifier{queue: queue}
+}
+
+func (n *EmailNotifier) Channel() string {
+	return models.NotificationChannelEmail
+}
+
+func (n *EmailNotifier) Notify(db *gorm.DB, recipient NotificationRecipient, notification models.Notification) error {
+	if recipient.Email == "" {
+		return nil
+	}
+
+	if n.queue == nil {
+		log.Printf("⚠️  Job queue unavailable, skipping %s email to %s", notification.Type, recipient.UserID)
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"type":  notification.Type,
+		"title": notification.Title,
+		"body":  notification.Body,
+	}
+	if notification.ResourceID != nil {
+		data["resource_type"] = notification.ResourceType
+		data["resource_id"] = notification.ResourceID.String()
+	}
+
+	payload := map[string]interface{}{
+		"user_id":  recipient.UserID.String(),
+		"email":    recipient.Email,
+		"template": EmailTemplateNotification,
+		"subject":  notification.Title,
+		"data":     data,
+	}
+
+	return n.queue.Enqueue(notificationQueue, worker.JobTypeEmailNotification, payload)
+}
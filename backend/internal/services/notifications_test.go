@@ -0,0 +1,179 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupNotificationTestDB(t *testing.T) *gorm.DB {
+	db := openTestDB(t, "users", "task_watchers")
+
+	require.NoError(t, db.Exec(`CREATE TABLE notification_preferences (
+		user_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		email_enabled BOOLEAN NOT NULL DEFAULT true,
+		in_app_enabled BOOLEAN NOT NULL DEFAULT true,
+		created_at DATETIME,
+		updated_at DATETIME,
+		PRIMARY KEY (user_id, event_type)
+	)`).Error)
+
+	require.NoError(t, db.Exec(`CREATE TABLE notifications (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT,
+		resource_type TEXT,
+		resource_id TEXT,
+		data TEXT,
+		read_at DATETIME,
+		created_at DATETIME
+	)`).Error)
+
+	require.NoError(t, db.Exec(`CREATE TABLE digest_preferences (
+		user_id TEXT PRIMARY KEY,
+		frequency TEXT NOT NULL DEFAULT 'off',
+		timezone TEXT NOT NULL DEFAULT 'UTC',
+		hour INTEGER NOT NULL DEFAULT 8,
+		weekday INTEGER NOT NULL DEFAULT 1,
+		period_end DATETIME NOT NULL,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`).Error)
+
+	return db
+}
+
+type recordingNotifier struct {
+	channel   string
+	err       error
+	delivered []uuid.UUID
+}
+
+func (n *recordingNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *recordingNotifier) Notify(db *gorm.DB, recipient services.NotificationRecipient, notification models.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.delivered = append(n.delivered, recipient.UserID)
+	return nil
+}
+
+func TestNotificationService_DispatchHonoursChannelPreferences(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	email := &recordingNotifier{channel: models.NotificationChannelEmail}
+	inApp := &recordingNotifier{channel: models.NotificationChannelInApp}
+	svc := services.NewNotificationService(inApp, email)
+
+	optedOut := uuid.Must(uuid.NewV4())
+	defaults := uuid.Must(uuid.NewV4())
+	require.NoError(t, svc.SetNotificationPreference(db, models.NotificationPreference{
+		UserID:       optedOut,
+		EventType:    models.TaskEventUpdated,
+		EmailEnabled: false,
+		InAppEnabled: false,
+	}))
+
+	err := svc.Dispatch(db, []services.NotificationRecipient{
+		{UserID: optedOut, Email: "out@example.com"},
+		{UserID: defaults, Email: "in@example.com"},
+	}, models.Notification{Type: models.TaskEventUpdated, Title: "Updated"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{defaults}, email.delivered)
+	assert.Equal(t, []uuid.UUID{defaults}, inApp.delivered)
+}
+
+func TestNotificationService_DispatchContinuesAfterChannelFailure(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	failing := &recordingNotifier{channel: "webhook", err: errors.New("receiver down")}
+	inApp := &recordingNotifier{channel: models.NotificationChannelInApp}
+	svc := services.NewNotificationService(failing, inApp)
+
+	userID := uuid.Must(uuid.NewV4())
+	err := svc.Dispatch(db, []services.NotificationRecipient{{UserID: userID}},
+		models.Notification{Type: models.TaskEventCreated, Title: "Created"})
+
+	assert.Error(t, err)
+	assert.Equal(t, []uuid.UUID{userID}, inApp.delivered)
+}
+
+func TestNotificationService_ReadState(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	svc := services.NewNotificationService(services.NewInAppNotifier())
+
+	userID := uuid.Must(uuid.NewV4())
+	otherID := uuid.Must(uuid.NewV4())
+	recipients := []services.NotificationRecipient{{UserID: userID}}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, svc.Dispatch(db, recipients, models.Notification{
+			Type:  models.TaskEventUpdated,
+			Title: "Task updated",
+		}))
+	}
+
+	notifications, total, err := svc.GetNotifications(db, userID, false, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, notifications, 2)
+
+	require.NoError(t, svc.MarkRead(db, userID, notifications[0].ID))
+	assert.ErrorIs(t, svc.MarkRead(db, otherID, notifications[1].ID), gorm.ErrRecordNotFound)
+
+	unread, err := svc.CountUnread(db, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), unread)
+
+	_, unreadTotal, err := svc.GetNotifications(db, userID, true, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), unreadTotal)
+
+	updated, err := svc.MarkAllRead(db, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+
+	unread, err = svc.CountUnread(db, userID)
+	require.NoError(t, err)
+	assert.Zero(t, unread)
+}
+
+func TestNotificationService_DeleteOlderThan(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	svc := services.NewNotificationService(services.NewInAppNotifier())
+
+	userID := uuid.Must(uuid.NewV4())
+	recipients := []services.NotificationRecipient{{UserID: userID}}
+
+	require.NoError(t, svc.Dispatch(db, recipients, models.Notification{
+		Type:      models.TaskEventUpdated,
+		Title:     "Old",
+		CreatedAt: time.Now().Add(-100 * 24 * time.Hour),
+	}))
+	require.NoError(t, svc.Dispatch(db, recipients, models.Notification{
+		Type:  models.TaskEventUpdated,
+		Title: "Recent",
+	}))
+
+	deleted, err := svc.DeleteOlderThan(db, time.Now().Add(-90*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	notifications, _, err := svc.GetNotifications(db, userID, false, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "Recent", notifications[0].Title)
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxOptions tunes the relay. Messages that fail MaxAttempts times stay in
+// the table unpublished so they can be inspected and replayed by hand.
+type OutboxOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	ClaimTimeout time.Duration
+}
+
+// OutboxHandler publishes one message. A returned error leaves the message
+// pending and it is retried with backoff, so handlers may see it again.
+type OutboxHandler func(db *gorm.DB, message models.OutboxMessage) error
+
+// WriteOutbox records an event in db, which should be the transaction making
+// the change the event describes. The message ID doubles as the dedupe ID.
+func WriteOutbox(db *gorm.DB, aggregateType string, aggregateID uuid.UUID, eventType string, payload interface{}) (uuid.UUID, error) {
+	id := uuid.Must(uuid.NewV4())
+
+	if event, ok := payload.(*models.TaskEvent); ok {
+		event.EventID = id
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	now := time.Now()
+	message := models.OutboxMessage{
+		ID:            id,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       string(body),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := db.Create(&message).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to write outbox message: %w", err)
+	}
+	return id, nil
+}
+
+// TaskEventOutbox is a TaskEventPublisher that records events in the outbox
+// instead of publishing them. Pass the write's transaction as db.
+type TaskEventOutbox struct{}
+
+func NewTaskEventOutbox() *TaskEventOutbox {
+	return &TaskEventOutbox{}
+}
+
+func (o *TaskEventOutbox) PublishTaskEvent(db *gorm.DB, event models.TaskEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	_, err := WriteOutbox(db, models.OutboxAggregateTask, event.TaskID, event.Type, &event)
+	return err
+}
+
+// OutboxRelay polls the outbox and publishes pending messages, oldest first.
+// Delivery is at-least-once: a crash after publishing but before a message is
+// marked published sends it again once its claim times out.
+type OutboxRelay struct {
+	db       *gorm.DB
+	options  OutboxOptions
+	handlers map[string]OutboxHandler
+	mu       sync.RWMutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewOutboxRelay(db *gorm.DB, options OutboxOptions) *OutboxRelay {
+	if options.PollInterval <= 0 {
+		options.PollInterval = time.Second
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 100
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 10
+	}
+	if options.ClaimTimeout <= 0 {
+		options.ClaimTimeout = time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OutboxRelay{
+		db:       db,
+		options:  options,
+		handlers: make(map[string]OutboxHandler),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Handle registers the handler for an aggregate type. Messages for aggregates
+// without a handler are marked published since nothing consumes them.
+func (r *OutboxRelay) Handle(aggregateType string, handler OutboxHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[aggregateType] = handler
+}
+
+func (r *OutboxRelay) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.options.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			// Keep draining while full batches come back so a backlog clears
+			// without waiting a poll interval per batch.
+			for {
+				n, err := r.RelayPending(r.ctx)
+				if err != nil {
+					log.Printf("⚠️  Outbox relay failed: %v", err)
+				}
+				if err != nil || n < r.options.BatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r *OutboxRelay) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// RelayPending publishes one batch of due messages and reports how many it
+// claimed. Claiming pushes next_attempt_at out by ClaimTimeout, so a relay
+// that dies mid-batch leaves its messages to be retried rather than lost. On
+// Postgres the claim uses SKIP LOCKED so several instances can relay at once.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	db := r.db.WithContext(ctx)
+
+	var messages []models.OutboxMessage
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		query := tx.Where("published_at IS NULL AND attempts < ? AND next_attempt_at <= ?", r.options.MaxAttempts, now).
+			Order("created_at ASC").
+			Limit(r.options.BatchSize)
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := query.Find(&messages).Error; err != nil {
+			return fmt.Errorf("failed to load outbox messages: %w", err)
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+		}
+		return tx.Model(&models.OutboxMessage{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(r.options.ClaimTimeout)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		if err := r.publish(db, message); err != nil {
+			log.Printf("⚠️  Outbox message %s (%s) failed: %v", message.ID, message.EventType, err)
+			if err := r.recordFailure(db, message, err); err != nil {
+				return len(messages), err
+			}
+		}
+	}
+
+	return len(messages), nil
+}
+
+func (r *OutboxRelay) publish(db *gorm.DB, message models.OutboxMessage) error {
+	r.mu.RLock()
+	handler := r.handlers[message.AggregateType]
+	r.mu.RUnlock()
+
+	if handler != nil {
+		if err := handler(db, message); err != nil {
+			return err
+		}
+	}
+
+	return db.Model(&models.OutboxMessage{}).
+		Where("id = ?", message.ID).
+		Update("published_at", time.Now()).Error
+}
+
+func (r *OutboxRelay) recordFailure(tx *gorm.DB, message models.OutboxMessage, cause error) error {
+	attempts := message.Attempts + 1
+	backoff := time.Duration(1<<min(attempts, 10)) * time.Second
+
+	return tx.Model(&models.OutboxMessage{}).
+		Where("id = ?", message.ID).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      cause.Error(),
+			"next_attempt_at": time.Now().Add(backoff),
+		}).Error
+}
+
+// DeletePublishedOutbox removes messages published before cutoff.
+func DeletePublishedOutbox(db *gorm.DB, cutoff time.Time) (int64, error) {
+	result := db.Where("published_at IS NOT NULL AND published_at < ?", cutoff).
+		Delete(&models.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
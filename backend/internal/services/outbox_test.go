@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupOutboxTestDB(t *testing.T) *gorm.DB {
+	return openTestDB(t, "outbox", "users")
+}
+
+type taskEventRecorder struct {
+	events []models.TaskEvent
+	err    error
+}
+
+func (r *taskEventRecorder) PublishTaskEvent(db *gorm.DB, event models.TaskEvent) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.events = append(r.events, event)
+	return nil
+}
+
+func newTaskEventRelay(t *testing.T, db *gorm.DB, options services.OutboxOptions, publisher services.TaskEventPublisher) *services.OutboxRelay {
+	bus := services.NewEventBus(1, 0)
+	t.Cleanup(bus.Close)
+	bus.SubscribeTaskEvents(publisher, false)
+
+	relay := services.NewOutboxRelay(db, options)
+	relay.Handle(models.OutboxAggregateTask, services.NewEventBusOutboxHandler(bus))
+	return relay
+}
+
+func TestOutboxRelay_PublishesCommittedEventsOnce(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	outbox := services.NewTaskEventOutbox()
+
+	taskID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return outbox.PublishTaskEvent(tx, models.TaskEvent{Type: models.TaskEventCreated, TaskID: taskID, Title: "Write report"})
+	}))
+
+	// A rolled-back write must not leave its event behind.
+	rollback := errors.New("rollback")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, outbox.PublishTaskEvent(tx, models.TaskEvent{Type: models.TaskEventDeleted, TaskID: taskID}))
+		return rollback
+	})
+	require.ErrorIs(t, err, rollback)
+
+	recorder := &taskEventRecorder{}
+	relay := newTaskEventRelay(t, db, services.OutboxOptions{}, recorder)
+
+	n, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, recorder.events, 1)
+	event := recorder.events[0]
+	assert.Equal(t, models.TaskEventCreated, event.Type)
+	assert.Equal(t, "Write report", event.Title)
+	assert.False(t, event.OccurredAt.IsZero())
+
+	var message models.OutboxMessage
+	require.NoError(t, db.First(&message).Error)
+	assert.Equal(t, message.ID, event.EventID)
+	assert.NotNil(t, message.PublishedAt)
+
+	n, err = relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Len(t, recorder.events, 1)
+}
+
+func TestOutboxRelay_RetriesFailedMessages(t *testing.T) {
+	db := setupOutboxTestDB(t)
+
+	require.NoError(t, services.NewTaskEventOutbox().PublishTaskEvent(db, models.TaskEvent{
+		Type:   models.TaskEventUpdated,
+		TaskID: uuid.Must(uuid.NewV4()),
+	}))
+
+	recorder := &taskEventRecorder{err: errors.New("queue down")}
+	relay := newTaskEventRelay(t, db, services.OutboxOptions{MaxAttempts: 2}, recorder)
+
+	_, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+
+	var message models.OutboxMessage
+	require.NoError(t, db.First(&message).Error)
+	assert.Nil(t, message.PublishedAt)
+	assert.Equal(t, 1, message.Attempts)
+	assert.Equal(t, "queue down", message.LastError)
+	assert.True(t, message.NextAttemptAt.After(time.Now()), "failed messages back off")
+
+	n, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "message is not retried before its backoff elapses")
+
+	recorder.err = nil
+	require.NoError(t, db.Model(&message).Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+
+	n, err = relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, recorder.events, 1)
+
+	deleted, err := services.DeletePublishedOutbox(db, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestUserService_WritesOutboxWithChange(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	userService := services.NewUserService()
+
+	userID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec("INSERT INTO users (id, username) VALUES (?, 'alice')", userID).Error)
+
+	require.NoError(t, userService.UpdateUser(db, userID, map[string]interface{}{"username": "alice2"}))
+	require.NoError(t, userService.DeleteUser(db, userID))
+
+	// A missing user changes nothing, so no event is recorded.
+	require.ErrorIs(t, userService.DeleteUser(db, uuid.Must(uuid.NewV4())), gorm.ErrRecordNotFound)
+
+	var messages []models.OutboxMessage
+	require.NoError(t, db.Order("created_at ASC").Find(&messages).Error)
+	require.Len(t, messages, 2)
+	assert.Equal(t, models.UserEventUpdated, messages[0].EventType)
+	assert.JSONEq(t, `{"user_id":"`+userID.String()+`","fields":["username"]}`, messages[0].Payload)
+	assert.Equal(t, models.UserEventDeleted, messages[1].EventType)
+	assert.Equal(t, userID, messages[1].AggregateID)
+}
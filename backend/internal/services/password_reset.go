@@ -0,0 +1,181 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	passwordResetTokenPrefix = "pwr_"
+
+	DefaultPasswordResetTTL         = time.Hour
+	DefaultPasswordResetResendDelay = 2 * time.Minute
+)
+
+var (
+	// ErrInvalidResetToken covers unknown, expired and used tokens alike.
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	// ErrPasswordResetThrottled means a reset link went to the address
+	// recently, so no new one was issued.
+	ErrPasswordResetThrottled = errors.New("a password reset email was sent recently")
+)
+
+type PasswordResetService interface {
+	// RequestReset issues a reset token for the active user with the email.
+	// It returns a nil user and no error when there is no such user, so
+	// callers can answer the same either way. It returns
+	// ErrPasswordResetThrottled while the user's last token is within the
+	// resend delay.
+	RequestReset(db *gorm.DB, email string) (*models.User, string, error)
+	// ResetPassword sets the password of the token's user, uses up the
+	// token and revokes the user's refresh tokens. The password must
+	// already be validated.
+	ResetPassword(db *gorm.DB, token, password string) (*models.User, error)
+	TTL() time.Duration
+}
+
+type PasswordResetServiceImpl struct {
+	ttl         time.Duration
+	resendDelay time.Duration
+	bcryptCost  int
+}
+
+// NewPasswordResetService issues reset links valid for ttl, at most one per
+// user every resendDelay so the address cannot be flooded.
+func NewPasswordResetService(ttl, resendDelay time.Duration, bcryptCost int) *PasswordResetServiceImpl {
+	if ttl <= 0 {
+		ttl = DefaultPasswordResetTTL
+	}
+	if resendDelay < 0 {
+		resendDelay = DefaultPasswordResetResendDelay
+	}
+	if bcryptCost < bcrypt.MinCost {
+		bcryptCost = bcrypt.DefaultCost
+	}
+	return &PasswordResetServiceImpl{ttl: ttl, resendDelay: resendDelay, bcryptCost: bcryptCost}
+}
+
+func (s *PasswordResetServiceImpl) TTL() time.Duration {
+	return s.ttl
+}
+
+func (s *PasswordResetServiceImpl) RequestReset(db *gorm.DB, email string) (*models.User, string, error) {
+	var user models.User
+	email = strings.ToLower(strings.TrimSpace(email))
+	err := db.Where("email = ? AND is_active = ?", email, true).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := passwordResetTokenPrefix + hex.EncodeToString(buf)
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var recent int64
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND created_at > ?", user.ID, now.Add(-s.resendDelay)).
+			Count(&recent).Error; err != nil {
+			return err
+		}
+		if recent > 0 {
+			return ErrPasswordResetThrottled
+		}
+
+		// Only the newest link works.
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			ID:        uuid.Must(uuid.NewV4()),
+			UserID:    user.ID,
+			TokenHash: hashResetToken(token),
+			ExpiresAt: now.Add(s.ttl),
+		}).Error
+	})
+	if errors.Is(err, ErrPasswordResetThrottled) {
+		return nil, "", err
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to save reset token: %w", err)
+	}
+	return &user, token, nil
+}
+
+func (s *PasswordResetServiceImpl) ResetPassword(db *gorm.DB, token, password string) (*models.User, error) {
+	if !strings.HasPrefix(token, passwordResetTokenPrefix) {
+		return nil, ErrInvalidResetToken
+	}
+
+	var user models.User
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var reset models.PasswordResetToken
+		err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashResetToken(token), now).
+			First(&reset).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		// Marking the token used in the same statement that checks it keeps
+		// two concurrent resets from both succeeding.
+		used := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", now)
+		if used.Error != nil {
+			return used.Error
+		}
+		if used.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+
+		if err := tx.Where("id = ? AND is_active = ?", reset.UserID, true).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("password", string(hash)).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.Token{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// hashResetToken hashes a token for lookup. Tokens carry 256 random bits, so
+// a fast hash is enough.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
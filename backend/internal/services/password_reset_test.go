@@ -0,0 +1,112 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func setupPasswordResetTestDB(t *testing.T) (*gorm.DB, uuid.UUID) {
+	db := openTestDB(t, "users", "tokens")
+	require.NoError(t, db.Exec(`CREATE TABLE password_reset_tokens (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		expires_at DATETIME NOT NULL,
+		used_at DATETIME,
+		created_at DATETIME
+	)`).Error)
+	return db, createTestUser(t, db, "alice")
+}
+
+func TestPasswordResetService_ResetsOnce(t *testing.T) {
+	db, userID := setupPasswordResetTestDB(t)
+	resets := services.NewPasswordResetService(time.Hour, 0, bcrypt.MinCost)
+
+	user, _, err := resets.RequestReset(db, "nobody@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, user, "unknown addresses are not an error")
+
+	first, firstToken, err := resets.RequestReset(db, " Alice@Example.com ")
+	require.NoError(t, err)
+	assert.Equal(t, userID, first.ID)
+	assert.True(t, strings.HasPrefix(firstToken, "pwr_"))
+	_, token, err := resets.RequestReset(db, "alice@example.com")
+	require.NoError(t, err)
+
+	var stored []models.PasswordResetToken
+	require.NoError(t, db.Order("created_at").Find(&stored).Error)
+	require.Len(t, stored, 2)
+	assert.NotContains(t, []string{stored[0].TokenHash, stored[1].TokenHash}, token, "only hashes are stored")
+
+	_, err = resets.ResetPassword(db, firstToken, "N3w-passw0rd!")
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken, "a newer request replaces older links")
+
+	for _, jti := range []uuid.UUID{uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())} {
+		require.NoError(t, db.Create(&models.Token{ID: uuid.Must(uuid.NewV4()), UserId: userID, JTI: jti, RefreshToken: "r", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	}
+
+	reset, err := resets.ResetPassword(db, token, "N3w-passw0rd!")
+	require.NoError(t, err)
+	assert.Equal(t, userID, reset.ID)
+
+	var password string
+	require.NoError(t, db.Raw("SELECT password FROM users WHERE id = ?", userID).Scan(&password).Error)
+	assert.True(t, services.VerifyPassword(password, "N3w-passw0rd!"))
+
+	var refreshTokens int64
+	require.NoError(t, db.Model(&models.Token{}).Where("user_id = ?", userID).Count(&refreshTokens).Error)
+	assert.Zero(t, refreshTokens, "existing sessions are revoked")
+
+	_, err = resets.ResetPassword(db, token, "An0ther-pass!")
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken, "tokens work once")
+	_, err = resets.ResetPassword(db, "pwr_unknown", "An0ther-pass!")
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken)
+}
+
+func TestPasswordResetService_Expiry(t *testing.T) {
+	db, _ := setupPasswordResetTestDB(t)
+	resets := services.NewPasswordResetService(time.Hour, 0, bcrypt.MinCost)
+
+	_, token, err := resets.RequestReset(db, "alice@example.com")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.PasswordResetToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	_, err = resets.ResetPassword(db, token, "N3w-passw0rd!")
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken)
+}
+
+func TestPasswordResetService_ThrottlesPerUser(t *testing.T) {
+	db, userID := setupPasswordResetTestDB(t)
+	bob := createTestUser(t, db, "bob")
+	resets := services.NewPasswordResetService(time.Hour, time.Hour, bcrypt.MinCost)
+
+	user, token, err := resets.RequestReset(db, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, userID, user.ID)
+
+	user, _, err = resets.RequestReset(db, "ALICE@example.com")
+	assert.ErrorIs(t, err, services.ErrPasswordResetThrottled)
+	assert.Nil(t, user)
+
+	user, _, err = resets.RequestReset(db, "bob@example.com")
+	require.NoError(t, err, "other addresses are not held up")
+	assert.Equal(t, bob, user.ID)
+
+	_, err = resets.ResetPassword(db, token, "N3w-passw0rd!")
+	require.NoError(t, err, "the link already sent still works")
+
+	require.NoError(t, db.Model(&models.PasswordResetToken{}).Where("user_id = ?", userID).
+		Update("created_at", time.Now().Add(-2*time.Hour)).Error)
+	_, _, err = resets.RequestReset(db, "alice@example.com")
+	assert.NoError(t, err, "a new link can be sent once the delay has passed")
+}
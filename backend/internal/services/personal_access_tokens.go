@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, telling them
+// apart from JWTs in the Authorization header.
+const PersonalAccessTokenPrefix = "pat_"
+
+// accessTokenLastUsedInterval limits how often authenticating with a token
+// writes its last-used time.
+const accessTokenLastUsedInterval = time.Minute
+
+var (
+	// ErrInvalidAccessToken covers unknown, expired and revoked tokens alike.
+	ErrInvalidAccessToken        = errors.New("invalid or expired access token")
+	ErrInvalidAccessTokenRequest = errors.New("invalid access token request")
+	ErrAccessTokenNotFound       = errors.New("access token not found")
+	ErrScopeNotGranted           = errors.New("scope is not among the user's permissions")
+)
+
+type CreateAccessTokenRequest struct {
+	Name   string
+	Scopes []string
+	// ExpiresAt is optional; tokens without it last until revoked.
+	ExpiresAt *time.Time
+}
+
+// APITokenIdentity is who a personal access token authenticates, and with
+// which permissions: the token's scopes the user still holds.
+type APITokenIdentity struct {
+	TokenID     uuid.UUID
+	UserID      uuid.UUID
+	Permissions []string
+	ExpiresAt   *time.Time
+}
+
+type PersonalAccessTokenService interface {
+	// Create issues a token for the user and returns it with its secret, which
+	// is not stored and cannot be shown again.
+	Create(db *gorm.DB, userID uuid.UUID, req CreateAccessTokenRequest) (*models.PersonalAccessToken, string, error)
+	List(db *gorm.DB, userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	Revoke(db *gorm.DB, userID, tokenID uuid.UUID) error
+	// Authenticate resolves a token presented by a client and records its use.
+	Authenticate(db *gorm.DB, token string) (*APITokenIdentity, error)
+}
+
+type PersonalAccessTokenServiceImpl struct{}
+
+func NewPersonalAccessTokenService() PersonalAccessTokenService {
+	return &PersonalAccessTokenServiceImpl{}
+}
+
+func (s *PersonalAccessTokenServiceImpl) Create(db *gorm.DB, userID uuid.UUID, req CreateAccessTokenRequest) (*models.PersonalAccessToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", fmt.Errorf("%w: name must be between 1 and 100 characters", ErrInvalidAccessTokenRequest)
+	}
+	if len(req.Scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidAccessTokenRequest)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expiry must be in the future", ErrInvalidAccessTokenRequest)
+	}
+
+	granted, err := userPermissions(db, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	held := make(map[string]bool, len(granted))
+	for _, permission := range granted {
+		held[permission] = true
+	}
+	requested := make(map[string]bool, len(req.Scopes))
+	scopes := make(models.StringList, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !held[scope] {
+			return nil, "", fmt.Errorf("%w: %q", ErrScopeNotGranted, scope)
+		}
+		if !requested[scope] {
+			requested[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	secret := PersonalAccessTokenPrefix + hex.EncodeToString(buf)
+
+	token := &models.PersonalAccessToken{
+		ID:          uuid.Must(uuid.NewV4()),
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   hashAccessToken(secret),
+		TokenPrefix: secret[:len(PersonalAccessTokenPrefix)+8],
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   time.Now(),
+	}
+	if err := db.Create(token).Error; err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+func (s *PersonalAccessTokenServiceImpl) List(db *gorm.DB, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := db.Where("user_id = ? AND revoked_at IS NULL", userID).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (s *PersonalAccessTokenServiceImpl) Revoke(db *gorm.DB, userID, tokenID uuid.UUID) error {
+	result := db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+func (s *PersonalAccessTokenServiceImpl) Authenticate(db *gorm.DB, token string) (*APITokenIdentity, error) {
+	if !strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	now := time.Now()
+	var stored models.PersonalAccessToken
+	err := db.Joins("JOIN users ON users.id = personal_access_tokens.user_id").
+		Where("personal_access_tokens.token_hash = ? AND personal_access_tokens.revoked_at IS NULL", hashAccessToken(token)).
+		Where("personal_access_tokens.expires_at IS NULL OR personal_access_tokens.expires_at > ?", now).
+		Where("users.is_active = ? AND users.deleted_at IS NULL", true).
+		First(&stored).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAccessToken
+		}
+		return nil, err
+	}
+
+	// Permissions the user lost since the token was created are dropped.
+	granted, err := userPermissions(db, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[string]bool, len(granted))
+	for _, permission := range granted {
+		held[permission] = true
+	}
+	permissions := make([]string, 0, len(stored.Scopes))
+	for _, scope := range stored.Scopes {
+		if held[scope] {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	if err := db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", stored.ID, now.Add(-accessTokenLastUsedInterval)).
+		Update("last_used_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	return &APITokenIdentity{
+		TokenID:     stored.ID,
+		UserID:      stored.UserID,
+		Permissions: permissions,
+		ExpiresAt:   stored.ExpiresAt,
+	}, nil
+}
+
+// hashAccessToken hashes a token for lookup. Tokens carry 256 random bits, so
+// a fast hash is enough.
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type tokenScopesContextKey struct{}
+
+// WithTokenScopes marks ctx as authenticated by a personal access token
+// limited to scopes. AuthorizationService then only grants those permissions,
+// and no roles.
+func WithTokenScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, tokenScopesContextKey{}, scopes)
+}
+
+// TokenScopesFromContext returns the scopes set by WithTokenScopes.
+func TokenScopesFromContext(ctx context.Context) ([]string, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	scopes, ok := ctx.Value(tokenScopesContextKey{}).([]string)
+	return scopes, ok
+}
+
+// scopeAllows reports whether the request's token, if any, covers
+// resource:action.
+func scopeAllows(ctx context.Context, resource, action string) bool {
+	scopes, ok := TokenScopesFromContext(ctx)
+	if !ok {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope == resource+":"+action {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenAuthenticator checks personal access tokens for AuthzMiddleware.
+type APITokenAuthenticator struct {
+	db     *gorm.DB
+	tokens PersonalAccessTokenService
+}
+
+func NewAPITokenAuthenticator(db *gorm.DB, tokens PersonalAccessTokenService) *APITokenAuthenticator {
+	return &APITokenAuthenticator{db: db, tokens: tokens}
+}
+
+func (a *APITokenAuthenticator) AuthenticateAPIToken(ctx context.Context, token string) (*APITokenIdentity, error) {
+	return a.tokens.Authenticate(a.db.WithContext(ctx), token)
+}
@@ -0,0 +1,128 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupAccessTokenTestDB(t *testing.T) (*gorm.DB, uuid.UUID) {
+	db := openTestDB(t, "users", "roles", "permissions", "role_permissions", "user_roles")
+	userID := createTestUser(t, db, "alice")
+	require.NoError(t, db.Exec(`CREATE TABLE personal_access_tokens (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		token_prefix TEXT NOT NULL,
+		scopes TEXT NOT NULL,
+		expires_at DATETIME,
+		last_used_at DATETIME,
+		revoked_at DATETIME,
+		created_at DATETIME
+	)`).Error)
+
+	roleID := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec(`INSERT INTO roles (id, name) VALUES (?, 'user')`, roleID).Error)
+	require.NoError(t, db.Exec(`INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)`, userID, roleID).Error)
+	for _, action := range []string{"read", "update"} {
+		permissionID := uuid.Must(uuid.NewV4())
+		require.NoError(t, db.Exec(`INSERT INTO permissions (id, resource, action) VALUES (?, 'tasks', ?)`, permissionID, action).Error)
+		require.NoError(t, db.Exec(`INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?)`, roleID, permissionID).Error)
+	}
+	return db, userID
+}
+
+func TestPersonalAccessTokenService_CreateAndAuthenticate(t *testing.T) {
+	db, userID := setupAccessTokenTestDB(t)
+	tokens := services.NewPersonalAccessTokenService()
+
+	_, _, err := tokens.Create(db, userID, services.CreateAccessTokenRequest{Name: "ci", Scopes: []string{"tasks:delete"}})
+	assert.ErrorIs(t, err, services.ErrScopeNotGranted, "scopes are limited to the user's permissions")
+	_, _, err = tokens.Create(db, userID, services.CreateAccessTokenRequest{Name: " ", Scopes: []string{"tasks:read"}})
+	assert.ErrorIs(t, err, services.ErrInvalidAccessTokenRequest)
+
+	expiry := time.Now().Add(24 * time.Hour)
+	created, secret, err := tokens.Create(db, userID, services.CreateAccessTokenRequest{
+		Name:      "ci",
+		Scopes:    []string{"tasks:update", "tasks:read", "tasks:read"},
+		ExpiresAt: &expiry,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, services.PersonalAccessTokenPrefix))
+	assert.True(t, strings.HasPrefix(secret, created.TokenPrefix))
+	assert.Equal(t, models.StringList{"tasks:read", "tasks:update"}, created.Scopes)
+
+	var stored models.PersonalAccessToken
+	require.NoError(t, db.First(&stored, "id = ?", created.ID).Error)
+	assert.NotContains(t, stored.TokenHash, secret, "only a hash is stored")
+	assert.Nil(t, stored.LastUsedAt)
+
+	identity, err := tokens.Authenticate(db, secret)
+	require.NoError(t, err)
+	assert.Equal(t, userID, identity.UserID)
+	assert.Equal(t, []string{"tasks:read", "tasks:update"}, identity.Permissions)
+	require.NoError(t, db.First(&stored, "id = ?", created.ID).Error)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	// Losing a permission narrows the token with it.
+	require.NoError(t, db.Exec(`DELETE FROM permissions WHERE action = 'update'`).Error)
+	identity, err = tokens.Authenticate(db, secret)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tasks:read"}, identity.Permissions)
+
+	_, err = tokens.Authenticate(db, "pat_"+strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, services.ErrInvalidAccessToken)
+
+	assert.ErrorIs(t, tokens.Revoke(db, uuid.Must(uuid.NewV4()), created.ID), services.ErrAccessTokenNotFound)
+	require.NoError(t, tokens.Revoke(db, userID, created.ID))
+	_, err = tokens.Authenticate(db, secret)
+	assert.ErrorIs(t, err, services.ErrInvalidAccessToken)
+
+	list, err := tokens.List(db, userID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestPersonalAccessTokenService_ExpiredTokensAreRejected(t *testing.T) {
+	db, userID := setupAccessTokenTestDB(t)
+	tokens := services.NewPersonalAccessTokenService()
+
+	expiry := time.Now().Add(time.Hour)
+	created, secret, err := tokens.Create(db, userID, services.CreateAccessTokenRequest{Name: "ci", Scopes: []string{"tasks:read"}, ExpiresAt: &expiry})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.PersonalAccessToken{}).Where("id = ?", created.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	_, err = tokens.Authenticate(db, secret)
+	assert.ErrorIs(t, err, services.ErrInvalidAccessToken)
+}
+
+func TestAuthorizationService_TokenScopesLimitPermissions(t *testing.T) {
+	db, userID := setupAccessTokenTestDB(t)
+	authz := services.NewAuthorizationService(db)
+
+	allowed, err := authz.HasPermission(context.Background(), userID, "tasks", "update")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	scoped := services.WithTokenScopes(context.Background(), []string{"tasks:read"})
+	allowed, err = authz.HasPermission(scoped, userID, "tasks", "update")
+	require.NoError(t, err)
+	assert.False(t, allowed, "the token lacks the scope")
+	allowed, err = authz.HasPermission(scoped, userID, "tasks", "read")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	isUser, err := authz.HasRole(scoped, userID, "user")
+	require.NoError(t, err)
+	assert.False(t, isUser, "tokens carry no roles")
+}
@@ -24,10 +24,12 @@ type RegisterService interface {
 	RegisterUser(db *gorm.DB, req RegistrationRequest) (*models.User, error)
 }
 
-type RegisterServiceImpl struct{}
+type RegisterServiceImpl struct {
+	workspaces WorkspaceService
+}
 
 func NewRegisterService() *RegisterServiceImpl {
-	return &RegisterServiceImpl{}
+	return &RegisterServiceImpl{workspaces: NewWorkspaceService()}
 }
 
 func (s *RegisterServiceImpl) RegisterUser(db *gorm.DB, req RegistrationRequest) (*models.User, error) {
@@ -120,6 +122,13 @@ func (s *RegisterServiceImpl) RegisterUser(db *gorm.DB, req RegistrationRequest)
 		}
 	}
 
+	// Every new user starts in a workspace of their own and is invited into
+	// others by their owners.
+	if _, err := s.workspaces.CreateWorkspace(tx, user.Username, user.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
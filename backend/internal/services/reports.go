@@ -0,0 +1,479 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/cache"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReportPeriodDay   = "day"
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+
+	ReportGroupByUser       = "user"
+	ReportGroupByDepartment = "department"
+
+	// maxReportPeriods bounds the number of periods in a throughput report.
+	maxReportPeriods = 366
+)
+
+var ErrInvalidReportQuery = errors.New("invalid report query")
+
+// closedTaskStatuses are the statuses that take a task off everyone's plate.
+var closedTaskStatuses = []string{"completed", "done", "cancelled"}
+
+// completedTaskStatuses are the closed statuses that count as finished work.
+var completedTaskStatuses = []string{"completed", "done"}
+
+// ReportFilter selects the tasks a report covers. Throughput and duration
+// reports use the [From, To) range; Department, when set, limits every report
+// to tasks owned by members of that department.
+type ReportFilter struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Period     string    `json:"period,omitempty"`
+	Department string    `json:"department,omitempty"`
+}
+
+func (f ReportFilter) cacheKey() string {
+	return fmt.Sprintf("%d:%d:%s:%s", f.From.Unix(), f.To.Unix(), f.Period, f.Department)
+}
+
+type ThroughputPoint struct {
+	Period    string `json:"period"`
+	Created   int64  `json:"created"`
+	Completed int64  `json:"completed"`
+}
+
+// DurationBucket counts tasks that took at least MinHours and less than
+// MaxHours. The last bucket has no upper bound and MaxHours is 0.
+type DurationBucket struct {
+	Label    string `json:"label"`
+	MinHours int    `json:"min_hours"`
+	MaxHours int    `json:"max_hours,omitempty"`
+	Count    int64  `json:"count"`
+}
+
+type DurationDistribution struct {
+	Count        int64            `json:"count"`
+	AverageHours float64          `json:"average_hours"`
+	MinHours     float64          `json:"min_hours"`
+	MaxHours     float64          `json:"max_hours"`
+	Buckets      []DurationBucket `json:"buckets"`
+}
+
+// TaskDurationReport covers tasks completed in the filter's range. Lead time
+// runs from creation to completion, cycle time from when work started.
+type TaskDurationReport struct {
+	LeadTime  DurationDistribution `json:"lead_time"`
+	CycleTime DurationDistribution `json:"cycle_time"`
+}
+
+// StatusBreakdown counts tasks per status for one user or department.
+type StatusBreakdown struct {
+	Key    string           `json:"key"`
+	Label  string           `json:"label"`
+	Counts map[string]int64 `json:"counts"`
+	Total  int64            `json:"total"`
+}
+
+type OverdueCount struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// OverdueReport counts open tasks whose due date has passed.
+type OverdueReport struct {
+	Total        int64          `json:"total"`
+	ByUser       []OverdueCount `json:"by_user"`
+	ByDepartment []OverdueCount `json:"by_department"`
+}
+
+type ReportService interface {
+	Throughput(db *gorm.DB, filter ReportFilter) ([]ThroughputPoint, error)
+	TaskDurations(db *gorm.DB, filter ReportFilter) (*TaskDurationReport, error)
+	StatusBreakdown(db *gorm.DB, groupBy string, filter ReportFilter) ([]StatusBreakdown, error)
+	Overdue(db *gorm.DB, filter ReportFilter, now time.Time) (*OverdueReport, error)
+}
+
+type ReportServiceImpl struct{}
+
+func NewReportService() *ReportServiceImpl {
+	return &ReportServiceImpl{}
+}
+
+var durationBuckets = []DurationBucket{
+	{Label: "< 1 day", MinHours: 0, MaxHours: 24},
+	{Label: "1-3 days", MinHours: 24, MaxHours: 72},
+	{Label: "3-7 days", MinHours: 72, MaxHours: 168},
+	{Label: "1-2 weeks", MinHours: 168, MaxHours: 336},
+	{Label: "2-4 weeks", MinHours: 336, MaxHours: 672},
+	{Label: "4+ weeks", MinHours: 672},
+}
+
+// Throughput counts tasks created and completed in each period, oldest first.
+// Periods without activity are included with zero counts.
+func (s *ReportServiceImpl) Throughput(db *gorm.DB, filter ReportFilter) ([]ThroughputPoint, error) {
+	periods, err := reportPeriods(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make(map[string]*ThroughputPoint, len(periods))
+	result := make([]ThroughputPoint, len(periods))
+	for i, period := range periods {
+		result[i].Period = period
+		points[period] = &result[i]
+	}
+
+	type periodCount struct {
+		Period string
+		Count  int64
+	}
+	for _, column := range []string{"created_at", "completed_at"} {
+		expr := reportPeriodExpr(db, "t."+column, filter.Period)
+		var counts []periodCount
+		err := reportTasks(db, filter).
+			Select(expr+" AS period, COUNT(*) AS count").
+			Where("t."+column+" >= ? AND t."+column+" < ?", filter.From, filter.To).
+			Group(expr).
+			Scan(&counts).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count tasks by %s: %w", column, err)
+		}
+
+		for _, count := range counts {
+			point, ok := points[count.Period]
+			if !ok {
+				continue
+			}
+			if column == "created_at" {
+				point.Created = count.Count
+			} else {
+				point.Completed = count.Count
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// TaskDurations summarizes lead and cycle times of tasks completed in range.
+// Tasks that went straight to done without being started have no cycle time.
+func (s *ReportServiceImpl) TaskDurations(db *gorm.DB, filter ReportFilter) (*TaskDurationReport, error) {
+	if err := validateReportRange(filter); err != nil {
+		return nil, err
+	}
+
+	lead, err := durationDistribution(db, filter, "t.created_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute lead time: %w", err)
+	}
+	cycle, err := durationDistribution(db, filter, "t.started_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute cycle time: %w", err)
+	}
+
+	return &TaskDurationReport{LeadTime: *lead, CycleTime: *cycle}, nil
+}
+
+func durationDistribution(db *gorm.DB, filter ReportFilter, startColumn string) (*DurationDistribution, error) {
+	seconds := reportSecondsBetween(db, startColumn, "t.completed_at")
+
+	columns := []string{"COUNT(*)", "AVG(d)", "MIN(d)", "MAX(d)"}
+	for _, bucket := range durationBuckets {
+		condition := fmt.Sprintf("d >= %d", bucket.MinHours*3600)
+		if bucket.MaxHours > 0 {
+			condition += fmt.Sprintf(" AND d < %d", bucket.MaxHours*3600)
+		}
+		columns = append(columns, "SUM(CASE WHEN "+condition+" THEN 1 ELSE 0 END)")
+	}
+
+	durations := reportTasks(db, filter).
+		Select(seconds+" AS d").
+		Where("t.completed_at >= ? AND t.completed_at < ? AND "+startColumn+" IS NOT NULL", filter.From, filter.To)
+
+	rows, err := db.Raw("SELECT "+strings.Join(columns, ", ")+" FROM (?) durations", durations).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var count int64
+	var avg, min, max sql.NullFloat64
+	bucketCounts := make([]sql.NullInt64, len(durationBuckets))
+	dest := []interface{}{&count, &avg, &min, &max}
+	for i := range bucketCounts {
+		dest = append(dest, &bucketCounts[i])
+	}
+	if rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	distribution := &DurationDistribution{
+		Count:        count,
+		AverageHours: roundHours(avg.Float64),
+		MinHours:     roundHours(min.Float64),
+		MaxHours:     roundHours(max.Float64),
+		Buckets:      make([]DurationBucket, len(durationBuckets)),
+	}
+	copy(distribution.Buckets, durationBuckets)
+	for i := range distribution.Buckets {
+		distribution.Buckets[i].Count = bucketCounts[i].Int64
+	}
+	return distribution, nil
+}
+
+// StatusBreakdown counts tasks per status for each user or department.
+// Departments are taken from the task's assignee; assignees without one are
+// grouped under an empty key.
+func (s *ReportServiceImpl) StatusBreakdown(db *gorm.DB, groupBy string, filter ReportFilter) ([]StatusBreakdown, error) {
+	keyExpr, labelExpr, err := reportGroupExprs(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Key    string
+		Label  string
+		Status string
+		Count  int64
+	}
+	err = reportTasks(db, filter).
+		Select(keyExpr + " AS key, " + labelExpr + " AS label, t.status AS status, COUNT(*) AS count").
+		Group(keyExpr + ", " + labelExpr + ", t.status").
+		Order("label, key, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks by status: %w", err)
+	}
+
+	var result []StatusBreakdown
+	index := map[string]int{}
+	for _, row := range rows {
+		i, ok := index[row.Key]
+		if !ok {
+			i = len(result)
+			index[row.Key] = i
+			result = append(result, StatusBreakdown{Key: row.Key, Label: row.Label, Counts: map[string]int64{}})
+		}
+		result[i].Counts[row.Status] += row.Count
+		result[i].Total += row.Count
+	}
+	return result, nil
+}
+
+// Overdue counts open tasks that were due before now, in total and per user
+// and department.
+func (s *ReportServiceImpl) Overdue(db *gorm.DB, filter ReportFilter, now time.Time) (*OverdueReport, error) {
+	report := &OverdueReport{ByUser: []OverdueCount{}, ByDepartment: []OverdueCount{}}
+
+	for _, groupBy := range []string{ReportGroupByUser, ReportGroupByDepartment} {
+		keyExpr, labelExpr, _ := reportGroupExprs(groupBy)
+		var counts []OverdueCount
+		err := reportTasks(db, filter).
+			Select(keyExpr+" AS key, "+labelExpr+" AS label, COUNT(*) AS count").
+			Where("t.due_date < ? AND t.status NOT IN ?", now, closedTaskStatuses).
+			Group(keyExpr + ", " + labelExpr).
+			Order("count DESC, label").
+			Scan(&counts).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count overdue tasks: %w", err)
+		}
+
+		if groupBy == ReportGroupByUser {
+			report.ByUser = append(report.ByUser, counts...)
+			for _, count := range counts {
+				report.Total += count.Count
+			}
+		} else {
+			report.ByDepartment = append(report.ByDepartment, counts...)
+		}
+	}
+
+	return report, nil
+}
+
+// reportTasks starts a query over tasks t joined to their assignee u, or to
+// their owner while unassigned.
+func reportTasks(db *gorm.DB, filter ReportFilter) *gorm.DB {
+	query := db.Table("tasks t").Joins("LEFT JOIN users u ON u.id = COALESCE(t.assignee_id, t.user_id)")
+	query = workspaceScope(query, "t.workspace_id")
+	if filter.Department != "" {
+		query = query.Where("u.department = ?", filter.Department)
+	}
+	return query
+}
+
+func reportGroupExprs(groupBy string) (string, string, error) {
+	switch groupBy {
+	case ReportGroupByUser:
+		return "CAST(COALESCE(t.assignee_id, t.user_id) AS TEXT)", "COALESCE(u.username, '')", nil
+	case ReportGroupByDepartment:
+		return "COALESCE(u.department, '')", "COALESCE(u.department, '')", nil
+	}
+	return "", "", fmt.Errorf("%w: group_by must be user or department", ErrInvalidReportQuery)
+}
+
+// reportPeriodExpr truncates a timestamp column to the start of its period,
+// formatted as YYYY-MM-DD. Weeks start on Monday.
+func reportPeriodExpr(db *gorm.DB, column, period string) string {
+	if db.Dialector.Name() == "postgres" {
+		return fmt.Sprintf("to_char(date_trunc('%s', %s), 'YYYY-MM-DD')", period, column)
+	}
+
+	switch period {
+	case ReportPeriodWeek:
+		return fmt.Sprintf("date(%s, '-6 days', 'weekday 1')", column)
+	case ReportPeriodMonth:
+		return fmt.Sprintf("strftime('%%Y-%%m-01', %s)", column)
+	default:
+		return fmt.Sprintf("date(%s)", column)
+	}
+}
+
+func reportSecondsBetween(db *gorm.DB, start, end string) string {
+	if db.Dialector.Name() == "postgres" {
+		return fmt.Sprintf("EXTRACT(EPOCH FROM (%s - %s))", end, start)
+	}
+	return fmt.Sprintf("((julianday(%s) - julianday(%s)) * 86400)", end, start)
+}
+
+func validateReportRange(filter ReportFilter) error {
+	if filter.From.IsZero() || filter.To.IsZero() || !filter.From.Before(filter.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidReportQuery)
+	}
+	return nil
+}
+
+// reportPeriods lists the start of every period overlapping [From, To).
+func reportPeriods(filter ReportFilter) ([]string, error) {
+	if err := validateReportRange(filter); err != nil {
+		return nil, err
+	}
+
+	from := filter.From.UTC()
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	var next func(time.Time) time.Time
+	switch filter.Period {
+	case ReportPeriodDay:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case ReportPeriodWeek:
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case ReportPeriodMonth:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, fmt.Errorf("%w: period must be day, week or month", ErrInvalidReportQuery)
+	}
+
+	var periods []string
+	for t := start; t.Before(filter.To); t = next(t) {
+		if len(periods) == maxReportPeriods {
+			return nil, fmt.Errorf("%w: range spans more than %d periods", ErrInvalidReportQuery, maxReportPeriods)
+		}
+		periods = append(periods, t.Format("2006-01-02"))
+	}
+	return periods, nil
+}
+
+func roundHours(seconds float64) float64 {
+	return float64(int64(seconds/36+0.5)) / 100
+}
+
+// CachedReportService caches reports in the multi-level cache. Reports are
+// aggregates, so they are not invalidated on every task change; they expire
+// after ttl instead.
+type CachedReportService struct {
+	reports ReportService
+	cache   *cache.MultiLevelCache
+	ttl     time.Duration
+}
+
+func NewCachedReportService(reports ReportService, cacheInstance *cache.MultiLevelCache, ttl time.Duration) *CachedReportService {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &CachedReportService{reports: reports, cache: cacheInstance, ttl: ttl}
+}
+
+func (s *CachedReportService) Throughput(db *gorm.DB, filter ReportFilter) ([]ThroughputPoint, error) {
+	cacheKey := "reports:throughput:" + filter.cacheKey() + workspaceCacheSuffix(db)
+
+	var cached []ThroughputPoint
+	if err := s.cache.Get(cacheKey, &cached); err == nil {
+		return cached, nil
+	}
+
+	points, err := s.reports.Throughput(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, points, s.ttl)
+	return points, nil
+}
+
+func (s *CachedReportService) TaskDurations(db *gorm.DB, filter ReportFilter) (*TaskDurationReport, error) {
+	cacheKey := "reports:durations:" + filter.cacheKey() + workspaceCacheSuffix(db)
+
+	var cached TaskDurationReport
+	if err := s.cache.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	report, err := s.reports.TaskDurations(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, report, s.ttl)
+	return report, nil
+}
+
+func (s *CachedReportService) StatusBreakdown(db *gorm.DB, groupBy string, filter ReportFilter) ([]StatusBreakdown, error) {
+	cacheKey := "reports:status:" + groupBy + ":" + filter.cacheKey() + workspaceCacheSuffix(db)
+
+	var cached []StatusBreakdown
+	if err := s.cache.Get(cacheKey, &cached); err == nil {
+		return cached, nil
+	}
+
+	breakdown, err := s.reports.StatusBreakdown(db, groupBy, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, breakdown, s.ttl)
+	return breakdown, nil
+}
+
+// Overdue is computed and cached per minute of now, since the counts depend
+// on it.
+func (s *CachedReportService) Overdue(db *gorm.DB, filter ReportFilter, now time.Time) (*OverdueReport, error) {
+	now = now.Truncate(time.Minute)
+	cacheKey := fmt.Sprintf("reports:overdue:%s:%d", filter.Department, now.Unix()) + workspaceCacheSuffix(db)
+
+	var cached OverdueReport
+	if err := s.cache.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	report, err := s.reports.Overdue(db, filter, now)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(cacheKey, report, s.ttl)
+	return report, nil
+}
@@ -0,0 +1,196 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/cache"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportService_ThroughputAndDurations(t *testing.T) {
+	f := newTestFixture(t, "tasks")
+	reports := services.NewReportService()
+
+	// 2026-03-02 is a Monday.
+	f.addTask(t, f.alice, "done", fixtureTime(2, 9), fixtureTime(2, 12), fixtureTime(2, 18), nil)
+	f.addTask(t, f.alice, "completed", fixtureTime(2, 10), fixtureTime(4, 10), fixtureTime(6, 10), nil)
+	f.addTask(t, f.bob, "done", fixtureTime(3, 9), nil, fixtureTime(20, 9), nil)
+	f.addTask(t, f.carol, "pending", fixtureTime(9, 9), nil, nil, nil)
+	f.addTask(t, f.carol, "done", fixtureTime(1, 9), nil, fixtureTime(1, 10), nil)
+
+	filter := services.ReportFilter{From: *fixtureTime(2, 0), To: *fixtureTime(23, 0), Period: services.ReportPeriodWeek}
+	points, err := reports.Throughput(f.db, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []services.ThroughputPoint{
+		{Period: "2026-03-02", Created: 3, Completed: 2},
+		{Period: "2026-03-09", Created: 1, Completed: 0},
+		{Period: "2026-03-16", Created: 0, Completed: 1},
+	}, points)
+
+	filter.Period = services.ReportPeriodDay
+	filter.Department = "Sales"
+	points, err = reports.Throughput(f.db, filter)
+	require.NoError(t, err)
+	require.Len(t, points, 21)
+	assert.Equal(t, services.ThroughputPoint{Period: "2026-03-09", Created: 1}, points[7])
+
+	filter.Period = "hourly"
+	_, err = reports.Throughput(f.db, filter)
+	assert.ErrorIs(t, err, services.ErrInvalidReportQuery)
+
+	durations, err := reports.TaskDurations(f.db, services.ReportFilter{From: *fixtureTime(2, 0), To: *fixtureTime(23, 0)})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), durations.LeadTime.Count)
+	assert.Equal(t, 9.0, durations.LeadTime.MinHours)
+	assert.Equal(t, 408.0, durations.LeadTime.MaxHours)
+	lead := map[string]int64{}
+	for _, bucket := range durations.LeadTime.Buckets {
+		lead[bucket.Label] = bucket.Count
+	}
+	assert.Equal(t, map[string]int64{"< 1 day": 1, "1-3 days": 0, "3-7 days": 1, "1-2 weeks": 0, "2-4 weeks": 1, "4+ weeks": 0}, lead)
+
+	assert.Equal(t, int64(2), durations.CycleTime.Count, "tasks never started have no cycle time")
+	assert.Equal(t, 27.0, durations.CycleTime.AverageHours)
+}
+
+func TestReportService_StatusBreakdownAndOverdue(t *testing.T) {
+	f := newTestFixture(t, "tasks")
+	reports := services.NewReportService()
+	now := *fixtureTime(10, 12)
+
+	f.addTask(t, f.alice, "pending", fixtureTime(1, 9), nil, nil, fixtureTime(5, 9))
+	f.addTask(t, f.alice, "in_progress", fixtureTime(1, 9), fixtureTime(2, 9), nil, fixtureTime(9, 9))
+	f.addTask(t, f.alice, "done", fixtureTime(1, 9), nil, fixtureTime(3, 9), fixtureTime(2, 9))
+	f.addTask(t, f.bob, "pending", fixtureTime(1, 9), nil, nil, fixtureTime(12, 9))
+	f.addTask(t, f.carol, "pending", fixtureTime(1, 9), nil, nil, fixtureTime(4, 9))
+	f.addTask(t, f.carol, "cancelled", fixtureTime(1, 9), nil, nil, fixtureTime(4, 9))
+	// Assigned tasks count for the assignee's department, not the owner's.
+	require.NoError(t, f.db.Create(&models.Task{
+		ID: uuid.Must(uuid.NewV4()), UserID: f.alice, AssigneeID: &f.carol, Title: "Handed over", Status: "pending",
+		CreatedAt: *fixtureTime(1, 9), DueDate: fixtureTime(6, 9),
+	}).Error)
+
+	byDepartment, err := reports.StatusBreakdown(f.db, services.ReportGroupByDepartment, services.ReportFilter{})
+	require.NoError(t, err)
+	require.Len(t, byDepartment, 2)
+	assert.Equal(t, "Engineering", byDepartment[0].Key)
+	assert.Equal(t, map[string]int64{"pending": 2, "in_progress": 1, "done": 1}, byDepartment[0].Counts)
+	assert.Equal(t, int64(4), byDepartment[0].Total)
+	assert.Equal(t, int64(3), byDepartment[1].Total)
+
+	byUser, err := reports.StatusBreakdown(f.db, services.ReportGroupByUser, services.ReportFilter{Department: "Engineering"})
+	require.NoError(t, err)
+	require.Len(t, byUser, 2)
+	assert.Equal(t, "alice", byUser[0].Label)
+	assert.Equal(t, f.alice.String(), byUser[0].Key)
+	assert.Equal(t, "bob", byUser[1].Label)
+
+	_, err = reports.StatusBreakdown(f.db, "project", services.ReportFilter{})
+	assert.ErrorIs(t, err, services.ErrInvalidReportQuery)
+
+	overdue, err := reports.Overdue(f.db, services.ReportFilter{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), overdue.Total)
+	assert.Equal(t, []services.OverdueCount{
+		{Key: f.alice.String(), Label: "alice", Count: 2},
+		{Key: f.carol.String(), Label: "carol", Count: 2},
+	}, overdue.ByUser)
+	assert.Equal(t, []services.OverdueCount{
+		{Key: "Engineering", Label: "Engineering", Count: 2},
+		{Key: "Sales", Label: "Sales", Count: 2},
+	}, overdue.ByDepartment)
+}
+
+func TestCachedReportService_ServesFromCache(t *testing.T) {
+	f := newTestFixture(t, "tasks")
+	reports := services.NewCachedReportService(services.NewReportService(), cache.NewMultiLevelCache(nil), time.Minute)
+	now := *fixtureTime(10, 12)
+
+	f.addTask(t, f.alice, "pending", fixtureTime(1, 9), nil, nil, fixtureTime(5, 9))
+	first, err := reports.Overdue(f.db, services.ReportFilter{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first.Total)
+
+	f.addTask(t, f.bob, "pending", fixtureTime(1, 9), nil, nil, fixtureTime(5, 9))
+	cached, err := reports.Overdue(f.db, services.ReportFilter{}, now.Add(10*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cached.Total, "the same minute is served from the cache")
+
+	engineering, err := reports.Overdue(f.db, services.ReportFilter{Department: "Engineering"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), engineering.Total, "other filters are cached separately")
+}
+
+func TestCachedReportService_KeepsWorkspacesApart(t *testing.T) {
+	f := newTestFixture(t, "tasks")
+	reports := services.NewCachedReportService(services.NewReportService(), cache.NewMultiLevelCache(nil), time.Minute)
+	now := *fixtureTime(10, 12)
+
+	acme, globex := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	for _, task := range []struct {
+		owner     uuid.UUID
+		workspace uuid.UUID
+	}{{f.alice, acme}, {f.bob, globex}, {f.carol, globex}} {
+		require.NoError(t, f.db.Create(&models.Task{
+			ID: uuid.Must(uuid.NewV4()), UserID: task.owner, WorkspaceID: &task.workspace, Title: "Task", Status: "pending",
+			CreatedAt: *fixtureTime(1, 9), DueDate: fixtureTime(5, 9),
+		}).Error)
+	}
+	acmeDB := f.db.WithContext(services.WithWorkspace(context.Background(), acme))
+	globexDB := f.db.WithContext(services.WithWorkspace(context.Background(), globex))
+
+	acmeOverdue, err := reports.Overdue(acmeDB, services.ReportFilter{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), acmeOverdue.Total)
+	globexOverdue, err := reports.Overdue(globexDB, services.ReportFilter{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), globexOverdue.Total, "another workspace's cached report is not served")
+
+	acmeStatus, err := reports.StatusBreakdown(acmeDB, services.ReportGroupByUser, services.ReportFilter{})
+	require.NoError(t, err)
+	require.Len(t, acmeStatus, 1)
+	globexStatus, err := reports.StatusBreakdown(globexDB, services.ReportGroupByUser, services.ReportFilter{})
+	require.NoError(t, err)
+	assert.Len(t, globexStatus, 2)
+}
+
+func TestTaskService_RecordsStartAndCompletion(t *testing.T) {
+	f := newTestFixture(t, "tasks")
+	tasks := services.NewTaskService()
+
+	taskID := uuid.Must(uuid.NewV4())
+	require.NoError(t, tasks.CreateTask(f.db, models.Task{ID: taskID, UserID: f.alice, Title: "Write report", Status: "pending"}))
+
+	require.NoError(t, tasks.UpdateTask(f.db, taskID, models.Task{Status: models.TaskStatusInProgress}))
+	task, err := tasks.GetTaskByID(f.db, taskID)
+	require.NoError(t, err)
+	require.NotNil(t, task.StartedAt)
+	assert.Nil(t, task.CompletedAt)
+	started := *task.StartedAt
+
+	require.NoError(t, tasks.UpdateTask(f.db, taskID, models.Task{Status: models.TaskStatusDone}))
+	task, err = tasks.GetTaskByID(f.db, taskID)
+	require.NoError(t, err)
+	require.NotNil(t, task.CompletedAt)
+	assert.True(t, task.StartedAt.Equal(started))
+
+	// Reopening clears completion but keeps the original start.
+	require.NoError(t, tasks.UpdateTask(f.db, taskID, models.Task{Status: models.TaskStatusInProgress}))
+	task, err = tasks.GetTaskByID(f.db, taskID)
+	require.NoError(t, err)
+	assert.Nil(t, task.CompletedAt)
+	assert.True(t, task.StartedAt.Equal(started))
+
+	require.NoError(t, tasks.UpdateTask(f.db, taskID, models.Task{Title: "Renamed"}))
+	task, err = tasks.GetTaskByID(f.db, taskID)
+	require.NoError(t, err)
+	assert.Nil(t, task.CompletedAt)
+}
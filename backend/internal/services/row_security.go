@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// The row-level security policies read the current workspace from this
+// setting; see migrations 000026 and 000035. Unset hides every row, so work
+// without a workspace runs on the system pool, which bypasses the policies.
+const workspaceSetting = "app.workspace_id"
+
+// workspaceSession carries a request's workspace and the connections the
+// workspace setting was applied to, one per pool. Statements outside a
+// transaction share their pool's pinned connection so rows scanned after the
+// statement still come from a connection with the setting in place. Pools are
+// kept apart so a statement never runs as another pool's role.
+type workspaceSession struct {
+	workspaceID uuid.UUID
+
+	mu    sync.Mutex
+	conns map[connProvider]*sql.Conn
+}
+
+type connProvider interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// pinnedConn returns the session's connection from pool, taking one and
+// applying the workspace setting on first use.
+func (s *workspaceSession) pinnedConn(ctx context.Context, pool connProvider) (*sql.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if conn, ok := s.conns[pool]; ok {
+		return conn, nil
+	}
+
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT set_config($1, $2, false)", workspaceSetting, s.workspaceID.String()); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set workspace for row security: %w", err)
+	}
+	if s.conns == nil {
+		s.conns = make(map[connProvider]*sql.Conn)
+	}
+	s.conns[pool] = conn
+	return conn, nil
+}
+
+func (s *workspaceSession) isPinned(conn *sql.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pinned := range s.conns {
+		if pinned == conn {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *workspaceSession) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for pool, conn := range s.conns {
+		// A connection that cannot be reset must not go back to the pool
+		// with another tenant's workspace still set.
+		if _, err := conn.ExecContext(context.Background(), "SELECT set_config($1, '', false)", workspaceSetting); err != nil {
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+		delete(s.conns, pool)
+	}
+}
+
+// ReleaseWorkspaceSession returns the connection pinned for ctx's workspace
+// to the pool. Later statements with ctx pin a new one.
+func ReleaseWorkspaceSession(ctx context.Context) {
+	if session := workspaceSessionFrom(ctx); session != nil {
+		session.release()
+	}
+}
+
+// registerRowSecurity installs the callback that applies the context's
+// workspace to the Postgres session before every statement.
+func registerRowSecurity(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:begin_transaction").Before("gorm:create").Register("tenant:session", applyRowSecurity); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:session", applyRowSecurity); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:begin_transaction").Before("gorm:update").Register("tenant:session", applyRowSecurity); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:begin_transaction").Before("gorm:delete").Register("tenant:session", applyRowSecurity); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:session", applyRowSecurity); err != nil {
+		return err
+	}
+	return callbacks.Raw().Before("gorm:raw").Register("tenant:session", applyRowSecurity)
+}
+
+// applyRowSecurity sets the workspace for the connection the statement runs
+// on. Inside a transaction the setting is local to it; otherwise the
+// statement moves to the session's pinned connection.
+func applyRowSecurity(db *gorm.DB) {
+	if db.Error != nil || db.Dialector.Name() != "postgres" {
+		return
+	}
+	ctx := db.Statement.Context
+	session := workspaceSessionFrom(ctx)
+	if session == nil || session.workspaceID == uuid.Nil {
+		return
+	}
+
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); inTransaction {
+		_, err := db.Statement.ConnPool.ExecContext(ctx, "SELECT set_config($1, $2, true)", workspaceSetting, session.workspaceID.String())
+		if err != nil {
+			db.AddError(fmt.Errorf("failed to set workspace for row security: %w", err))
+		}
+		return
+	}
+
+	if conn, ok := db.Statement.ConnPool.(*sql.Conn); ok && session.isPinned(conn) {
+		// Associations loaded by a statement that already moved.
+		return
+	}
+	connPool := db.Statement.ConnPool
+	if prepared, ok := connPool.(*gorm.PreparedStmtDB); ok {
+		connPool = prepared.ConnPool
+	}
+	pool, ok := connPool.(connProvider)
+	if !ok {
+		db.AddError(fmt.Errorf("row security needs a connection pool, got %T", db.Statement.ConnPool))
+		return
+	}
+	conn, err := session.pinnedConn(ctx, pool)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.Statement.ConnPool = conn
+}
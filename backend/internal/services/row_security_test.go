@@ -0,0 +1,240 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/repositories"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// rowSecurityMigrations enable the policies and then make them strict.
+var rowSecurityMigrations = []string{
+	"../../../database-migrations/migrations/000026_enable_row_level_security.up.sql",
+	"../../../database-migrations/migrations/000035_deny_rows_without_workspace.up.sql",
+}
+
+// setupRowSecurityTestDB creates a scratch schema in the Postgres database at
+// TEST_DATABASE_URL and applies the row security migrations to it. The role
+// must not be a superuser or have BYPASSRLS, as those skip every policy. It
+// also returns the DSN of the scratch schema.
+func setupRowSecurityTestDB(t *testing.T) (*gorm.DB, string) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	var bypass bool
+	require.NoError(t, admin.Raw("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass).Error)
+	if bypass {
+		t.Skip("TEST_DATABASE_URL connects as a role that bypasses row security")
+	}
+
+	schema := "rls_test_" + uuid.Must(uuid.NewV4()).String()[:8]
+	require.NoError(t, admin.Exec("CREATE SCHEMA "+schema).Error)
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	separator := " "
+	if strings.Contains(dsn, "://") {
+		separator = "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+	}
+	dsn += separator + "search_path=" + schema
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	createTestTables(t, db, "workspaces", "users", "workspace_members", "tasks", "sprints", "sprint_snapshots",
+		"task_attachments", "task_watchers", "task_shares", "audit_logs")
+
+	for _, path := range rowSecurityMigrations {
+		migration, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(string(migration)).Error)
+	}
+	require.NoError(t, services.RegisterTenantScope(db))
+	return db, dsn
+}
+
+// rowSecurityTenants is two workspaces, Acme and Globex, with a member and a
+// task each.
+type rowSecurityTenants struct {
+	acme, globex         uuid.UUID
+	alice, carol         uuid.UUID
+	acmeTask, globexTask uuid.UUID
+}
+
+func seedRowSecurityTenants(t *testing.T, db *gorm.DB) rowSecurityTenants {
+	r := rowSecurityTenants{
+		acme: uuid.Must(uuid.NewV4()), globex: uuid.Must(uuid.NewV4()),
+		alice: uuid.Must(uuid.NewV4()), carol: uuid.Must(uuid.NewV4()),
+		acmeTask: uuid.Must(uuid.NewV4()), globexTask: uuid.Must(uuid.NewV4()),
+	}
+	for _, seed := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{`INSERT INTO workspaces (id, name, slug) VALUES (?, 'Acme', 'acme'), (?, 'Globex', 'globex')`, []interface{}{r.acme, r.globex}},
+		{`INSERT INTO users (id, username, email) VALUES (?, 'alice', 'alice@acme.test'), (?, 'carol', 'carol@globex.test')`, []interface{}{r.alice, r.carol}},
+		{`INSERT INTO workspace_members (workspace_id, user_id) VALUES (?, ?), (?, ?)`, []interface{}{r.acme, r.alice, r.globex, r.carol}},
+		{`INSERT INTO tasks (id, user_id, title, workspace_id) VALUES (?, ?, 'Acme roadmap', ?), (?, ?, 'Globex plan', ?)`, []interface{}{r.acmeTask, r.alice, r.acme, r.globexTask, r.carol, r.globex}},
+		{`INSERT INTO task_attachments (id, task_id, filename, content_type, size, data) VALUES (?, ?, 'q1.csv', 'text/csv', 0, '')`, []interface{}{uuid.Must(uuid.NewV4()), r.globexTask}},
+		{`INSERT INTO audit_logs (id, user_id, action, resource, decision, workspace_id) VALUES (?, ?, 'task.read', 'task', 'allowed', ?)`, []interface{}{uuid.Must(uuid.NewV4()), r.carol, r.globex}},
+	} {
+		require.NoError(t, db.Exec(seed.sql, seed.args...).Error)
+	}
+	return r
+}
+
+// allowSystemRole grants the system role the scratch schema's tables, as
+// migration 000035 does for the public schema. It reports false when db's
+// role may not assume the system role.
+func allowSystemRole(t *testing.T, db *gorm.DB) bool {
+	var member bool
+	require.NoError(t, db.Raw("SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = ?) AND pg_has_role(current_user, ?, 'MEMBER')",
+		repositories.SystemRole, repositories.SystemRole).Scan(&member).Error)
+	if !member {
+		return false
+	}
+	var schema string
+	require.NoError(t, db.Raw("SELECT current_schema()").Scan(&schema).Error)
+	require.NoError(t, db.Exec("GRANT USAGE ON SCHEMA "+schema+" TO "+repositories.SystemRole).Error)
+	require.NoError(t, db.Exec("GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA "+schema+" TO "+repositories.SystemRole).Error)
+	return true
+}
+
+func TestRowSecurity_HidesOtherWorkspaces(t *testing.T) {
+	db, _ := setupRowSecurityTestDB(t)
+	tenants := seedRowSecurityTenants(t, db)
+	acme, globex := tenants.acme, tenants.globex
+	alice, carol := tenants.alice, tenants.carol
+	globexTask := tenants.globexTask
+
+	ctx := services.WithWorkspace(context.Background(), acme)
+	defer services.ReleaseWorkspaceSession(ctx)
+	acmeDB := db.WithContext(ctx)
+	users := services.NewUserService()
+
+	// Raw SQL bypasses the application's scoping; only the policies stand
+	// between it and the other tenant.
+	leaked, err := users.GetUserProfileMalicious(acmeDB, carol.String())
+	require.NoError(t, err)
+	assert.Empty(t, leaked)
+	own, err := users.GetUserProfileMalicious(acmeDB, alice.String())
+	require.NoError(t, err)
+	assert.Len(t, own, 1)
+
+	var titles []string
+	require.NoError(t, acmeDB.Raw("SELECT title FROM tasks ORDER BY title").Scan(&titles).Error)
+	assert.Equal(t, []string{"Acme roadmap"}, titles)
+
+	var attachments, auditLogs int64
+	require.NoError(t, acmeDB.Table("task_attachments").Count(&attachments).Error)
+	require.NoError(t, acmeDB.Table("audit_logs").Count(&auditLogs).Error)
+	assert.Zero(t, attachments)
+	assert.Zero(t, auditLogs)
+
+	// Inside a transaction the setting is local to it.
+	err = acmeDB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Raw("SELECT COUNT(*) FROM tasks").Scan(&count).Error; err != nil {
+			return err
+		}
+		assert.Equal(t, int64(1), count)
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = acmeDB.Exec(`UPDATE tasks SET title = 'Hijacked' WHERE id = ?`, globexTask).Error
+	require.NoError(t, err)
+	err = acmeDB.Exec(`INSERT INTO tasks (id, user_id, title, workspace_id) VALUES (?, ?, 'Planted', ?)`, uuid.Must(uuid.NewV4()), alice, globex).Error
+	assert.Error(t, err, "rows cannot be written into another workspace")
+
+	// Unscoped statements see nothing, and the pinned connection goes back
+	// to the pool without the setting.
+	services.ReleaseWorkspaceSession(ctx)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	var unscoped int64
+	var setting string
+	require.NoError(t, db.Model(&models.Task{}).Count(&unscoped).Error)
+	assert.Zero(t, unscoped)
+	require.NoError(t, db.Raw("SELECT COALESCE(current_setting('app.workspace_id', true), '')").Scan(&setting).Error)
+	assert.Empty(t, setting)
+
+	// The system role, used by background jobs, sees every tenant.
+	if !allowSystemRole(t, db) {
+		t.Skip("TEST_DATABASE_URL's role cannot assume " + repositories.SystemRole)
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL ROLE " + repositories.SystemRole).Error; err != nil {
+			return err
+		}
+		var globexTitle string
+		if err := tx.Model(&models.Task{}).Select("title").Where("id = ?", globexTask).Scan(&globexTitle).Error; err != nil {
+			return err
+		}
+		assert.Equal(t, "Globex plan", globexTitle)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRowSecurity_SystemPoolDoesNotLeakIntoRequests(t *testing.T) {
+	db, dsn := setupRowSecurityTestDB(t)
+	if !allowSystemRole(t, db) {
+		t.Skip("TEST_DATABASE_URL's role cannot assume " + repositories.SystemRole)
+	}
+	tenants := seedRowSecurityTenants(t, db)
+
+	dialector, err := repositories.RoleDialector(dsn, repositories.SystemRole)
+	require.NoError(t, err)
+	systemDB, err := gorm.Open(dialector, &gorm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := systemDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	require.NoError(t, services.RegisterTenantScope(systemDB))
+
+	ctx := services.WithWorkspace(context.Background(), tenants.acme)
+	defer services.ReleaseWorkspaceSession(ctx)
+
+	// Authorization reads on the system pool first in a request; the
+	// application pool must still get a connection of its own.
+	var roles int64
+	require.NoError(t, systemDB.WithContext(ctx).Table("workspace_members").Count(&roles).Error)
+
+	var titles []string
+	require.NoError(t, db.WithContext(ctx).Raw("SELECT title FROM tasks WHERE id = ?", tenants.globexTask).Scan(&titles).Error)
+	assert.Empty(t, titles, "the application pool must not read another workspace")
+
+	var role string
+	require.NoError(t, db.WithContext(ctx).Raw("SELECT current_user").Scan(&role).Error)
+	assert.NotEqual(t, repositories.SystemRole, role)
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ClientInfo describes the client a session's tokens are issued to.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Session is a login as seen by its user: the refresh token family started
+// by the login, described by its newest token. The ID is the family ID, so it
+// stays the same across refreshes.
+type Session struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+type SessionService interface {
+	// List returns the user's active sessions, most recently used first. The
+	// session that issued the access token currentJTI is marked current.
+	List(db *gorm.DB, userID uuid.UUID, currentJTI string) ([]Session, error)
+	// Terminate ends one of the user's sessions: its refresh tokens are
+	// deleted and the access tokens it issued are revoked.
+	Terminate(ctx context.Context, db *gorm.DB, userID, sessionID uuid.UUID) error
+	// TerminateAll ends every session of the user and returns how many there
+	// were.
+	TerminateAll(ctx context.Context, db *gorm.DB, userID uuid.UUID) (int64, error)
+}
+
+type SessionServiceImpl struct {
+	denylist *TokenDenylist
+}
+
+func NewSessionService(denylist *TokenDenylist) SessionService {
+	return &SessionServiceImpl{denylist: denylist}
+}
+
+func activeSessionTokens(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Model(&models.Token{}).
+		Where("user_id = ? AND rotated_at IS NULL AND expires_at > ?", userID, time.Now())
+}
+
+func (s *SessionServiceImpl) List(db *gorm.DB, userID uuid.UUID, currentJTI string) ([]Session, error) {
+	var tokens []models.Token
+	if err := activeSessionTokens(db, userID).Order("last_used_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, Session{
+			ID:         token.FamilyID,
+			UserID:     token.UserId,
+			Device:     token.Device,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			LastUsedAt: token.LastUsedAt,
+			ExpiresAt:  token.ExpiresAt,
+			Current:    currentJTI != "" && token.AccessJTI.String() == currentJTI,
+		})
+	}
+	return sessions, nil
+}
+
+func (s *SessionServiceImpl) Terminate(ctx context.Context, db *gorm.DB, userID, sessionID uuid.UUID) error {
+	var count int64
+	if err := activeSessionTokens(db, userID).Where("family_id = ?", sessionID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrSessionNotFound
+	}
+
+	// Rotated tokens of the family are kept for reuse detection; those issued
+	// within the access token lifetime still have live access tokens.
+	var tokens []models.Token
+	if err := db.Where("user_id = ? AND family_id = ? AND created_at > ?", userID, sessionID, time.Now().Add(-AccessTokenTTL)).
+		Find(&tokens).Error; err != nil {
+		return err
+	}
+
+	if err := db.Where("user_id = ? AND family_id = ?", userID, sessionID).Delete(&models.Token{}).Error; err != nil {
+		return fmt.Errorf("failed to delete session tokens: %w", err)
+	}
+
+	for _, token := range tokens {
+		if token.AccessJTI == uuid.Nil {
+			continue
+		}
+		if err := s.denylist.Revoke(ctx, token.AccessJTI.String(), token.CreatedAt.Add(AccessTokenTTL)); err != nil {
+			return fmt.Errorf("failed to revoke session access token: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SessionServiceImpl) TerminateAll(ctx context.Context, db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := activeSessionTokens(db, userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	if err := db.Where("user_id = ?", userID).Delete(&models.Token{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to delete session tokens: %w", err)
+	}
+	if err := s.denylist.RevokeUser(ctx, userID); err != nil {
+		return 0, fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return count, nil
+}
+
+// DeviceName summarises a user agent as "Browser on OS" for session lists.
+// Unrecognised agents are shown as they are, shortened.
+func DeviceName(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	// Order matters: Chrome agents mention Safari, Edge and Opera agents
+	// mention Chrome, and Android and iOS agents mention Linux and Mac OS X.
+	var browser string
+	for _, candidate := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	var os string
+	for _, candidate := range []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			os = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+	if len(userAgent) > 100 {
+		return userAgent[:100]
+	}
+	return userAgent
+}
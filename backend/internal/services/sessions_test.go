@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const macChrome = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+
+func accessTokenClaims(t *testing.T, accessToken string) jwt.MapClaims {
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(accessToken, claims)
+	require.NoError(t, err)
+	return claims
+}
+
+func TestSessionService_ListAndTerminate(t *testing.T) {
+	db := openTestDB(t, "users", "tokens")
+	userID := createTestUser(t, db, "alice")
+	auth := services.NewAuthService(services.AuthOptions{})
+	denylist := services.NewTokenDenylist(nil)
+	sessions := services.NewSessionService(denylist)
+	ctx := context.Background()
+
+	laptopAccess, laptopRefresh, err := auth.GenerateToken(db, userID, services.ClientInfo{UserAgent: macChrome, IPAddress: "203.0.113.7"})
+	require.NoError(t, err)
+	_, _, err = auth.GenerateToken(db, userID, services.ClientInfo{UserAgent: "curl/8.5.0", IPAddress: "198.51.100.2"})
+	require.NoError(t, err)
+
+	// Refreshing keeps the session but moves it to the new address.
+	refreshedAccess, _, _, err := auth.RefreshToken(db, laptopRefresh, services.ClientInfo{UserAgent: macChrome, IPAddress: "203.0.113.9"})
+	require.NoError(t, err)
+	current := accessTokenClaims(t, refreshedAccess)["jti"].(string)
+
+	list, err := sessions.List(db, userID, current)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	var laptop services.Session
+	for _, session := range list {
+		if session.Current {
+			laptop = session
+		}
+	}
+	assert.Equal(t, "Chrome on macOS", laptop.Device)
+	assert.Equal(t, "203.0.113.9", laptop.IPAddress)
+	assert.NotNil(t, laptop.LastUsedAt)
+
+	other, err := sessions.List(db, uuid.Must(uuid.NewV4()), "")
+	require.NoError(t, err)
+	assert.Empty(t, other)
+	assert.ErrorIs(t, sessions.Terminate(ctx, db, uuid.Must(uuid.NewV4()), laptop.ID), services.ErrSessionNotFound,
+		"sessions of other users are not found")
+
+	require.NoError(t, sessions.Terminate(ctx, db, userID, laptop.ID))
+	assert.ErrorIs(t, sessions.Terminate(ctx, db, userID, laptop.ID), services.ErrSessionNotFound)
+
+	for _, accessToken := range []string{laptopAccess, refreshedAccess} {
+		jti := accessTokenClaims(t, accessToken)["jti"].(string)
+		assert.True(t, denylist.IsRevoked(ctx, jti, userID, time.Now()), "access tokens of the session are revoked")
+	}
+
+	var remaining int64
+	require.NoError(t, db.Model(&models.Token{}).Where("user_id = ?", userID).Count(&remaining).Error)
+	assert.EqualValues(t, 1, remaining)
+
+	count, err := sessions.TerminateAll(ctx, db, userID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	list, err = sessions.List(db, userID, "")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestDeviceName(t *testing.T) {
+	assert.Equal(t, "Chrome on macOS", services.DeviceName(macChrome))
+	assert.Equal(t, "Safari on iOS", services.DeviceName("Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"))
+	assert.Equal(t, "Edge on Windows", services.DeviceName("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0"))
+	assert.Equal(t, "Firefox on Linux", services.DeviceName("Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0"))
+	assert.Equal(t, "taskify-cli/1.2", services.DeviceName("taskify-cli/1.2"))
+	assert.Equal(t, "Unknown device", services.DeviceName(""))
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	shareTokenPrefix = "shr_"
+
+	DefaultShareTTL = 7 * 24 * time.Hour
+	MaxShareTTL     = 90 * 24 * time.Hour
+
+	AuditActionShareViewed = "task.share_viewed"
+)
+
+var (
+	// ErrShareNotFound covers unknown, expired and revoked links alike so the
+	// public endpoint does not reveal which tokens once existed.
+	ErrShareNotFound = errors.New("share link not found")
+	ErrSharePassword = errors.New("share link password required or incorrect")
+	ErrInvalidShare  = errors.New("invalid share link")
+)
+
+// ShareAccess describes the client opening a share link, for the audit log.
+type ShareAccess struct {
+	IPAddress string
+	UserAgent string
+	Method    string
+	Path      string
+}
+
+// SharedTask is the public view of a shared task. It leaves out owners, IDs
+// and anything else that is only meaningful inside the workspace.
+type SharedTask struct {
+	Title          string            `json:"title"`
+	Description    string            `json:"description"`
+	Status         string            `json:"status"`
+	DueDate        *time.Time        `json:"due_date,omitempty"`
+	Labels         models.StringList `json:"labels,omitempty"`
+	EstimatedHours *float64          `json:"estimated_hours,omitempty"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	ExpiresAt      time.Time         `json:"link_expires_at"`
+}
+
+type ShareService interface {
+	// CreateShare mints a link for the task. A zero expiresAt means
+	// DefaultShareTTL from now; an empty password leaves the link open.
+	CreateShare(db *gorm.DB, taskID, createdBy uuid.UUID, expiresAt time.Time, password string) (*models.TaskShare, string, error)
+	ListShares(db *gorm.DB, taskID uuid.UUID) ([]models.TaskShare, error)
+	RevokeShare(db *gorm.DB, taskID, shareID uuid.UUID) error
+	OpenShare(db *gorm.DB, token, password string, access ShareAccess) (*SharedTask, error)
+}
+
+type ShareServiceImpl struct{}
+
+func NewShareService() *ShareServiceImpl {
+	return &ShareServiceImpl{}
+}
+
+func (s *ShareServiceImpl) CreateShare(db *gorm.DB, taskID, createdBy uuid.UUID, expiresAt time.Time, password string) (*models.TaskShare, string, error) {
+	now := time.Now()
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(DefaultShareTTL)
+	}
+	if !expiresAt.After(now) || expiresAt.Sub(now) > MaxShareTTL {
+		return nil, "", fmt.Errorf("%w: expires_at must be in the future and within %d days", ErrInvalidShare, int(MaxShareTTL.Hours()/24))
+	}
+	if len(password) > 72 {
+		return nil, "", fmt.Errorf("%w: password must be at most 72 bytes", ErrInvalidShare)
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	token := shareTokenPrefix + hex.EncodeToString(buf)
+
+	share := models.TaskShare{
+		ID:        uuid.Must(uuid.NewV4()),
+		TaskID:    taskID,
+		CreatedBy: createdBy,
+		TokenHash: hashShareToken(token),
+		ExpiresAt: expiresAt.UTC(),
+	}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to hash share password: %w", err)
+		}
+		share.PasswordHash = string(hash)
+	}
+
+	if err := db.Create(&share).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to save share link: %w", err)
+	}
+	return &share, token, nil
+}
+
+func (s *ShareServiceImpl) ListShares(db *gorm.DB, taskID uuid.UUID) ([]models.TaskShare, error) {
+	shares := []models.TaskShare{}
+	err := db.Where("task_id = ?", taskID).Order("created_at DESC").Find(&shares).Error
+	return shares, err
+}
+
+func (s *ShareServiceImpl) RevokeShare(db *gorm.DB, taskID, shareID uuid.UUID) error {
+	result := db.Model(&models.TaskShare{}).
+		Where("id = ? AND task_id = ? AND revoked_at IS NULL", shareID, taskID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// OpenShare resolves a token to the public task view. Every attempt on a live
+// link is written to audit_logs under the link's creator, with wrong passwords
+// recorded as denied.
+func (s *ShareServiceImpl) OpenShare(db *gorm.DB, token, password string, access ShareAccess) (*SharedTask, error) {
+	if !strings.HasPrefix(token, shareTokenPrefix) {
+		return nil, ErrShareNotFound
+	}
+
+	var share models.TaskShare
+	if err := db.Where("token_hash = ?", hashShareToken(token)).First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !share.Active(now) {
+		return nil, ErrShareNotFound
+	}
+
+	var task models.Task
+	if err := db.Where("id = ?", share.TaskID).First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+
+	if share.HasPassword() && bcrypt.CompareHashAndPassword([]byte(share.PasswordHash), []byte(password)) != nil {
+		if err := recordShareAccess(db, share, task.WorkspaceID, access, "denied", "incorrect share password", now); err != nil {
+			return nil, err
+		}
+		return nil, ErrSharePassword
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&share).UpdateColumns(map[string]interface{}{
+			"access_count":     gorm.Expr("access_count + 1"),
+			"last_accessed_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordShareAccess(tx, share, task.WorkspaceID, access, "allowed", "share link opened", now)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record share access: %w", err)
+	}
+
+	return &SharedTask{
+		Title:          task.Title,
+		Description:    task.Description,
+		Status:         task.Status,
+		DueDate:        task.DueDate,
+		Labels:         task.Labels,
+		EstimatedHours: task.EstimatedHours,
+		CompletedAt:    task.CompletedAt,
+		CreatedAt:      task.CreatedAt,
+		UpdatedAt:      task.UpdatedAt,
+		ExpiresAt:      share.ExpiresAt,
+	}, nil
+}
+
+// recordShareAccess writes the audit row into the shared task's workspace, as
+// the public request carries none.
+func recordShareAccess(db *gorm.DB, share models.TaskShare, workspaceID *uuid.UUID, access ShareAccess, decision, reason string, now time.Time) error {
+	detail, _ := json.Marshal(map[string]string{"share_id": share.ID.String()})
+
+	return db.Create(&models.AuditLog{
+		ID:            uuid.Must(uuid.NewV4()),
+		UserID:        share.CreatedBy,
+		Action:        AuditActionShareViewed,
+		Resource:      "task",
+		ResourceID:    share.TaskID,
+		Decision:      decision,
+		Reason:        reason,
+		IPAddress:     access.IPAddress,
+		UserAgent:     access.UserAgent,
+		RequestMethod: access.Method,
+		RequestPath:   access.Path,
+		Context:       string(detail),
+		Timestamp:     now,
+		WorkspaceID:   workspaceID,
+	}).Error
+}
+
+// hashShareToken hashes a token for lookup. Tokens carry 192 random bits, so
+// unlike passwords they do not need a slow hash.
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
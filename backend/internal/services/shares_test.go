@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupShareTestDB(t *testing.T) *testFixture {
+	return newTestFixture(t, "tasks", "task_shares", "audit_logs")
+}
+
+func TestShareService_OpenAndRevoke(t *testing.T) {
+	f := setupShareTestDB(t)
+	shares := services.NewShareService()
+
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: f.alice, Title: "Homepage redesign", Description: "Mockups attached", Status: "in_progress", Labels: models.StringList{"client"}}
+	require.NoError(t, f.db.Create(&task).Error)
+
+	_, _, err := shares.CreateShare(f.db, task.ID, f.alice, time.Now().Add(-time.Hour), "")
+	assert.ErrorIs(t, err, services.ErrInvalidShare)
+	_, _, err = shares.CreateShare(f.db, task.ID, f.alice, time.Now().Add(100*24*time.Hour), "")
+	assert.ErrorIs(t, err, services.ErrInvalidShare)
+
+	share, token, err := shares.CreateShare(f.db, task.ID, f.alice, time.Time{}, "")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(services.DefaultShareTTL), share.ExpiresAt, time.Minute)
+
+	access := services.ShareAccess{IPAddress: "203.0.113.7", UserAgent: "curl/8", Method: "GET", Path: "/api/v1/shared/:token"}
+	view, err := shares.OpenShare(f.db, token, "", access)
+	require.NoError(t, err)
+	assert.Equal(t, "Homepage redesign", view.Title)
+	assert.Equal(t, models.StringList{"client"}, view.Labels)
+
+	_, err = shares.OpenShare(f.db, "shr_unknown", "", access)
+	assert.ErrorIs(t, err, services.ErrShareNotFound)
+
+	list, err := shares.ListShares(f.db, task.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, 1, list[0].AccessCount)
+	assert.NotNil(t, list[0].LastAccessedAt)
+
+	var entry models.AuditLog
+	require.NoError(t, f.db.Where("action = ?", services.AuditActionShareViewed).First(&entry).Error)
+	assert.Equal(t, f.alice, entry.UserID)
+	assert.Equal(t, task.ID, entry.ResourceID)
+	assert.Equal(t, "allowed", entry.Decision)
+	assert.Equal(t, "203.0.113.7", entry.IPAddress)
+	assert.Equal(t, "/api/v1/shared/:token", entry.RequestPath)
+	assert.Contains(t, entry.Context, share.ID.String())
+
+	require.NoError(t, shares.RevokeShare(f.db, task.ID, share.ID))
+	assert.ErrorIs(t, shares.RevokeShare(f.db, task.ID, share.ID), services.ErrShareNotFound)
+	_, err = shares.OpenShare(f.db, token, "", access)
+	assert.ErrorIs(t, err, services.ErrShareNotFound)
+}
+
+func TestShareService_PasswordProtected(t *testing.T) {
+	f := setupShareTestDB(t)
+	shares := services.NewShareService()
+
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: f.alice, Title: "Quarterly numbers", Status: "pending"}
+	require.NoError(t, f.db.Create(&task).Error)
+
+	share, token, err := shares.CreateShare(f.db, task.ID, f.alice, time.Now().Add(time.Hour), "open sesame")
+	require.NoError(t, err)
+	assert.True(t, share.HasPassword())
+
+	_, err = shares.OpenShare(f.db, token, "", services.ShareAccess{})
+	assert.ErrorIs(t, err, services.ErrSharePassword)
+	_, err = shares.OpenShare(f.db, token, "guess", services.ShareAccess{})
+	assert.ErrorIs(t, err, services.ErrSharePassword)
+
+	view, err := shares.OpenShare(f.db, token, "open sesame", services.ShareAccess{})
+	require.NoError(t, err)
+	assert.Equal(t, "Quarterly numbers", view.Title)
+
+	var denied, allowed int64
+	f.db.Model(&models.AuditLog{}).Where("decision = ?", "denied").Count(&denied)
+	f.db.Model(&models.AuditLog{}).Where("decision = ?", "allowed").Count(&allowed)
+	assert.Equal(t, int64(2), denied)
+	assert.Equal(t, int64(1), allowed)
+
+	// Expired links read as missing.
+	require.NoError(t, f.db.Model(share).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = shares.OpenShare(f.db, token, "open sesame", services.ShareAccess{})
+	assert.ErrorIs(t, err, services.ErrShareNotFound)
+}
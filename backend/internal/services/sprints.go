@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSprintNotFound = errors.New("sprint not found")
+	ErrSprintClosed   = errors.New("sprint is closed")
+	ErrInvalidSprint  = errors.New("invalid sprint")
+)
+
+// SprintCloseResult reports what closing a sprint did with its tasks.
+// Unfinished tasks move to RolledOverTo, or leave the sprint when it is nil.
+type SprintCloseResult struct {
+	Sprint          models.Sprint `json:"sprint"`
+	CompletedTasks  int64         `json:"completed_tasks"`
+	RolledOverTasks int64         `json:"rolled_over_tasks"`
+	RolledOverTo    *uuid.UUID    `json:"rolled_over_to,omitempty"`
+}
+
+// BurndownPoint is one day of a sprint. The actual values are nil for days
+// without a snapshot, such as days still ahead.
+type BurndownPoint struct {
+	Date           string   `json:"date"`
+	IdealTasks     float64  `json:"ideal_tasks"`
+	IdealHours     float64  `json:"ideal_hours"`
+	TotalTasks     *int64   `json:"total_tasks"`
+	RemainingTasks *int64   `json:"remaining_tasks"`
+	RemainingHours *float64 `json:"remaining_hours"`
+}
+
+type Burndown struct {
+	Sprint models.Sprint   `json:"sprint"`
+	Points []BurndownPoint `json:"points"`
+}
+
+type SprintService interface {
+	CreateSprint(db *gorm.DB, sprint *models.Sprint) error
+	GetSprint(db *gorm.DB, id uuid.UUID) (*models.Sprint, error)
+	ListSprints(db *gorm.DB, status string) ([]models.Sprint, error)
+	StartSprint(db *gorm.DB, id uuid.UUID, now time.Time) (*models.Sprint, error)
+	CloseSprint(db *gorm.DB, id uuid.UUID, rolloverTo *uuid.UUID, now time.Time) (*SprintCloseResult, error)
+
+	AddTask(db *gorm.DB, sprintID, taskID uuid.UUID) error
+	RemoveTask(db *gorm.DB, sprintID, taskID uuid.UUID) error
+
+	Snapshot(db *gorm.DB, sprint models.Sprint, now time.Time) (*models.SprintSnapshot, error)
+	SnapshotActive(db *gorm.DB, now time.Time) (int, error)
+	Burndown(db *gorm.DB, id uuid.UUID) (*Burndown, error)
+}
+
+type SprintServiceImpl struct{}
+
+func NewSprintService() *SprintServiceImpl {
+	return &SprintServiceImpl{}
+}
+
+// sprintDay truncates t to its UTC calendar day.
+func sprintDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *SprintServiceImpl) CreateSprint(db *gorm.DB, sprint *models.Sprint) error {
+	sprint.Name = strings.TrimSpace(sprint.Name)
+	if sprint.Name == "" || len(sprint.Name) > 255 {
+		return fmt.Errorf("%w: name must be 1-255 characters", ErrInvalidSprint)
+	}
+	if sprint.StartDate.IsZero() || sprint.EndDate.IsZero() {
+		return fmt.Errorf("%w: start_date and end_date are required", ErrInvalidSprint)
+	}
+
+	sprint.StartDate = sprintDay(sprint.StartDate)
+	sprint.EndDate = sprintDay(sprint.EndDate)
+	if sprint.EndDate.Before(sprint.StartDate) {
+		return fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidSprint)
+	}
+
+	if sprint.ID == uuid.Nil {
+		sprint.ID = uuid.Must(uuid.NewV4())
+	}
+	sprint.Status = models.SprintStatusPlanned
+	return db.Create(sprint).Error
+}
+
+func (s *SprintServiceImpl) GetSprint(db *gorm.DB, id uuid.UUID) (*models.Sprint, error) {
+	var sprint models.Sprint
+	if err := db.Where("id = ?", id).First(&sprint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSprintNotFound
+		}
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+func (s *SprintServiceImpl) ListSprints(db *gorm.DB, status string) ([]models.Sprint, error) {
+	query := db.Order("start_date DESC, name")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	sprints := []models.Sprint{}
+	err := query.Find(&sprints).Error
+	return sprints, err
+}
+
+// StartSprint activates a planned sprint and records its first snapshot, which
+// sets the baseline of the ideal burndown line.
+func (s *SprintServiceImpl) StartSprint(db *gorm.DB, id uuid.UUID, now time.Time) (*models.Sprint, error) {
+	var sprint *models.Sprint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		sprint, err = s.GetSprint(tx, id)
+		if err != nil {
+			return err
+		}
+		if sprint.Status != models.SprintStatusPlanned {
+			return fmt.Errorf("%w: only planned sprints can be started", ErrInvalidSprint)
+		}
+
+		sprint.Status = models.SprintStatusActive
+		if err := tx.Model(sprint).Update("status", sprint.Status).Error; err != nil {
+			return err
+		}
+		_, err = s.Snapshot(tx, *sprint, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sprint, nil
+}
+
+// CloseSprint records a final snapshot, then moves unfinished tasks to
+// rolloverTo, or out of any sprint when rolloverTo is nil.
+func (s *SprintServiceImpl) CloseSprint(db *gorm.DB, id uuid.UUID, rolloverTo *uuid.UUID, now time.Time) (*SprintCloseResult, error) {
+	result := &SprintCloseResult{RolledOverTo: rolloverTo}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sprint, err := s.GetSprint(tx, id)
+		if err != nil {
+			return err
+		}
+		if sprint.Status == models.SprintStatusClosed {
+			return ErrSprintClosed
+		}
+
+		if rolloverTo != nil {
+			if *rolloverTo == id {
+				return fmt.Errorf("%w: cannot roll over into the sprint being closed", ErrInvalidSprint)
+			}
+			next, err := s.GetSprint(tx, *rolloverTo)
+			if err != nil {
+				return err
+			}
+			if next.Status == models.SprintStatusClosed {
+				return fmt.Errorf("%w: cannot roll over into a closed sprint", ErrInvalidSprint)
+			}
+		}
+
+		snapshot, err := s.Snapshot(tx, *sprint, now)
+		if err != nil {
+			return err
+		}
+		result.CompletedTasks = snapshot.CompletedTasks
+
+		moved := tx.Model(&models.Task{}).
+			Where("sprint_id = ? AND status NOT IN ?", id, closedTaskStatuses).
+			Update("sprint_id", rolloverTo)
+		if moved.Error != nil {
+			return fmt.Errorf("failed to roll over tasks: %w", moved.Error)
+		}
+		result.RolledOverTasks = moved.RowsAffected
+
+		closedAt := now
+		sprint.Status = models.SprintStatusClosed
+		sprint.ClosedAt = &closedAt
+		if err := tx.Model(sprint).Updates(map[string]interface{}{"status": sprint.Status, "closed_at": closedAt}).Error; err != nil {
+			return err
+		}
+		result.Sprint = *sprint
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *SprintServiceImpl) AddTask(db *gorm.DB, sprintID, taskID uuid.UUID) error {
+	sprint, err := s.GetSprint(db, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.Status == models.SprintStatusClosed {
+		return ErrSprintClosed
+	}
+
+	result := db.Model(&models.Task{}).Where("id = ?", taskID).Update("sprint_id", sprintID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *SprintServiceImpl) RemoveTask(db *gorm.DB, sprintID, taskID uuid.UUID) error {
+	sprint, err := s.GetSprint(db, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.Status == models.SprintStatusClosed {
+		return ErrSprintClosed
+	}
+
+	result := db.Model(&models.Task{}).
+		Where("id = ? AND sprint_id = ?", taskID, sprintID).
+		Update("sprint_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Snapshot records the sprint's current work for the day of now, clamped to
+// the sprint's dates. Running it again on the same day overwrites the row, so
+// the last run of a day holds its closing state.
+func (s *SprintServiceImpl) Snapshot(db *gorm.DB, sprint models.Sprint, now time.Time) (*models.SprintSnapshot, error) {
+	day := sprintDay(now)
+	if day.Before(sprintDay(sprint.StartDate)) {
+		day = sprintDay(sprint.StartDate)
+	}
+	if day.After(sprintDay(sprint.EndDate)) {
+		day = sprintDay(sprint.EndDate)
+	}
+
+	var snapshot models.SprintSnapshot
+	err := db.Table("tasks").
+		Select(`COUNT(*) AS total_tasks,
+			COALESCE(SUM(CASE WHEN status IN ? THEN 1 ELSE 0 END), 0) AS completed_tasks,
+			COALESCE(SUM(estimated_hours), 0) AS total_hours,
+			COALESCE(SUM(CASE WHEN status IN ? THEN 0 ELSE estimated_hours END), 0) AS remaining_hours`,
+			completedTaskStatuses, completedTaskStatuses).
+		Where("sprint_id = ? AND status <> ?", sprint.ID, "cancelled").
+		Scan(&snapshot).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sprint tasks: %w", err)
+	}
+	snapshot.SprintID = sprint.ID
+	snapshot.SnapshotDate = day
+	snapshot.RemainingTasks = snapshot.TotalTasks - snapshot.CompletedTasks
+
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "sprint_id"}, {Name: "snapshot_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"total_tasks", "completed_tasks", "remaining_tasks", "total_hours", "remaining_hours", "updated_at"}),
+	}).Create(&snapshot).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save sprint snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// SnapshotActive snapshots every active sprint that covers the day of now.
+func (s *SprintServiceImpl) SnapshotActive(db *gorm.DB, now time.Time) (int, error) {
+	today := sprintDay(now)
+
+	var sprints []models.Sprint
+	err := db.Where("status = ? AND start_date <= ? AND end_date >= ?", models.SprintStatusActive, today, today).
+		Find(&sprints).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sprint := range sprints {
+		if _, err := s.Snapshot(db, sprint, now); err != nil {
+			return 0, fmt.Errorf("sprint %s: %w", sprint.ID, err)
+		}
+	}
+	return len(sprints), nil
+}
+
+// Burndown returns one point per sprint day. The ideal line falls linearly
+// from the first snapshot's totals to zero on the last day.
+func (s *SprintServiceImpl) Burndown(db *gorm.DB, id uuid.UUID) (*Burndown, error) {
+	sprint, err := s.GetSprint(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []models.SprintSnapshot
+	if err := db.Where("sprint_id = ?", id).Order("snapshot_date").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]models.SprintSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		byDate[snapshot.SnapshotDate.UTC().Format("2006-01-02")] = snapshot
+	}
+
+	var baselineTasks, baselineHours float64
+	if len(snapshots) > 0 {
+		baselineTasks = float64(snapshots[0].TotalTasks)
+		baselineHours = snapshots[0].TotalHours
+	}
+
+	start, end := sprintDay(sprint.StartDate), sprintDay(sprint.EndDate)
+	days := int(end.Sub(start).Hours() / 24)
+
+	burndown := &Burndown{Sprint: *sprint, Points: make([]BurndownPoint, 0, days+1)}
+	for i := 0; i <= days; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+
+		left := 0.0
+		if days > 0 {
+			left = 1 - float64(i)/float64(days)
+		}
+		point := BurndownPoint{
+			Date:       date,
+			IdealTasks: math.Round(baselineTasks*left*100) / 100,
+			IdealHours: math.Round(baselineHours*left*100) / 100,
+		}
+		if snapshot, ok := byDate[date]; ok {
+			point.TotalTasks = &snapshot.TotalTasks
+			point.RemainingTasks = &snapshot.RemainingTasks
+			point.RemainingHours = &snapshot.RemainingHours
+		}
+		burndown.Points = append(burndown.Points, point)
+	}
+
+	return burndown, nil
+}
+
+// NewSprintSnapshotJobHandler returns the worker handler that snapshots active
+// sprints. It is scheduled several times a day; each run rewrites the day's row.
+func NewSprintSnapshotJobHandler(db *gorm.DB, sprints SprintService) worker.JobHandler {
+	return func(ctx context.Context, job *worker.Job) error {
+		count, err := sprints.SnapshotActive(db.WithContext(ctx), time.Now())
+		if err != nil {
+			return fmt.Errorf("sprint snapshot failed: %w", err)
+		}
+
+		log.Printf("Recorded burndown snapshots for %d active sprints", count)
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupSprintTestDB(t *testing.T) *testFixture {
+	return newTestFixture(t, "tasks", "sprints", "sprint_snapshots")
+}
+
+func addSprintTask(t *testing.T, db *gorm.DB, sprints services.SprintService, sprintID uuid.UUID, owner uuid.UUID, status string, hours float64) uuid.UUID {
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: owner, Title: "Task " + status, Status: status, EstimatedHours: &hours}
+	require.NoError(t, db.Create(&task).Error)
+	require.NoError(t, sprints.AddTask(db, sprintID, task.ID))
+	return task.ID
+}
+
+func TestSprintService_BurndownAndRollover(t *testing.T) {
+	f := setupSprintTestDB(t)
+	sprints := services.NewSprintService()
+
+	_, err := sprints.ListSprints(f.db, "")
+	require.NoError(t, err)
+	err = sprints.CreateSprint(f.db, &models.Sprint{Name: "Backwards", StartDate: *fixtureTime(10, 0), EndDate: *fixtureTime(2, 0)})
+	assert.ErrorIs(t, err, services.ErrInvalidSprint)
+
+	sprint := models.Sprint{Name: " Sprint 1 ", StartDate: *fixtureTime(2, 15), EndDate: *fixtureTime(6, 0)}
+	require.NoError(t, sprints.CreateSprint(f.db, &sprint))
+	assert.Equal(t, "Sprint 1", sprint.Name)
+	assert.Equal(t, models.SprintStatusPlanned, sprint.Status)
+
+	first := addSprintTask(t, f.db, sprints, sprint.ID, f.alice, "pending", 4)
+	second := addSprintTask(t, f.db, sprints, sprint.ID, f.alice, "pending", 2)
+	addSprintTask(t, f.db, sprints, sprint.ID, f.bob, "in_progress", 3)
+	addSprintTask(t, f.db, sprints, sprint.ID, f.bob, "pending", 1)
+	addSprintTask(t, f.db, sprints, sprint.ID, f.bob, "cancelled", 8)
+
+	_, err = sprints.StartSprint(f.db, sprint.ID, *fixtureTime(2, 9))
+	require.NoError(t, err)
+	_, err = sprints.StartSprint(f.db, sprint.ID, *fixtureTime(2, 9))
+	assert.ErrorIs(t, err, services.ErrInvalidSprint)
+
+	// Day 2 is recorded twice; the later run wins.
+	require.NoError(t, f.db.Model(&models.Task{}).Where("id = ?", first).Update("status", "done").Error)
+	count, err := sprints.SnapshotActive(f.db, *fixtureTime(3, 9))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.NoError(t, f.db.Model(&models.Task{}).Where("id = ?", second).Update("status", "completed").Error)
+	_, err = sprints.SnapshotActive(f.db, *fixtureTime(3, 23))
+	require.NoError(t, err)
+
+	count, err = sprints.SnapshotActive(f.db, *fixtureTime(9, 9))
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "sprints past their end date are not snapshotted")
+
+	burndown, err := sprints.Burndown(f.db, sprint.ID)
+	require.NoError(t, err)
+	require.Len(t, burndown.Points, 5)
+
+	start := burndown.Points[0]
+	assert.Equal(t, "2026-03-02", start.Date)
+	assert.Equal(t, 4.0, start.IdealTasks)
+	assert.Equal(t, 10.0, start.IdealHours)
+	require.NotNil(t, start.RemainingTasks)
+	assert.Equal(t, int64(4), *start.RemainingTasks)
+
+	dayTwo := burndown.Points[1]
+	assert.Equal(t, 3.0, dayTwo.IdealTasks)
+	require.NotNil(t, dayTwo.RemainingTasks)
+	assert.Equal(t, int64(2), *dayTwo.RemainingTasks)
+	assert.Equal(t, 4.0, *dayTwo.RemainingHours)
+
+	assert.Nil(t, burndown.Points[2].RemainingTasks)
+	assert.Equal(t, 0.0, burndown.Points[4].IdealTasks)
+
+	next := models.Sprint{Name: "Sprint 2", StartDate: *fixtureTime(9, 0), EndDate: *fixtureTime(13, 0)}
+	require.NoError(t, sprints.CreateSprint(f.db, &next))
+
+	_, err = sprints.CloseSprint(f.db, sprint.ID, &sprint.ID, *fixtureTime(6, 17))
+	assert.ErrorIs(t, err, services.ErrInvalidSprint)
+
+	result, err := sprints.CloseSprint(f.db, sprint.ID, &next.ID, *fixtureTime(6, 17))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.CompletedTasks)
+	assert.Equal(t, int64(2), result.RolledOverTasks)
+	assert.Equal(t, models.SprintStatusClosed, result.Sprint.Status)
+	require.NotNil(t, result.Sprint.ClosedAt)
+
+	var inNext int64
+	require.NoError(t, f.db.Model(&models.Task{}).Where("sprint_id = ?", next.ID).Count(&inNext).Error)
+	assert.Equal(t, int64(2), inNext)
+	var leftBehind int64
+	require.NoError(t, f.db.Model(&models.Task{}).Where("sprint_id = ?", sprint.ID).Count(&leftBehind).Error)
+	assert.Equal(t, int64(3), leftBehind, "finished and cancelled tasks stay with the closed sprint")
+
+	burndown, err = sprints.Burndown(f.db, sprint.ID)
+	require.NoError(t, err)
+	require.NotNil(t, burndown.Points[4].RemainingTasks, "closing records the final day")
+	assert.Equal(t, int64(2), *burndown.Points[4].RemainingTasks)
+
+	_, err = sprints.CloseSprint(f.db, sprint.ID, nil, time.Now())
+	assert.ErrorIs(t, err, services.ErrSprintClosed)
+	assert.ErrorIs(t, sprints.AddTask(f.db, sprint.ID, first), services.ErrSprintClosed)
+
+	_, err = sprints.GetSprint(f.db, uuid.Must(uuid.NewV4()))
+	assert.ErrorIs(t, err, services.ErrSprintNotFound)
+}
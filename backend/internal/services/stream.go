@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	streamChannel     = "stream:task_events"
+	streamSequenceKey = "stream:task_events:seq"
+
+	defaultReplayBufferSize = 1000
+	subscriberBufferSize    = 64
+)
+
+// StreamEvent is a task event tagged with a sequence ID that clients echo back
+// in Last-Event-ID when they reconnect.
+type StreamEvent struct {
+	ID    uint64           `json:"id"`
+	Event models.TaskEvent `json:"event"`
+}
+
+// StreamSubscription receives live events. Replay holds buffered events newer
+// than the requested Last-Event-ID; Truncated is set when some of them had
+// already been evicted and the client should refetch its state.
+type StreamSubscription struct {
+	Replay    []StreamEvent
+	Truncated bool
+	Events    <-chan StreamEvent
+
+	events chan StreamEvent
+}
+
+type EventStream interface {
+	TaskEventPublisher
+
+	Subscribe(lastEventID uint64) *StreamSubscription
+	Unsubscribe(sub *StreamSubscription)
+	Close() error
+}
+
+// TaskEventBroker fans task events out to connected stream clients. With a
+// Redis client events travel over pub/sub so every instance sees them and
+// sequence IDs are shared; without one it is a purely in-process broker.
+type TaskEventBroker struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+
+	mu          sync.Mutex
+	subscribers map[*StreamSubscription]struct{}
+	buffer      []StreamEvent
+	bufferSize  int
+	sequence    uint64
+	evicted     uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewTaskEventBroker(client *redis.Client, bufferSize int) *TaskEventBroker {
+	if bufferSize <= 0 {
+		bufferSize = defaultReplayBufferSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &TaskEventBroker{
+		client:      client,
+		subscribers: make(map[*StreamSubscription]struct{}),
+		bufferSize:  bufferSize,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	if client != nil {
+		b.pubsub = client.Subscribe(ctx, streamChannel)
+		b.wg.Add(1)
+		go b.receive()
+	}
+
+	return b
+}
+
+// PublishTaskEvent assigns the next sequence ID and broadcasts the event.
+func (b *TaskEventBroker) PublishTaskEvent(db *gorm.DB, event models.TaskEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	if b.client == nil {
+		b.mu.Lock()
+		b.sequence++
+		id := b.sequence
+		b.mu.Unlock()
+
+		b.broadcast(StreamEvent{ID: id, Event: event})
+		return nil
+	}
+
+	id, err := b.client.Incr(b.ctx, streamSequenceKey).Uint64()
+	if err != nil {
+		return fmt.Errorf("failed to allocate stream event ID: %w", err)
+	}
+
+	data, err := json.Marshal(StreamEvent{ID: id, Event: event})
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+
+	return b.client.Publish(b.ctx, streamChannel, data).Err()
+}
+
+// Subscribe registers a client. The replay snapshot and registration happen
+// under one lock so nothing published in between is lost.
+func (b *TaskEventBroker) Subscribe(lastEventID uint64) *StreamSubscription {
+	events := make(chan StreamEvent, subscriberBufferSize)
+	sub := &StreamSubscription{Events: events, events: events}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastEventID > 0 {
+		for _, ev := range b.buffer {
+			if ev.ID > lastEventID {
+				sub.Replay = append(sub.Replay, ev)
+			}
+		}
+		sub.Truncated = b.evicted > lastEventID
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (b *TaskEventBroker) Unsubscribe(sub *StreamSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (b *TaskEventBroker) Close() error {
+	b.cancel()
+
+	var err error
+	if b.pubsub != nil {
+		err = b.pubsub.Close()
+	}
+	b.wg.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+
+	return err
+}
+
+func (b *TaskEventBroker) receive() {
+	defer b.wg.Done()
+
+	for msg := range b.pubsub.Channel() {
+		var ev StreamEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+			log.Printf("Failed to decode stream event: %v", err)
+			continue
+		}
+		b.broadcast(ev)
+	}
+}
+
+// broadcast records the event in the replay buffer and hands it to every
+// subscriber. A subscriber that has fallen a full buffer behind is dropped;
+// its client reconnects with Last-Event-ID and catches up from the replay.
+func (b *TaskEventBroker) broadcast(ev StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buffer = append(b.buffer, ev)
+	if len(b.buffer) > b.bufferSize {
+		if oldest := b.buffer[0].ID; oldest > b.evicted {
+			b.evicted = oldest
+		}
+		b.buffer = b.buffer[1:]
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- ev:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamTestEvent(title string) models.TaskEvent {
+	return models.TaskEvent{
+		Type:    models.TaskEventUpdated,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: uuid.Must(uuid.NewV4()),
+		Title:   title,
+	}
+}
+
+func receiveStreamEvent(t *testing.T, sub *services.StreamSubscription) services.StreamEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-sub.Events:
+		require.True(t, ok, "subscription closed unexpectedly")
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for stream event")
+		return services.StreamEvent{}
+	}
+}
+
+func TestTaskEventBroker_InProcessDelivery(t *testing.T) {
+	broker := services.NewTaskEventBroker(nil, 10)
+	defer broker.Close()
+
+	sub := broker.Subscribe(0)
+	defer broker.Unsubscribe(sub)
+	assert.Empty(t, sub.Replay)
+
+	require.NoError(t, broker.PublishTaskEvent(nil, streamTestEvent("first")))
+	require.NoError(t, broker.PublishTaskEvent(nil, streamTestEvent("second")))
+
+	first := receiveStreamEvent(t, sub)
+	second := receiveStreamEvent(t, sub)
+	assert.Equal(t, "first", first.Event.Title)
+	assert.Equal(t, "second", second.Event.Title)
+	assert.Greater(t, second.ID, first.ID)
+	assert.False(t, first.Event.OccurredAt.IsZero())
+}
+
+func TestTaskEventBroker_ResumeFromLastEventID(t *testing.T) {
+	broker := services.NewTaskEventBroker(nil, 10)
+	defer broker.Close()
+
+	for _, title := range []string{"one", "two", "three"} {
+		require.NoError(t, broker.PublishTaskEvent(nil, streamTestEvent(title)))
+	}
+
+	sub := broker.Subscribe(1)
+	defer broker.Unsubscribe(sub)
+
+	require.Len(t, sub.Replay, 2)
+	assert.Equal(t, "two", sub.Replay[0].Event.Title)
+	assert.Equal(t, "three", sub.Replay[1].Event.Title)
+	assert.False(t, sub.Truncated)
+}
+
+func TestTaskEventBroker_ResumeBeyondBufferIsTruncated(t *testing.T) {
+	broker := services.NewTaskEventBroker(nil, 2)
+	defer broker.Close()
+
+	for _, title := range []string{"one", "two", "three", "four"} {
+		require.NoError(t, broker.PublishTaskEvent(nil, streamTestEvent(title)))
+	}
+
+	sub := broker.Subscribe(1)
+	defer broker.Unsubscribe(sub)
+
+	assert.True(t, sub.Truncated)
+	require.Len(t, sub.Replay, 2)
+	assert.Equal(t, "three", sub.Replay[0].Event.Title)
+}
+
+func TestTaskEventBroker_SlowSubscriberIsDropped(t *testing.T) {
+	broker := services.NewTaskEventBroker(nil, 500)
+	defer broker.Close()
+
+	sub := broker.Subscribe(0)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, broker.PublishTaskEvent(nil, streamTestEvent("flood")))
+	}
+
+	received := 0
+	for range sub.Events {
+		received++
+	}
+	assert.Less(t, received, 100)
+
+	// Unsubscribing an already dropped subscriber must not panic.
+	broker.Unsubscribe(sub)
+}
+
+func TestTaskEventBroker_FansOutAcrossInstancesViaRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	newClient := func() *redis.Client {
+		return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	}
+
+	publisher := services.NewTaskEventBroker(newClient(), 10)
+	defer publisher.Close()
+	receiver := services.NewTaskEventBroker(newClient(), 10)
+	defer receiver.Close()
+
+	sub := receiver.Subscribe(0)
+	defer receiver.Unsubscribe(sub)
+
+	// Subscriptions are established asynchronously; wait until both brokers listen.
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub("stream:task_events")["stream:task_events"] == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	event := streamTestEvent("remote")
+	require.NoError(t, publisher.PublishTaskEvent(nil, event))
+
+	ev := receiveStreamEvent(t, sub)
+	assert.Equal(t, uint64(1), ev.ID)
+	assert.Equal(t, event.TaskID, ev.Event.TaskID)
+	assert.Equal(t, "remote", ev.Event.Title)
+
+	// The receiving instance buffers remote events so clients can resume there.
+	require.NoError(t, publisher.PublishTaskEvent(nil, streamTestEvent("after")))
+	assert.Equal(t, uint64(2), receiveStreamEvent(t, sub).ID)
+
+	resumed := receiver.Subscribe(1)
+	defer receiver.Unsubscribe(resumed)
+	require.Len(t, resumed.Replay, 1)
+	assert.Equal(t, "after", resumed.Replay[0].Event.Title)
+}
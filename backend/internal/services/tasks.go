@@ -2,6 +2,8 @@ package services
 
 import (
 	"strconv"
+	"time"
+
 	"task-manager/backend/internal/models"
 
 	"github.com/gofrs/uuid"
@@ -24,6 +26,13 @@ func NewTaskService() *TaskServiceImpl {
 }
 
 func (s *TaskServiceImpl) CreateTask(db *gorm.DB, task models.Task) error {
+	now := time.Now()
+	if task.Status == models.TaskStatusInProgress && task.StartedAt == nil {
+		task.StartedAt = &now
+	}
+	if models.IsCompletedStatus(task.Status) && task.CompletedAt == nil {
+		task.CompletedAt = &now
+	}
 	return db.Create(&task).Error
 }
 
@@ -65,8 +74,26 @@ func (s *TaskServiceImpl) GetTasksPaginated(db *gorm.DB, sortBy, order, page, pa
 	return tasks, total, result.Error
 }
 
+// UpdateTask applies the non-zero fields of updated. A status change also
+// records when work started and when the task was completed; reopening a task
+// clears its completion time.
 func (s *TaskServiceImpl) UpdateTask(db *gorm.DB, id uuid.UUID, updated models.Task) error {
-	return db.Model(&models.Task{}).Where("id = ?", id).Updates(updated).Error
+	if err := db.Model(&models.Task{}).Where("id = ?", id).Updates(updated).Error; err != nil {
+		return err
+	}
+	if updated.Status == "" {
+		return nil
+	}
+
+	now := time.Now()
+	timestamps := map[string]interface{}{"completed_at": nil}
+	switch {
+	case updated.Status == models.TaskStatusInProgress:
+		timestamps["started_at"] = gorm.Expr("COALESCE(started_at, ?)", now)
+	case models.IsCompletedStatus(updated.Status):
+		timestamps["completed_at"] = gorm.Expr("COALESCE(completed_at, ?)", now)
+	}
+	return db.Model(&models.Task{}).Where("id = ?", id).UpdateColumns(timestamps).Error
 }
 
 func (s *TaskServiceImpl) DeleteTask(db *gorm.DB, id uuid.UUID) error {
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTeamNotFound     = errors.New("team not found")
+	ErrNotTeamMember    = errors.New("not a member of this team")
+	ErrInvalidTeam      = errors.New("invalid team")
+	ErrTaskAlreadyTaken = errors.New("task is already assigned")
+)
+
+type TeamMemberView struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	IsLead   bool      `json:"is_lead"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type TeamService interface {
+	CreateTeam(db *gorm.DB, team *models.Team) error
+	GetTeam(db *gorm.DB, id uuid.UUID) (*models.Team, error)
+	ListTeams(db *gorm.DB) ([]models.Team, error)
+	DeleteTeam(db *gorm.DB, id uuid.UUID) error
+
+	// Membership returns ErrNotTeamMember when the user is not in the team.
+	Membership(db *gorm.DB, teamID, userID uuid.UUID) (*models.TeamMember, error)
+	ListMembers(db *gorm.DB, teamID uuid.UUID) ([]TeamMemberView, error)
+	// AddMember adds the user, who must belong to the team's workspace, or
+	// changes whether they lead the team if already a member.
+	AddMember(db *gorm.DB, teamID, userID uuid.UUID, lead bool) (*models.TeamMember, error)
+	RemoveMember(db *gorm.DB, teamID, userID uuid.UUID) error
+
+	// AddTask hands a task to the team. It joins the queue unless it already
+	// has an assignee.
+	AddTask(db *gorm.DB, teamID, taskID uuid.UUID) error
+	RemoveTask(db *gorm.DB, teamID, taskID uuid.UUID) error
+	// Queue returns the team's open tasks that nobody has claimed, those due
+	// soonest first.
+	Queue(db *gorm.DB, teamID uuid.UUID) ([]models.Task, error)
+	// ClaimTask assigns a queued task to userID. It returns
+	// ErrTaskAlreadyTaken when someone else got there first.
+	ClaimTask(db *gorm.DB, teamID, taskID, userID uuid.UUID) (*models.Task, error)
+}
+
+type TeamServiceImpl struct{}
+
+func NewTeamService() *TeamServiceImpl {
+	return &TeamServiceImpl{}
+}
+
+func (s *TeamServiceImpl) CreateTeam(db *gorm.DB, team *models.Team) error {
+	team.Name = strings.TrimSpace(team.Name)
+	if team.Name == "" || len(team.Name) > 100 {
+		return fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidTeam)
+	}
+
+	var existing int64
+	if err := db.Model(&models.Team{}).Where("name = ?", team.Name).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return fmt.Errorf("%w: a team named %q already exists", ErrInvalidTeam, team.Name)
+	}
+
+	if team.ID == uuid.Nil {
+		team.ID = uuid.Must(uuid.NewV4())
+	}
+	return db.Create(team).Error
+}
+
+func (s *TeamServiceImpl) GetTeam(db *gorm.DB, id uuid.UUID) (*models.Team, error) {
+	var team models.Team
+	if err := db.Where("id = ?", id).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (s *TeamServiceImpl) ListTeams(db *gorm.DB) ([]models.Team, error) {
+	teams := []models.Team{}
+	err := db.Order("name").Find(&teams).Error
+	return teams, err
+}
+
+// DeleteTeam removes the team. Its tasks stay with their assignees, and
+// unclaimed ones with their creators.
+func (s *TeamServiceImpl) DeleteTeam(db *gorm.DB, id uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.GetTeam(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Task{}).Where("team_id = ?", id).Update("team_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", id).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.Team{}).Error
+	})
+}
+
+func (s *TeamServiceImpl) Membership(db *gorm.DB, teamID, userID uuid.UUID) (*models.TeamMember, error) {
+	var member models.TeamMember
+	err := db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotTeamMember
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *TeamServiceImpl) ListMembers(db *gorm.DB, teamID uuid.UUID) ([]TeamMemberView, error) {
+	if _, err := s.GetTeam(db, teamID); err != nil {
+		return nil, err
+	}
+
+	members := []TeamMemberView{}
+	err := db.Table("team_members tm").
+		Joins("JOIN users u ON u.id = tm.user_id").
+		Select("tm.user_id, u.username, u.email, tm.is_lead, tm.created_at AS joined_at").
+		Where("tm.team_id = ? AND u.deleted_at IS NULL", teamID).
+		Order("tm.is_lead DESC, u.username").
+		Scan(&members).Error
+	return members, err
+}
+
+func (s *TeamServiceImpl) AddMember(db *gorm.DB, teamID, userID uuid.UUID, lead bool) (*models.TeamMember, error) {
+	var member models.TeamMember
+	err := db.Transaction(func(tx *gorm.DB) error {
+		team, err := s.GetTeam(tx, teamID)
+		if err != nil {
+			return err
+		}
+		if team.WorkspaceID != nil {
+			var members int64
+			err := tx.Model(&models.WorkspaceMember{}).
+				Where("workspace_id = ? AND user_id = ?", *team.WorkspaceID, userID).
+				Count(&members).Error
+			if err != nil {
+				return err
+			}
+			if members == 0 {
+				return fmt.Errorf("%w: user is not a member of the team's workspace", ErrInvalidTeam)
+			}
+		}
+
+		existing, err := s.Membership(tx, teamID, userID)
+		switch {
+		case errors.Is(err, ErrNotTeamMember):
+			member = models.TeamMember{TeamID: teamID, UserID: userID, IsLead: lead}
+			return tx.Create(&member).Error
+		case err != nil:
+			return err
+		}
+
+		existing.IsLead = lead
+		member = *existing
+		return tx.Model(existing).Where("team_id = ? AND user_id = ?", teamID, userID).Update("is_lead", lead).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *TeamServiceImpl) RemoveMember(db *gorm.DB, teamID, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.GetTeam(tx, teamID); err != nil {
+			return err
+		}
+		if _, err := s.Membership(tx, teamID, userID); err != nil {
+			return err
+		}
+		return tx.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{}).Error
+	})
+}
+
+func (s *TeamServiceImpl) AddTask(db *gorm.DB, teamID, taskID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.GetTeam(tx, teamID); err != nil {
+			return err
+		}
+		result := tx.Model(&models.Task{}).Where("id = ?", taskID).Update("team_id", teamID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (s *TeamServiceImpl) RemoveTask(db *gorm.DB, teamID, taskID uuid.UUID) error {
+	result := db.Model(&models.Task{}).Where("id = ? AND team_id = ?", taskID, teamID).Update("team_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *TeamServiceImpl) Queue(db *gorm.DB, teamID uuid.UUID) ([]models.Task, error) {
+	if _, err := s.GetTeam(db, teamID); err != nil {
+		return nil, err
+	}
+
+	tasks := []models.Task{}
+	err := db.Where("team_id = ? AND assignee_id IS NULL AND status NOT IN ?", teamID, closedTaskStatuses).
+		Order("due_date IS NULL, due_date, created_at").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (s *TeamServiceImpl) ClaimTask(db *gorm.DB, teamID, taskID, userID uuid.UUID) (*models.Task, error) {
+	var task models.Task
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND team_id = ?", taskID, teamID).First(&task).Error; err != nil {
+			return err
+		}
+
+		// The assignee check in the update makes concurrent claims safe.
+		result := tx.Model(&models.Task{}).
+			Where("id = ? AND assignee_id IS NULL", taskID).
+			Update("assignee_id", userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTaskAlreadyTaken
+		}
+		task.AssigneeID = &userID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
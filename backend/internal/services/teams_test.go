@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupTeamTestDB(t *testing.T) *testFixture {
+	f := setupWorkspaceTestDB(t)
+	createTestTables(t, f.db, "team_members")
+	require.NoError(t, f.db.Exec(`CREATE TABLE teams (
+		id TEXT PRIMARY KEY,
+		workspace_id TEXT,
+		name TEXT NOT NULL,
+		description TEXT,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`).Error)
+	return f
+}
+
+func TestTeamService_MembersAndQueue(t *testing.T) {
+	f := setupTeamTestDB(t)
+	workspaces := services.NewWorkspaceService()
+	teams := services.NewTeamService()
+
+	acme, err := workspaces.CreateWorkspace(f.db, "Acme", f.alice)
+	require.NoError(t, err)
+	_, err = workspaces.AddMember(f.db, acme.ID, f.bob, "")
+	require.NoError(t, err)
+	globex, err := workspaces.CreateWorkspace(f.db, "Globex", f.carol)
+	require.NoError(t, err)
+	acmeDB := f.db.WithContext(services.WithWorkspace(context.Background(), acme.ID))
+	globexDB := f.db.WithContext(services.WithWorkspace(context.Background(), globex.ID))
+
+	platform := models.Team{Name: "  Platform "}
+	require.NoError(t, teams.CreateTeam(acmeDB, &platform))
+	assert.Equal(t, "Platform", platform.Name)
+	require.NotNil(t, platform.WorkspaceID)
+	assert.Equal(t, acme.ID, *platform.WorkspaceID)
+	assert.ErrorIs(t, teams.CreateTeam(acmeDB, &models.Team{Name: "Platform"}), services.ErrInvalidTeam)
+	require.NoError(t, teams.CreateTeam(globexDB, &models.Team{Name: "Platform"}), "names are unique per workspace")
+
+	_, err = teams.GetTeam(globexDB, platform.ID)
+	assert.ErrorIs(t, err, services.ErrTeamNotFound)
+
+	_, err = teams.AddMember(acmeDB, platform.ID, f.carol, false)
+	assert.ErrorIs(t, err, services.ErrInvalidTeam, "carol is not in the workspace")
+	_, err = teams.AddMember(acmeDB, platform.ID, f.bob, false)
+	require.NoError(t, err)
+	lead, err := teams.AddMember(acmeDB, platform.ID, f.alice, true)
+	require.NoError(t, err)
+	assert.True(t, lead.IsLead)
+
+	members, err := teams.ListMembers(acmeDB, platform.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "alice", members[0].Username, "leads are listed first")
+
+	tasks := services.NewTaskService()
+	queued := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: f.alice, Title: "Rotate keys", Status: "pending", DueDate: fixtureTime(12, 0)}
+	undated := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: f.alice, Title: "Tidy dashboards", Status: "pending"}
+	finished := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: f.alice, Title: "Old work", Status: "done"}
+	for _, task := range []models.Task{undated, queued, finished} {
+		require.NoError(t, tasks.CreateTask(acmeDB, task))
+		require.NoError(t, teams.AddTask(acmeDB, platform.ID, task.ID))
+	}
+
+	queue, err := teams.Queue(acmeDB, platform.ID)
+	require.NoError(t, err)
+	require.Len(t, queue, 2)
+	assert.Equal(t, "Rotate keys", queue[0].Title, "tasks with a due date come first")
+
+	claimed, err := teams.ClaimTask(acmeDB, platform.ID, queued.ID, f.bob)
+	require.NoError(t, err)
+	require.NotNil(t, claimed.AssigneeID)
+	assert.Equal(t, f.bob, *claimed.AssigneeID)
+	_, err = teams.ClaimTask(acmeDB, platform.ID, queued.ID, f.alice)
+	assert.ErrorIs(t, err, services.ErrTaskAlreadyTaken)
+
+	queue, err = teams.Queue(acmeDB, platform.ID)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, "Tidy dashboards", queue[0].Title)
+
+	require.NoError(t, teams.RemoveTask(acmeDB, platform.ID, undated.ID))
+	assert.ErrorIs(t, teams.RemoveTask(acmeDB, platform.ID, undated.ID), gorm.ErrRecordNotFound)
+
+	require.NoError(t, teams.RemoveMember(acmeDB, platform.ID, f.bob))
+	assert.ErrorIs(t, teams.RemoveMember(acmeDB, platform.ID, f.bob), services.ErrNotTeamMember)
+
+	require.NoError(t, teams.DeleteTeam(acmeDB, platform.ID))
+	stored, err := tasks.GetTaskByID(acmeDB, queued.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.TeamID)
+	require.NotNil(t, stored.AssigneeID, "claimed work stays with its assignee")
+}
@@ -0,0 +1,310 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testSchema holds the tables several test files share, written in SQL both
+// SQLite and Postgres accept so the row security tests can use it too. Keep
+// it in step with the migrations; tables a single feature needs stay in that
+// feature's test file.
+var testSchema = map[string]string{
+	"users": `CREATE TABLE users (
+		id UUID PRIMARY KEY,
+		username TEXT,
+		email TEXT,
+		password TEXT,
+		first_name TEXT,
+		last_name TEXT,
+		department TEXT,
+		position TEXT,
+		is_active BOOLEAN DEFAULT true,
+		last_login_at TIMESTAMP,
+		email_verified_at TIMESTAMP,
+		verification_sent_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP
+	)`,
+	"tasks": `CREATE TABLE tasks (
+		id UUID PRIMARY KEY,
+		user_id UUID,
+		title TEXT NOT NULL,
+		description TEXT,
+		status TEXT,
+		due_date TIMESTAMP,
+		recurrence_rule TEXT,
+		labels TEXT,
+		started_at TIMESTAMP,
+		completed_at TIMESTAMP,
+		estimated_hours REAL,
+		sprint_id UUID,
+		workspace_id UUID,
+		team_id UUID,
+		assignee_id UUID,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	)`,
+	"task_attachments": `CREATE TABLE task_attachments (
+		id UUID PRIMARY KEY,
+		task_id UUID NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		data BYTEA NOT NULL,
+		uploaded_by UUID,
+		created_at TIMESTAMP
+	)`,
+	"task_watchers": `CREATE TABLE task_watchers (
+		task_id UUID NOT NULL,
+		user_id UUID NOT NULL,
+		reason TEXT NOT NULL DEFAULT 'manual',
+		created_at TIMESTAMP,
+		PRIMARY KEY (task_id, user_id)
+	)`,
+	"task_shares": `CREATE TABLE task_shares (
+		id UUID PRIMARY KEY,
+		task_id UUID NOT NULL,
+		created_by UUID NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		password_hash TEXT,
+		expires_at TIMESTAMP NOT NULL,
+		revoked_at TIMESTAMP,
+		access_count INTEGER NOT NULL DEFAULT 0,
+		last_accessed_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	)`,
+	"sprints": `CREATE TABLE sprints (
+		id UUID PRIMARY KEY,
+		name TEXT NOT NULL,
+		goal TEXT,
+		start_date DATE NOT NULL,
+		end_date DATE NOT NULL,
+		status TEXT NOT NULL DEFAULT 'planned',
+		closed_at TIMESTAMP,
+		created_by UUID,
+		workspace_id UUID,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	)`,
+	"sprint_snapshots": `CREATE TABLE sprint_snapshots (
+		sprint_id UUID NOT NULL,
+		snapshot_date DATE NOT NULL,
+		total_tasks INTEGER NOT NULL DEFAULT 0,
+		completed_tasks INTEGER NOT NULL DEFAULT 0,
+		remaining_tasks INTEGER NOT NULL DEFAULT 0,
+		total_hours REAL NOT NULL DEFAULT 0,
+		remaining_hours REAL NOT NULL DEFAULT 0,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		PRIMARY KEY (sprint_id, snapshot_date)
+	)`,
+	"workspaces": `CREATE TABLE workspaces (
+		id UUID PRIMARY KEY,
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL UNIQUE,
+		created_by UUID,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP
+	)`,
+	"workspace_members": `CREATE TABLE workspace_members (
+		workspace_id UUID NOT NULL,
+		user_id UUID NOT NULL,
+		role TEXT NOT NULL DEFAULT 'member',
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		PRIMARY KEY (workspace_id, user_id)
+	)`,
+	"team_members": `CREATE TABLE team_members (
+		team_id UUID NOT NULL,
+		user_id UUID NOT NULL,
+		is_lead BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		PRIMARY KEY (team_id, user_id)
+	)`,
+	"audit_logs": `CREATE TABLE audit_logs (
+		id UUID PRIMARY KEY,
+		user_id UUID,
+		action TEXT NOT NULL,
+		resource TEXT NOT NULL,
+		resource_id UUID,
+		decision TEXT NOT NULL,
+		reason TEXT,
+		ip_address TEXT,
+		user_agent TEXT,
+		request_method TEXT,
+		request_path TEXT,
+		context TEXT,
+		timestamp TIMESTAMP,
+		workspace_id UUID
+	)`,
+	"roles": `CREATE TABLE roles (
+		id UUID PRIMARY KEY,
+		name TEXT NOT NULL,
+		description TEXT,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP,
+		created_by UUID,
+		modified_by UUID
+	)`,
+	"permissions": `CREATE TABLE permissions (
+		id UUID PRIMARY KEY,
+		name TEXT,
+		resource TEXT NOT NULL,
+		action TEXT NOT NULL,
+		scope TEXT DEFAULT '*',
+		description TEXT,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP,
+		created_by UUID,
+		modified_by UUID
+	)`,
+	"user_roles": `CREATE TABLE user_roles (
+		id UUID PRIMARY KEY,
+		user_id UUID NOT NULL,
+		role_id UUID NOT NULL,
+		assigned_by UUID,
+		assigned_at TIMESTAMP,
+		expires_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP
+	)`,
+	"role_permissions": `CREATE TABLE role_permissions (
+		id UUID PRIMARY KEY,
+		role_id UUID NOT NULL,
+		permission_id UUID NOT NULL,
+		assigned_by UUID,
+		assigned_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP
+	)`,
+	"user_attributes": `CREATE TABLE user_attributes (
+		id UUID PRIMARY KEY,
+		user_id UUID NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		data_type TEXT NOT NULL,
+		source TEXT,
+		expires_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP
+	)`,
+	"tokens": `CREATE TABLE tokens (
+		id UUID PRIMARY KEY,
+		user_id UUID NOT NULL,
+		jti UUID NOT NULL UNIQUE,
+		refresh_token TEXT NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		family_id UUID,
+		rotated_at TIMESTAMP,
+		access_jti UUID,
+		device TEXT DEFAULT '',
+		user_agent TEXT DEFAULT '',
+		ip_address TEXT DEFAULT '',
+		last_used_at TIMESTAMP,
+		created_at TIMESTAMP,
+		updated_at TIMESTAMP,
+		deleted_at TIMESTAMP
+	)`,
+	"outbox": `CREATE TABLE outbox (
+		id UUID PRIMARY KEY,
+		aggregate_type TEXT NOT NULL,
+		aggregate_id UUID NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT,
+		next_attempt_at TIMESTAMP NOT NULL,
+		published_at TIMESTAMP,
+		created_at TIMESTAMP
+	)`,
+}
+
+// openTestDB opens an in-memory SQLite database with the named tables.
+func openTestDB(t testing.TB, tables ...string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createTestTables(t, db, tables...)
+	return db
+}
+
+// createTestTables creates the named tables from testSchema.
+func createTestTables(t testing.TB, db *gorm.DB, tables ...string) {
+	for _, table := range tables {
+		statement, ok := testSchema[table]
+		require.True(t, ok, "no test schema for table %q", table)
+		require.NoError(t, db.Exec(statement).Error)
+	}
+}
+
+// testFixture is a database with three users: alice and bob in Engineering
+// and carol in Sales.
+type testFixture struct {
+	db    *gorm.DB
+	alice uuid.UUID
+	bob   uuid.UUID
+	carol uuid.UUID
+}
+
+// newTestFixture opens a database with the users table and the named tables,
+// and adds the fixture's users.
+func newTestFixture(t *testing.T, tables ...string) *testFixture {
+	db := openTestDB(t, append([]string{"users"}, tables...)...)
+
+	f := &testFixture{db: db, alice: uuid.Must(uuid.NewV4()), bob: uuid.Must(uuid.NewV4()), carol: uuid.Must(uuid.NewV4())}
+	require.NoError(t, db.Exec(`INSERT INTO users (id, username, email, department) VALUES
+		(?, 'alice', 'alice@example.com', 'Engineering'),
+		(?, 'bob', 'bob@example.com', 'Engineering'),
+		(?, 'carol', 'carol@example.com', 'Sales')`, f.alice, f.bob, f.carol).Error)
+	return f
+}
+
+// fixtureTime is the given hour of a day in March 2026, UTC. 2026-03-02 is a
+// Monday.
+func fixtureTime(day, hour int) *time.Time {
+	t := time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func (f *testFixture) addTask(t *testing.T, owner uuid.UUID, status string, created, started, completed, due *time.Time) {
+	task := models.Task{
+		ID:          uuid.Must(uuid.NewV4()),
+		UserID:      owner,
+		Title:       "Task " + status,
+		Status:      status,
+		CreatedAt:   *created,
+		StartedAt:   started,
+		CompletedAt: completed,
+		DueDate:     due,
+	}
+	require.NoError(t, f.db.Create(&task).Error)
+}
+
+// createTestUser adds an active user named username and returns its ID.
+func createTestUser(t testing.TB, db *gorm.DB, username string) uuid.UUID {
+	id := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec(`INSERT INTO users (id, username, email, password, is_active) VALUES (?, ?, ?, 'hash', true)`,
+		id, username, username+"@example.com").Error)
+	return id
+}
+
+// createOutboxTable creates the outbox table for suites that set up the rest
+// of their schema themselves.
+func createOutboxTable(db *gorm.DB) error {
+	return db.Exec(testSchema["outbox"]).Error
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	denylistTokenKeyPrefix = "auth:denylist:jti:"
+	denylistUserKeyPrefix  = "auth:denylist:user:"
+)
+
+// TokenDenylist records access tokens revoked before they expire: single
+// tokens by JTI, and every token a user was issued before a point in time.
+// Entries expire with the tokens they cover. With a Redis client revocations
+// are shared by all instances; they are always also kept in process, so an
+// instance still honors its own revocations while Redis is unreachable.
+type TokenDenylist struct {
+	client *redis.Client
+
+	mu     sync.Mutex
+	tokens map[string]time.Time
+	users  map[uuid.UUID]userRevocation
+}
+
+type userRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+func NewTokenDenylist(client *redis.Client) *TokenDenylist {
+	return &TokenDenylist{
+		client: client,
+		tokens: make(map[string]time.Time),
+		users:  make(map[uuid.UUID]userRevocation),
+	}
+}
+
+// Revoke denies the token with the JTI until it expires.
+func (d *TokenDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	d.pruneLocked(time.Now())
+	d.tokens[jti] = expiresAt
+	d.mu.Unlock()
+
+	if d.client == nil {
+		return nil
+	}
+	return d.client.Set(ctx, denylistTokenKeyPrefix+jti, 1, ttl).Err()
+}
+
+// RevokeUser denies every access token issued to the user before now. The
+// entry lasts for the access token lifetime, after which those tokens have
+// expired anyway. Access tokens carry their issue time in microseconds, so a
+// login right after the revocation is not caught by it.
+func (d *TokenDenylist) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	issuedBefore := now
+	expiresAt := issuedBefore.Add(AccessTokenTTL)
+
+	d.mu.Lock()
+	d.pruneLocked(now)
+	d.users[userID] = userRevocation{issuedBefore: issuedBefore, expiresAt: expiresAt}
+	d.mu.Unlock()
+
+	if d.client == nil {
+		return nil
+	}
+	return d.client.Set(ctx, denylistUserKeyPrefix+userID.String(), issuedBefore.UnixNano(), time.Until(expiresAt)).Err()
+}
+
+// IsRevoked reports whether a token with the JTI, issued to the user at
+// issuedAt, has been revoked. Redis errors are logged and only the in-process
+// entries are consulted.
+func (d *TokenDenylist) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) bool {
+	now := time.Now()
+
+	d.mu.Lock()
+	expiresAt, tokenRevoked := d.tokens[jti]
+	tokenRevoked = tokenRevoked && now.Before(expiresAt)
+	user, userRevoked := d.users[userID]
+	userRevoked = userRevoked && now.Before(user.expiresAt) && issuedAt.Before(user.issuedBefore)
+	d.mu.Unlock()
+
+	if tokenRevoked || userRevoked || d.client == nil {
+		return tokenRevoked || userRevoked
+	}
+
+	keys := []string{denylistUserKeyPrefix + userID.String()}
+	if jti != "" {
+		keys = append(keys, denylistTokenKeyPrefix+jti)
+	}
+	values, err := d.client.MGet(ctx, keys...).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("⚠️  Token denylist lookup failed: %v", err)
+		return false
+	}
+
+	if len(values) > 1 && values[1] != nil {
+		return true
+	}
+	if raw, ok := values[0].(string); ok {
+		if before, err := strconv.ParseInt(raw, 10, 64); err == nil && issuedAt.Before(time.Unix(0, before)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *TokenDenylist) pruneLocked(now time.Time) {
+	for jti, expiresAt := range d.tokens {
+		if !now.Before(expiresAt) {
+			delete(d.tokens, jti)
+		}
+	}
+	for userID, revocation := range d.users {
+		if !now.Before(revocation.expiresAt) {
+			delete(d.users, userID)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-manager/backend/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenDenylist_InProcess(t *testing.T) {
+	ctx := context.Background()
+	denylist := services.NewTokenDenylist(nil)
+	userID := uuid.Must(uuid.NewV4())
+	issuedAt := time.Now().Add(-time.Minute)
+
+	assert.False(t, denylist.IsRevoked(ctx, "a", userID, issuedAt))
+
+	require.NoError(t, denylist.Revoke(ctx, "a", time.Now().Add(time.Hour)))
+	assert.True(t, denylist.IsRevoked(ctx, "a", userID, issuedAt))
+	assert.False(t, denylist.IsRevoked(ctx, "b", userID, issuedAt))
+
+	require.NoError(t, denylist.Revoke(ctx, "expired", time.Now().Add(-time.Second)))
+	assert.False(t, denylist.IsRevoked(ctx, "expired", userID, issuedAt), "expired tokens need no entry")
+
+	issuedJustBefore := services.IssuedAtFromClaim(services.IssuedAtClaim(time.Now()))
+	require.NoError(t, denylist.RevokeUser(ctx, userID))
+	issuedJustAfter := services.IssuedAtFromClaim(services.IssuedAtClaim(time.Now()))
+	assert.True(t, denylist.IsRevoked(ctx, "b", userID, issuedAt))
+	assert.True(t, denylist.IsRevoked(ctx, "b", userID, issuedJustBefore))
+	assert.False(t, denylist.IsRevoked(ctx, "c", userID, issuedJustAfter), "tokens issued after logout still work, even within the same second")
+	assert.False(t, denylist.IsRevoked(ctx, "b", uuid.Must(uuid.NewV4()), issuedAt))
+}
+
+func TestTokenDenylist_SharedThroughRedis(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	newClient := func() *redis.Client {
+		return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	}
+
+	first := services.NewTokenDenylist(newClient())
+	second := services.NewTokenDenylist(newClient())
+	userID := uuid.Must(uuid.NewV4())
+	issuedAt := time.Now().Add(-time.Minute)
+
+	require.NoError(t, first.Revoke(ctx, "a", time.Now().Add(time.Hour)))
+	assert.True(t, second.IsRevoked(ctx, "a", userID, issuedAt))
+	assert.True(t, mr.TTL("auth:denylist:jti:a") > 0, "entries expire with the token")
+
+	issuedJustBefore := services.IssuedAtFromClaim(services.IssuedAtClaim(time.Now()))
+	require.NoError(t, first.RevokeUser(ctx, userID))
+	issuedJustAfter := services.IssuedAtFromClaim(services.IssuedAtClaim(time.Now()))
+	assert.True(t, second.IsRevoked(ctx, "b", userID, issuedAt))
+	assert.True(t, second.IsRevoked(ctx, "b", userID, issuedJustBefore))
+	assert.False(t, second.IsRevoked(ctx, "b", userID, issuedJustAfter))
+
+	mr.Close()
+	assert.True(t, first.IsRevoked(ctx, "a", userID, issuedAt), "local revocations survive a Redis outage")
+	assert.False(t, second.IsRevoked(ctx, "c", uuid.Must(uuid.NewV4()), issuedAt))
+}
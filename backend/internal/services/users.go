@@ -1,6 +1,8 @@
 package services
 
 import (
+	"sort"
+
 	"task-manager/backend/internal/models"
 
 	"github.com/gofrs/uuid"
@@ -36,7 +38,7 @@ func (s *UserServiceImpl) GetUserProfileMalicious(db *gorm.DB, userID string) ([
 func (s *UserServiceImpl) GetUserProfile(db *gorm.DB, userID uuid.UUID) (models.User, error) {
 	var user models.User
 
-	result := db.Where("id = ?", userID).Find(&user)
+	result := workspaceMembersOnly(db, "id").Where("id = ?", userID).Find(&user)
 	if result.Error != nil {
 		return models.User{}, result.Error
 	}
@@ -44,10 +46,12 @@ func (s *UserServiceImpl) GetUserProfile(db *gorm.DB, userID uuid.UUID) (models.
 	return user, nil
 }
 
+// GetUsers lists the members of the workspace in db's context, or every user
+// when there is none.
 func (s *UserServiceImpl) GetUsers(db *gorm.DB) ([]models.User, error) {
 	var user []models.User
 
-	result := db.Find(&user)
+	result := workspaceMembersOnly(db, "id").Find(&user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -56,17 +60,42 @@ func (s *UserServiceImpl) GetUsers(db *gorm.DB) ([]models.User, error) {
 }
 
 func (s *UserServiceImpl) DeleteUser(db *gorm.DB, userId uuid.UUID) error {
-	result := db.Delete(&models.User{}, "id = ?", userId)
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return result.Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.User{}, "id = ?", userId)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		_, err := WriteOutbox(tx, models.OutboxAggregateUser, userId, models.UserEventDeleted, map[string]interface{}{
+			"user_id": userId.String(),
+		})
+		return err
+	})
 }
 
 func (s *UserServiceImpl) UpdateUser(db *gorm.DB, userID uuid.UUID, updates map[string]interface{}) error {
-	result := db.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return result.Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		fields := make([]string, 0, len(updates))
+		for field := range updates {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		_, err := WriteOutbox(tx, models.OutboxAggregateUser, userID, models.UserEventUpdated, map[string]interface{}{
+			"user_id": userID.String(),
+			"fields":  fields,
+		})
+		return err
+	})
 }
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const notificationQueue = "default"
+
+type TaskEventPublisher interface {
+	PublishTaskEvent(db *gorm.DB, event models.TaskEvent) error
+}
+
+type WatcherService interface {
+	TaskEventPublisher
+
+	Watch(db *gorm.DB, taskID, userID uuid.UUID, reason string) error
+	Unwatch(db *gorm.DB, taskID, userID uuid.UUID) error
+	GetWatchers(db *gorm.DB, taskID uuid.UUID) ([]models.TaskWatcher, error)
+	IsWatching(db *gorm.DB, taskID, userID uuid.UUID) (bool, error)
+
+	DeliverTaskEvent(db *gorm.DB, event models.TaskEvent) error
+}
+
+type WatcherServiceImpl struct {
+	queue         *worker.JobQueue
+	notifications NotificationService
+}
+
+// NewWatcherService creates a watcher service. When queue is nil (Redis is
+// unavailable) task events are fanned out inline instead of through the worker.
+func NewWatcherService(queue *worker.JobQueue, notifications NotificationService) *WatcherServiceImpl {
+	return &WatcherServiceImpl{queue: queue, notifications: notifications}
+}
+
+func (s *WatcherServiceImpl) Watch(db *gorm.DB, taskID, userID uuid.UUID, reason string) error {
+	if reason == "" {
+		reason = models.WatchReasonManual
+	}
+
+	watcher := models.TaskWatcher{
+		TaskID:    taskID,
+		UserID:    userID,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&watcher).Error
+}
+
+func (s *WatcherServiceImpl) Unwatch(db *gorm.DB, taskID, userID uuid.UUID) error {
+	return db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskWatcher{}).Error
+}
+
+func (s *WatcherServiceImpl) GetWatchers(db *gorm.DB, taskID uuid.UUID) ([]models.TaskWatcher, error) {
+	var watchers []models.TaskWatcher
+	err := db.Where("task_id = ?", taskID).Order("created_at").Find(&watchers).Error
+	return watchers, err
+}
+
+func (s *WatcherServiceImpl) IsWatching(db *gorm.DB, taskID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.TaskWatcher{}).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// PublishTaskEvent hands the event to the worker queue for fan-out. Task owners
+// start watching their own tasks when the creation event is published.
+func (s *WatcherServiceImpl) PublishTaskEvent(db *gorm.DB, event models.TaskEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	if event.Type == models.TaskEventCreated && event.OwnerID != uuid.Nil {
+		if err := s.Watch(db, event.TaskID, event.OwnerID, models.WatchReasonOwner); err != nil {
+			return fmt.Errorf("failed to auto-watch task: %w", err)
+		}
+	}
+
+	if s.queue == nil {
+		return s.DeliverTaskEvent(db, event)
+	}
+
+	return s.queue.Enqueue(notificationQueue, worker.JobTypeTaskEvent, event.ToPayload())
+}
+
+// DeliverTaskEvent notifies every watcher except the actor. Watchers of a
+// deleted task are removed once they have been told.
+func (s *WatcherServiceImpl) DeliverTaskEvent(db *gorm.DB, event models.TaskEvent) error {
+	var recipients []NotificationRecipient
+	err := db.Raw(`SELECT tw.user_id, u.email FROM task_watchers tw
+		JOIN users u ON u.id = tw.user_id
+		WHERE tw.task_id = ? AND tw.user_id <> ? AND u.deleted_at IS NULL`,
+		event.TaskID, event.ActorID).Scan(&recipients).Error
+	if err != nil {
+		return fmt.Errorf("failed to load watchers: %w", err)
+	}
+
+	if s.notifications != nil {
+		if err := s.notifications.Dispatch(db, recipients, taskEventNotification(event)); err != nil {
+			return err
+		}
+	}
+
+	if event.Type == models.TaskEventDeleted {
+		if err := db.Where("task_id = ?", event.TaskID).Delete(&models.TaskWatcher{}).Error; err != nil {
+			return fmt.Errorf("failed to remove watchers of deleted task: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func taskEventNotification(event models.TaskEvent) models.Notification {
+	taskID := event.TaskID
+	notification := models.Notification{
+		Type:         event.Type,
+		Title:        taskEventSubject(event),
+		ResourceType: "task",
+		ResourceID:   &taskID,
+		CreatedAt:    event.OccurredAt,
+	}
+
+	if len(event.Changes) > 0 {
+		if data, err := json.Marshal(event.Changes); err == nil {
+			notification.Data = string(data)
+		}
+	}
+
+	if description, ok := event.Changes["description"]; ok {
+		notification.Body = description
+	}
+
+	return notification
+}
+
+func taskEventSubject(event models.TaskEvent) string {
+	switch event.Type {
+	case models.TaskEventStatusChanged:
+		return fmt.Sprintf("Task %q is now %s", event.Title, event.Changes["status"])
+	case models.TaskEventDeleted:
+		return fmt.Sprintf("Task %q was deleted", event.Title)
+	case models.TaskEventCreated:
+		return fmt.Sprintf("Task %q was created", event.Title)
+	default:
+		return fmt.Sprintf("Task %q was updated", event.Title)
+	}
+}
+
+// NewTaskEventJobHandler returns the worker handler that fans queued task
+// events out to watchers.
+func NewTaskEventJobHandler(db *gorm.DB, watcherService WatcherService) worker.JobHandler {
+	return func(ctx context.Context, job *worker.Job) error {
+		event, err := models.TaskEventFromPayload(job.Payload)
+		if err != nil {
+			return err
+		}
+		return watcherService.DeliverTaskEvent(db.WithContext(ctx), event)
+	}
+}
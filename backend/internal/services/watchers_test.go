@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/worker"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func createWatcherTestUser(t *testing.T, db *gorm.DB, email string) uuid.UUID {
+	id := uuid.Must(uuid.NewV4())
+	require.NoError(t, db.Exec("INSERT INTO users (id, username, email) VALUES (?, ?, ?)", id, email, email).Error)
+	return id
+}
+
+func setupWatcherQueue(t *testing.T) (*worker.JobQueue, *redis.Client) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return worker.NewJobQueue(client), client
+}
+
+func queuedJobs(t *testing.T, client *redis.Client) []worker.Job {
+	raw, err := client.LRange(context.Background(), "default", 0, -1).Result()
+	require.NoError(t, err)
+
+	jobs := make([]worker.Job, 0, len(raw))
+	for _, data := range raw {
+		var job worker.Job
+		require.NoError(t, json.Unmarshal([]byte(data), &job))
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func TestWatcherService_WatchIsIdempotent(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	svc := services.NewWatcherService(nil, services.NewNotificationService(services.NewInAppNotifier()))
+
+	taskID := uuid.Must(uuid.NewV4())
+	userID := createWatcherTestUser(t, db, "watcher@example.com")
+
+	require.NoError(t, svc.Watch(db, taskID, userID, ""))
+	require.NoError(t, svc.Watch(db, taskID, userID, models.WatchReasonOwner))
+
+	watchers, err := svc.GetWatchers(db, taskID)
+	require.NoError(t, err)
+	require.Len(t, watchers, 1)
+	assert.Equal(t, models.WatchReasonManual, watchers[0].Reason)
+
+	require.NoError(t, svc.Unwatch(db, taskID, userID))
+
+	watching, err := svc.IsWatching(db, taskID, userID)
+	require.NoError(t, err)
+	assert.False(t, watching)
+}
+
+func TestWatcherService_PublishCreatedAutoWatchesOwner(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	queue, client := setupWatcherQueue(t)
+	svc := services.NewWatcherService(queue, services.NewNotificationService(
+		services.NewInAppNotifier(),
+		services.NewEmailNotifier(queue),
+	))
+
+	ownerID := createWatcherTestUser(t, db, "owner@example.com")
+	taskID := uuid.Must(uuid.NewV4())
+
+	err := svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventCreated,
+		TaskID:  taskID,
+		OwnerID: ownerID,
+		ActorID: ownerID,
+		Title:   "Write report",
+	})
+	require.NoError(t, err)
+
+	watching, err := svc.IsWatching(db, taskID, ownerID)
+	require.NoError(t, err)
+	assert.True(t, watching)
+
+	jobs := queuedJobs(t, client)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, worker.JobTypeTaskEvent, jobs[0].Type)
+
+	event, err := models.TaskEventFromPayload(jobs[0].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, taskID, event.TaskID)
+	assert.Equal(t, "Write report", event.Title)
+}
+
+func TestWatcherService_DeliverSkipsActorAndRespectsPreferences(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	queue, client := setupWatcherQueue(t)
+	notifications := services.NewNotificationService(services.NewInAppNotifier(), services.NewEmailNotifier(queue))
+	svc := services.NewWatcherService(queue, notifications)
+
+	taskID := uuid.Must(uuid.NewV4())
+	actorID := createWatcherTestUser(t, db, "actor@example.com")
+	followerID := createWatcherTestUser(t, db, "follower@example.com")
+	mutedID := createWatcherTestUser(t, db, "muted@example.com")
+
+	for _, id := range []uuid.UUID{actorID, followerID, mutedID} {
+		require.NoError(t, svc.Watch(db, taskID, id, models.WatchReasonManual))
+	}
+	require.NoError(t, notifications.SetNotificationPreference(db, models.NotificationPreference{
+		UserID:       mutedID,
+		EventType:    models.TaskEventStatusChanged,
+		EmailEnabled: false,
+		InAppEnabled: true,
+	}))
+
+	err := svc.DeliverTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventStatusChanged,
+		TaskID:  taskID,
+		ActorID: actorID,
+		Title:   "Ship release",
+		Changes: map[string]string{"status": "completed", "previous_status": "pending"},
+	})
+	require.NoError(t, err)
+
+	jobs := queuedJobs(t, client)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, worker.JobTypeEmailNotification, jobs[0].Type)
+	assert.Equal(t, "follower@example.com", jobs[0].Payload["email"])
+	assert.Equal(t, `Task "Ship release" is now completed`, jobs[0].Payload["subject"])
+
+	for _, id := range []uuid.UUID{followerID, mutedID} {
+		unread, err := notifications.CountUnread(db, id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), unread)
+	}
+
+	unread, err := notifications.CountUnread(db, actorID)
+	require.NoError(t, err)
+	assert.Zero(t, unread)
+}
+
+func TestWatcherService_DeliverDeletedRemovesWatchers(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	svc := services.NewWatcherService(nil, services.NewNotificationService(services.NewInAppNotifier()))
+
+	taskID := uuid.Must(uuid.NewV4())
+	userID := createWatcherTestUser(t, db, "gone@example.com")
+	require.NoError(t, svc.Watch(db, taskID, userID, models.WatchReasonManual))
+
+	err := svc.DeliverTaskEvent(db, models.TaskEvent{
+		Type:   models.TaskEventDeleted,
+		TaskID: taskID,
+		Title:  "Old task",
+	})
+	require.NoError(t, err)
+
+	watchers, err := svc.GetWatchers(db, taskID)
+	require.NoError(t, err)
+	assert.Empty(t, watchers)
+}
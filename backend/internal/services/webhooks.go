@@ -0,0 +1,498 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookQueue           = "default"
+	webhookUserAgent       = "Taskify-Webhooks/1.0"
+	webhookResponseMaxBody = 4096
+)
+
+var (
+	ErrWebhookQueueUnavailable = errors.New("webhook delivery requires the job queue")
+	errWebhookPrivateTarget    = errors.New("webhook target resolves to a private address")
+)
+
+// WebhookOptions tunes delivery. AllowPrivateTargets permits loopback and
+// private network receivers, which is needed for local development and tests
+// but would otherwise let users probe internal services.
+type WebhookOptions struct {
+	MaxAttempts         int
+	FailureThreshold    int
+	Timeout             time.Duration
+	AllowPrivateTargets bool
+}
+
+// WebhookPayload is the signed JSON body posted to receivers. EventID stays
+// the same across redeliveries so receivers can deduplicate.
+type WebhookPayload struct {
+	EventID    uuid.UUID        `json:"event_id"`
+	Type       string           `json:"type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       models.TaskEvent `json:"data"`
+}
+
+type WebhookService interface {
+	TaskEventPublisher
+
+	CreateWebhook(db *gorm.DB, webhook *models.Webhook) error
+	GetWebhooks(db *gorm.DB, userID *uuid.UUID) ([]models.Webhook, error)
+	GetWebhook(db *gorm.DB, id uuid.UUID) (*models.Webhook, error)
+	UpdateWebhook(db *gorm.DB, webhook *models.Webhook) error
+	DeleteWebhook(db *gorm.DB, id uuid.UUID) error
+
+	GetDeliveries(db *gorm.DB, webhookID uuid.UUID, page, pageSize int) ([]models.WebhookDelivery, int64, error)
+	Redeliver(db *gorm.DB, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	Deliver(ctx context.Context, db *gorm.DB, deliveryID uuid.UUID, attempt int, final bool) error
+}
+
+type WebhookServiceImpl struct {
+	queue        *worker.JobQueue
+	authzService AuthorizationService
+	options      WebhookOptions
+	client       *http.Client
+}
+
+// NewWebhookService creates a webhook service. authzService decides whether a
+// webhook owner may see events for tasks they do not own; when nil, webhooks
+// only receive events for their owner's tasks.
+func NewWebhookService(queue *worker.JobQueue, authzService AuthorizationService, options WebhookOptions) *WebhookServiceImpl {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 5
+	}
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = 5
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 10 * time.Second
+	}
+
+	return &WebhookServiceImpl{
+		queue:        queue,
+		authzService: authzService,
+		options:      options,
+		client:       newWebhookClient(options),
+	}
+}
+
+func newWebhookClient(options WebhookOptions) *http.Client {
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	if !options.AllowPrivateTargets {
+		// Checked on the resolved address, so DNS cannot be used to sneak past it.
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+				return errWebhookPrivateTarget
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// GenerateWebhookSecret returns a random secret for signing deliveries.
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// SignWebhookPayload computes the X-Webhook-Signature value. The timestamp is
+// signed with the body so a captured request cannot be replayed later.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (s *WebhookServiceImpl) CreateWebhook(db *gorm.DB, webhook *models.Webhook) error {
+	if webhook.Secret == "" {
+		secret, err := GenerateWebhookSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		webhook.Secret = secret
+	}
+
+	webhook.ID = uuid.Must(uuid.NewV4())
+	webhook.IsActive = true
+	return db.Create(webhook).Error
+}
+
+// GetWebhooks lists a user's webhooks, or every webhook when userID is nil.
+// Under a request context both are limited to the request's workspace.
+func (s *WebhookServiceImpl) GetWebhooks(db *gorm.DB, userID *uuid.UUID) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+	query := db.Order("created_at DESC")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (s *WebhookServiceImpl) GetWebhook(db *gorm.DB, id uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := db.Where("id = ?", id).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (s *WebhookServiceImpl) UpdateWebhook(db *gorm.DB, webhook *models.Webhook) error {
+	return db.Save(webhook).Error
+}
+
+func (s *WebhookServiceImpl) DeleteWebhook(db *gorm.DB, id uuid.UUID) error {
+	result := db.Where("id = ?", id).Delete(&models.Webhook{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *WebhookServiceImpl) GetDeliveries(db *gorm.DB, webhookID uuid.UUID, page, pageSize int) ([]models.WebhookDelivery, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// PublishTaskEvent queues a delivery for every active webhook in the task's
+// workspace that subscribes to the event and whose owner may read the task.
+// Events relayed from the outbox carry an EventID; webhooks that already have
+// a delivery for it are skipped, so a relay retry does not post the event
+// twice.
+func (s *WebhookServiceImpl) PublishTaskEvent(db *gorm.DB, event models.TaskEvent) error {
+	if s.queue == nil {
+		return nil
+	}
+
+	// The owner's access is checked inside the event's workspace, so a
+	// webhook never learns about another tenant's tasks.
+	ctx := context.Background()
+	query := db.Where("is_active = ?", true)
+	if event.WorkspaceID != nil {
+		ctx = WithWorkspace(ctx, *event.WorkspaceID)
+		defer ReleaseWorkspaceSession(ctx)
+		query = query.Where("workspace_id = ?", *event.WorkspaceID)
+	} else {
+		query = query.Where("workspace_id IS NULL")
+	}
+
+	var webhooks []models.Webhook
+	if err := query.Find(&webhooks).Error; err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	eventID := event.EventID
+	if eventID == uuid.Nil {
+		eventID = uuid.Must(uuid.NewV4())
+	}
+
+	payload, err := json.Marshal(WebhookPayload{
+		EventID:    eventID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       event,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	var errs []error
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		if !webhook.Subscribes(event.Type) || !s.canSee(ctx, webhook, event) {
+			continue
+		}
+
+		var existing int64
+		if err := db.Model(&models.WebhookDelivery{}).
+			Where("webhook_id = ? AND event_id = ? AND redelivery_of IS NULL", webhook.ID, eventID).
+			Count(&existing).Error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if existing > 0 {
+			continue
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventType: event.Type,
+			Payload:   string(payload),
+			EventID:   &eventID,
+		}
+		if err := s.queueDelivery(db, &delivery); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redeliver queues a fresh attempt with the original payload. The original
+// delivery is kept so the log shows both.
+func (s *WebhookServiceImpl) Redeliver(db *gorm.DB, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	if s.queue == nil {
+		return nil, ErrWebhookQueueUnavailable
+	}
+
+	var original models.WebhookDelivery
+	if err := db.Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&original).Error; err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:    webhookID,
+		EventType:    original.EventType,
+		Payload:      original.Payload,
+		EventID:      original.EventID,
+		RedeliveryOf: &original.ID,
+	}
+	if err := s.queueDelivery(db, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (s *WebhookServiceImpl) queueDelivery(db *gorm.DB, delivery *models.WebhookDelivery) error {
+	delivery.ID = uuid.Must(uuid.NewV4())
+	delivery.Status = models.WebhookDeliveryPending
+
+	if err := db.Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return s.queue.EnqueueWithMaxTries(webhookQueue, worker.JobTypeWebhookDelivery, map[string]interface{}{
+		"delivery_id": delivery.ID.String(),
+	}, s.options.MaxAttempts)
+}
+
+func (s *WebhookServiceImpl) canSee(ctx context.Context, webhook *models.Webhook, event models.TaskEvent) bool {
+	if webhook.UserID == event.OwnerID {
+		return true
+	}
+	if s.authzService == nil {
+		return false
+	}
+
+	taskID := event.TaskID
+	decision, err := s.authzService.IsAuthorized(ctx, AuthorizationRequest{
+		UserID:     webhook.UserID,
+		Resource:   "task",
+		Action:     "read",
+		ResourceID: &taskID,
+	})
+	if err != nil {
+		log.Printf("Webhook %s authorization check failed: %v", webhook.ID, err)
+		return false
+	}
+	return decision.Decision == "allowed"
+}
+
+// Deliver posts one attempt of a delivery. A failed attempt returns an error
+// so the worker retries it with backoff; on the final attempt the webhook's
+// failure streak grows and the webhook is disabled once it hits the threshold.
+func (s *WebhookServiceImpl) Deliver(ctx context.Context, db *gorm.DB, deliveryID uuid.UUID, attempt int, final bool) error {
+	var delivery models.WebhookDelivery
+	if err := db.Where("id = ?", deliveryID).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The webhook was deleted and its deliveries with it.
+			return nil
+		}
+		return err
+	}
+
+	webhook, err := s.GetWebhook(db, delivery.WebhookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if !webhook.IsActive {
+		return db.Model(&delivery).Updates(map[string]interface{}{
+			"status": models.WebhookDeliveryFailed,
+			"error":  "webhook is disabled",
+		}).Error
+	}
+
+	started := time.Now()
+	status, body, sendErr := s.send(ctx, webhook, &delivery)
+	duration := time.Since(started).Milliseconds()
+
+	updates := map[string]interface{}{
+		"attempts":      attempt,
+		"duration_ms":   duration,
+		"response_body": body,
+		"error":         "",
+	}
+	if status > 0 {
+		updates["response_status"] = status
+	}
+
+	if sendErr == nil {
+		now := time.Now()
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["delivered_at"] = now
+		if err := db.Model(&delivery).Updates(updates).Error; err != nil {
+			return err
+		}
+		if webhook.ConsecutiveFailures > 0 {
+			return db.Model(webhook).Update("consecutive_failures", 0).Error
+		}
+		return nil
+	}
+
+	updates["error"] = sendErr.Error()
+	updates["status"] = models.WebhookDeliveryRetrying
+	if final {
+		updates["status"] = models.WebhookDeliveryFailed
+	}
+	if err := db.Model(&delivery).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	if final {
+		if err := s.recordFailure(db, webhook); err != nil {
+			log.Printf("Failed to record failure for webhook %s: %v", webhook.ID, err)
+		}
+	}
+
+	return sendErr
+}
+
+func (s *WebhookServiceImpl) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String())
+	req.Header.Set("X-Webhook-Signature", SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseMaxBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("webhook receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
+
+func (s *WebhookServiceImpl) recordFailure(db *gorm.DB, webhook *models.Webhook) error {
+	err := db.Model(webhook).
+		Update("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error
+	if err != nil {
+		return err
+	}
+
+	var failures int
+	if err := db.Model(&models.Webhook{}).Where("id = ?", webhook.ID).
+		Select("consecutive_failures").Scan(&failures).Error; err != nil {
+		return err
+	}
+
+	if failures < s.options.FailureThreshold {
+		return nil
+	}
+
+	log.Printf("Disabling webhook %s after %d consecutive failed deliveries", webhook.ID, failures)
+	return db.Model(&models.Webhook{}).Where("id = ?", webhook.ID).Updates(map[string]interface{}{
+		"is_active":       false,
+		"disabled_at":     time.Now(),
+		"disabled_reason": fmt.Sprintf("disabled after %d consecutive failed deliveries", failures),
+	}).Error
+}
+
+// NewWebhookDeliveryJobHandler returns the worker handler for webhook
+// deliveries. Retries and the dead queue are left to the worker.
+func NewWebhookDeliveryJobHandler(db *gorm.DB, webhookService WebhookService) worker.JobHandler {
+	return func(ctx context.Context, job *worker.Job) error {
+		deliveryID, err := uuid.FromString(fmt.Sprintf("%v", job.Payload["delivery_id"]))
+		if err != nil {
+			return fmt.Errorf("invalid delivery_id in payload: %w", err)
+		}
+
+		attempt := job.Attempts + 1
+		return webhookService.Deliver(ctx, db.WithContext(ctx), deliveryID, attempt, attempt >= job.MaxTries)
+	}
+}
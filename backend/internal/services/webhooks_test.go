@@ -0,0 +1,354 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/worker"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupWebhookTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec(`CREATE TABLE webhooks (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		workspace_id TEXT,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		is_active BOOLEAN NOT NULL DEFAULT true,
+		consecutive_failures INTEGER NOT NULL DEFAULT 0,
+		disabled_at DATETIME,
+		disabled_reason TEXT,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`).Error)
+
+	require.NoError(t, db.Exec(`CREATE TABLE webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		webhook_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		response_status INTEGER,
+		response_body TEXT,
+		error TEXT,
+		duration_ms INTEGER,
+		redelivery_of TEXT,
+		event_id TEXT,
+		delivered_at DATETIME,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`).Error)
+
+	return db
+}
+
+// webhookReceiver is a local endpoint that verifies signatures and answers
+// with the configured status code.
+type webhookReceiver struct {
+	server   *httptest.Server
+	secret   string
+	status   atomic.Int32
+	received atomic.Int32
+	verified atomic.Int32
+}
+
+func newWebhookReceiver(t *testing.T, secret string) *webhookReceiver {
+	r := &webhookReceiver{secret: secret}
+	r.status.Store(http.StatusOK)
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.received.Add(1)
+		body, _ := io.ReadAll(req.Body)
+
+		signature := req.Header.Get("X-Webhook-Signature")
+		if ts, _, ok := strings.Cut(strings.TrimPrefix(signature, "t="), ","); ok {
+			timestamp, _ := strconv.ParseInt(ts, 10, 64)
+			if services.SignWebhookPayload(r.secret, timestamp, body) == signature {
+				r.verified.Add(1)
+			}
+		}
+
+		w.WriteHeader(int(r.status.Load()))
+		w.Write([]byte("ack"))
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func createTestWebhook(t *testing.T, db *gorm.DB, svc services.WebhookService, userID uuid.UUID, url string, events ...string) *models.Webhook {
+	webhook := &models.Webhook{UserID: userID, URL: url, Secret: "test-secret"}
+	webhook.SetEvents(events)
+	require.NoError(t, svc.CreateWebhook(db, webhook))
+	return webhook
+}
+
+func latestDelivery(t *testing.T, db *gorm.DB, webhookID uuid.UUID) models.WebhookDelivery {
+	var delivery models.WebhookDelivery
+	require.NoError(t, db.Where("webhook_id = ?", webhookID).Order("created_at DESC").First(&delivery).Error)
+	return delivery
+}
+
+func TestWebhookService_PublishQueuesMatchingWebhooks(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	queue, client := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{AllowPrivateTargets: true})
+
+	ownerID := uuid.Must(uuid.NewV4())
+	subscribed := createTestWebhook(t, db, svc, ownerID, "http://example.test/all", "*")
+	createTestWebhook(t, db, svc, ownerID, "http://example.test/deleted", models.TaskEventDeleted)
+	createTestWebhook(t, db, svc, uuid.Must(uuid.NewV4()), "http://example.test/other", "*")
+
+	err := svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventCreated,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: ownerID,
+		Title:   "Draft plan",
+	})
+	require.NoError(t, err)
+
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, db.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, subscribed.ID, deliveries[0].WebhookID)
+	assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+
+	var payload services.WebhookPayload
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, models.TaskEventCreated, payload.Type)
+	assert.Equal(t, "Draft plan", payload.Data.Title)
+
+	jobs := queuedJobs(t, client)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, worker.JobTypeWebhookDelivery, jobs[0].Type)
+	assert.Equal(t, 5, jobs[0].MaxTries)
+	assert.Equal(t, deliveries[0].ID.String(), jobs[0].Payload["delivery_id"])
+}
+
+func TestWebhookService_PublishStaysInWorkspace(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	require.NoError(t, services.RegisterTenantScope(db))
+	queue, _ := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{AllowPrivateTargets: true})
+
+	ownerID := uuid.Must(uuid.NewV4())
+	acme, globex := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	acmeDB := db.WithContext(services.WithWorkspace(context.Background(), acme))
+	globexDB := db.WithContext(services.WithWorkspace(context.Background(), globex))
+	acmeHook := createTestWebhook(t, acmeDB, svc, ownerID, "http://example.test/acme", "*")
+	createTestWebhook(t, globexDB, svc, ownerID, "http://example.test/globex", "*")
+	require.NotNil(t, acmeHook.WorkspaceID)
+	assert.Equal(t, acme, *acmeHook.WorkspaceID)
+
+	listed, err := svc.GetWebhooks(acmeDB, nil)
+	require.NoError(t, err)
+	require.Len(t, listed, 1, "listing every webhook stays within the workspace")
+	assert.Equal(t, acmeHook.ID, listed[0].ID)
+
+	require.NoError(t, svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:        models.TaskEventCreated,
+		TaskID:      uuid.Must(uuid.NewV4()),
+		WorkspaceID: &acme,
+		OwnerID:     ownerID,
+		Title:       "Acme roadmap",
+	}))
+
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, db.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1, "webhooks in other workspaces do not receive the event")
+	assert.Equal(t, acmeHook.ID, deliveries[0].WebhookID)
+}
+
+func TestWebhookService_PublishSkipsRelayedDuplicates(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	queue, client := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{AllowPrivateTargets: true})
+
+	ownerID := uuid.Must(uuid.NewV4())
+	createTestWebhook(t, db, svc, ownerID, "http://example.test/all", "*")
+
+	event := models.TaskEvent{
+		EventID: uuid.Must(uuid.NewV4()),
+		Type:    models.TaskEventCreated,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: ownerID,
+	}
+	require.NoError(t, svc.PublishTaskEvent(db, event))
+	require.NoError(t, svc.PublishTaskEvent(db, event))
+
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, db.Find(&deliveries).Error)
+	require.Len(t, deliveries, 1)
+	require.NotNil(t, deliveries[0].EventID)
+	assert.Equal(t, event.EventID, *deliveries[0].EventID)
+	assert.Len(t, queuedJobs(t, client), 1)
+
+	var payload services.WebhookPayload
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, event.EventID, payload.EventID)
+}
+
+func TestWebhookService_DeliverSignsRequest(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	queue, _ := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{AllowPrivateTargets: true})
+	receiver := newWebhookReceiver(t, "test-secret")
+
+	ownerID := uuid.Must(uuid.NewV4())
+	webhook := createTestWebhook(t, db, svc, ownerID, receiver.server.URL, "*")
+	require.NoError(t, svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventUpdated,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: ownerID,
+	}))
+
+	delivery := latestDelivery(t, db, webhook.ID)
+	require.NoError(t, svc.Deliver(context.Background(), db, delivery.ID, 1, false))
+
+	assert.Equal(t, int32(1), receiver.verified.Load(), "signature should verify with the shared secret")
+
+	delivery = latestDelivery(t, db, webhook.ID)
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	require.NotNil(t, delivery.ResponseStatus)
+	assert.Equal(t, http.StatusOK, *delivery.ResponseStatus)
+	assert.Equal(t, "ack", delivery.ResponseBody)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestWebhookService_FailuresRetryThenDisable(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	queue, _ := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{
+		AllowPrivateTargets: true,
+		FailureThreshold:    2,
+	})
+	receiver := newWebhookReceiver(t, "test-secret")
+	receiver.status.Store(http.StatusInternalServerError)
+
+	ownerID := uuid.Must(uuid.NewV4())
+	webhook := createTestWebhook(t, db, svc, ownerID, receiver.server.URL, "*")
+	handler := services.NewWebhookDeliveryJobHandler(db, svc)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, svc.PublishTaskEvent(db, models.TaskEvent{
+			Type:    models.TaskEventUpdated,
+			TaskID:  uuid.Must(uuid.NewV4()),
+			OwnerID: ownerID,
+		}))
+		delivery := latestDelivery(t, db, webhook.ID)
+		job := &worker.Job{Payload: map[string]interface{}{"delivery_id": delivery.ID.String()}, MaxTries: 2}
+
+		require.Error(t, handler(context.Background(), job), "failed attempts are returned so the worker retries")
+		assert.Equal(t, models.WebhookDeliveryRetrying, latestDelivery(t, db, webhook.ID).Status)
+
+		job.Attempts = 1
+		require.Error(t, handler(context.Background(), job))
+		failed := latestDelivery(t, db, webhook.ID)
+		assert.Equal(t, models.WebhookDeliveryFailed, failed.Status)
+		assert.Equal(t, 2, failed.Attempts)
+		assert.Contains(t, failed.Error, "status 500")
+	}
+
+	reloaded, err := svc.GetWebhook(db, webhook.ID)
+	require.NoError(t, err)
+	assert.False(t, reloaded.IsActive)
+	assert.Equal(t, 2, reloaded.ConsecutiveFailures)
+	assert.NotNil(t, reloaded.DisabledAt)
+
+	// Disabled webhooks no longer receive events.
+	require.NoError(t, svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventUpdated,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: ownerID,
+	}))
+	_, total, err := svc.GetDeliveries(db, webhook.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestWebhookService_SuccessResetsFailureStreak(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	queue, _ := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{AllowPrivateTargets: true})
+	receiver := newWebhookReceiver(t, "test-secret")
+
+	ownerID := uuid.Must(uuid.NewV4())
+	webhook := createTestWebhook(t, db, svc, ownerID, receiver.server.URL, "*")
+	require.NoError(t, db.Model(webhook).Update("consecutive_failures", 3).Error)
+
+	require.NoError(t, svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventUpdated,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: ownerID,
+	}))
+	require.NoError(t, svc.Deliver(context.Background(), db, latestDelivery(t, db, webhook.ID).ID, 1, false))
+
+	reloaded, err := svc.GetWebhook(db, webhook.ID)
+	require.NoError(t, err)
+	assert.Zero(t, reloaded.ConsecutiveFailures)
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	queue, client := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{AllowPrivateTargets: true})
+
+	ownerID := uuid.Must(uuid.NewV4())
+	webhook := createTestWebhook(t, db, svc, ownerID, "http://example.test/hook", "*")
+	require.NoError(t, svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventDeleted,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: ownerID,
+	}))
+	original := latestDelivery(t, db, webhook.ID)
+
+	redelivery, err := svc.Redeliver(db, webhook.ID, original.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, original.ID, redelivery.ID)
+	assert.Equal(t, original.Payload, redelivery.Payload)
+	require.NotNil(t, redelivery.RedeliveryOf)
+	assert.Equal(t, original.ID, *redelivery.RedeliveryOf)
+	assert.Len(t, queuedJobs(t, client), 2)
+
+	_, err = svc.Redeliver(db, uuid.Must(uuid.NewV4()), original.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestWebhookService_BlocksPrivateTargets(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	queue, _ := setupWatcherQueue(t)
+	svc := services.NewWebhookService(queue, nil, services.WebhookOptions{})
+	receiver := newWebhookReceiver(t, "test-secret")
+
+	ownerID := uuid.Must(uuid.NewV4())
+	webhook := createTestWebhook(t, db, svc, ownerID, receiver.server.URL, "*")
+	require.NoError(t, svc.PublishTaskEvent(db, models.TaskEvent{
+		Type:    models.TaskEventUpdated,
+		TaskID:  uuid.Must(uuid.NewV4()),
+		OwnerID: ownerID,
+	}))
+
+	err := svc.Deliver(context.Background(), db, latestDelivery(t, db, webhook.ID).ID, 1, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "private address")
+	assert.Zero(t, receiver.received.Load())
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// AssigneeWorkload is the open work of one user. Overloaded is set when the
+// user is over either limit in WorkloadOptions.
+type AssigneeWorkload struct {
+	UserID          uuid.UUID `json:"user_id"`
+	Username        string    `json:"username"`
+	OpenTasks       int64     `json:"open_tasks"`
+	InProgressTasks int64     `json:"in_progress_tasks"`
+	OverdueTasks    int64     `json:"overdue_tasks"`
+	EstimatedHours  float64   `json:"estimated_hours"`
+	UnestimatedOpen int64     `json:"unestimated_open_tasks"`
+	Overloaded      bool      `json:"overloaded"`
+}
+
+type DepartmentWorkload struct {
+	Department     string             `json:"department"`
+	OpenTasks      int64              `json:"open_tasks"`
+	OverdueTasks   int64              `json:"overdue_tasks"`
+	EstimatedHours float64            `json:"estimated_hours"`
+	Overloaded     int                `json:"overloaded_members"`
+	Members        []AssigneeWorkload `json:"members"`
+}
+
+type WorkloadOptions struct {
+	MaxOpenTasks      int
+	MaxEstimatedHours float64
+}
+
+type WorkloadService interface {
+	// Workload returns the open work of every active user, grouped by
+	// department. An empty department includes all departments.
+	Workload(db *gorm.DB, department string, now time.Time) ([]DepartmentWorkload, error)
+}
+
+type WorkloadServiceImpl struct {
+	opts WorkloadOptions
+}
+
+func NewWorkloadService(opts WorkloadOptions) *WorkloadServiceImpl {
+	if opts.MaxOpenTasks <= 0 {
+		opts.MaxOpenTasks = 10
+	}
+	if opts.MaxEstimatedHours <= 0 {
+		opts.MaxEstimatedHours = 40
+	}
+	return &WorkloadServiceImpl{opts: opts}
+}
+
+func (s *WorkloadServiceImpl) Workload(db *gorm.DB, department string, now time.Time) ([]DepartmentWorkload, error) {
+	var rows []struct {
+		AssigneeWorkload
+		Department string
+	}
+
+	// Users without open tasks are kept so idle members show up next to
+	// overloaded ones. Tasks count against their assignee, or their owner
+	// while unassigned.
+	taskJoin := "LEFT JOIN tasks t ON COALESCE(t.assignee_id, t.user_id) = u.id AND t.status NOT IN ?"
+	joinArgs := []interface{}{closedTaskStatuses}
+	if workspaceID, ok := WorkspaceFromContext(db.Statement.Context); ok {
+		taskJoin += " AND t.workspace_id = ?"
+		joinArgs = append(joinArgs, workspaceID)
+	}
+	query := workspaceMembersOnly(db.Table("users u"), "u.id").
+		Joins(taskJoin, joinArgs...).
+		Select(`u.id AS user_id, u.username, COALESCE(u.department, '') AS department,
+			COUNT(t.id) AS open_tasks,
+			COALESCE(SUM(CASE WHEN t.status = ? THEN 1 ELSE 0 END), 0) AS in_progress_tasks,
+			COALESCE(SUM(CASE WHEN t.due_date < ? THEN 1 ELSE 0 END), 0) AS overdue_tasks,
+			COALESCE(SUM(t.estimated_hours), 0) AS estimated_hours,
+			COALESCE(SUM(CASE WHEN t.id IS NOT NULL AND t.estimated_hours IS NULL THEN 1 ELSE 0 END), 0) AS unestimated_open`,
+			"in_progress", now).
+		Where("u.deleted_at IS NULL")
+	if department != "" {
+		query = query.Where("u.department = ?", department)
+	}
+
+	err := query.
+		Group("u.id, u.username, u.department").
+		Order("department, open_tasks DESC, u.username").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workload: %w", err)
+	}
+
+	departments := []DepartmentWorkload{}
+	for _, row := range rows {
+		member := row.AssigneeWorkload
+		member.EstimatedHours = math.Round(member.EstimatedHours*100) / 100
+		member.Overloaded = member.OpenTasks > int64(s.opts.MaxOpenTasks) || member.EstimatedHours > s.opts.MaxEstimatedHours
+
+		if len(departments) == 0 || departments[len(departments)-1].Department != row.Department {
+			departments = append(departments, DepartmentWorkload{Department: row.Department, Members: []AssigneeWorkload{}})
+		}
+		group := &departments[len(departments)-1]
+		group.Members = append(group.Members, member)
+		group.OpenTasks += member.OpenTasks
+		group.OverdueTasks += member.OverdueTasks
+		group.EstimatedHours += member.EstimatedHours
+		if member.Overloaded {
+			group.Overloaded++
+		}
+	}
+
+	return departments, nil
+}
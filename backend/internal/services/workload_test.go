@@ -0,0 +1,73 @@
+package services_test
+
+import (
+	"testing"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadService_GroupsByDepartment(t *testing.T) {
+	f := newTestFixture(t, "tasks")
+	workload := services.NewWorkloadService(services.WorkloadOptions{MaxOpenTasks: 2, MaxEstimatedHours: 10})
+	now := *fixtureTime(10, 12)
+	dave := createTestUser(t, f.db, "dave")
+	require.NoError(t, f.db.Exec("UPDATE users SET department = 'Engineering' WHERE id = ?", dave).Error)
+
+	hours := func(h float64) *float64 { return &h }
+	for _, task := range []models.Task{
+		{UserID: f.alice, Status: "pending", DueDate: fixtureTime(5, 9), EstimatedHours: hours(2.5)},
+		{UserID: f.alice, Status: "in_progress", DueDate: fixtureTime(12, 9), EstimatedHours: hours(4)},
+		{UserID: f.alice, Status: "pending"},
+		{UserID: f.alice, AssigneeID: &f.bob, Status: "pending", EstimatedHours: hours(1)},
+		{UserID: f.alice, Status: "done", DueDate: fixtureTime(1, 9), EstimatedHours: hours(20)},
+		{UserID: f.carol, Status: "pending", EstimatedHours: hours(12)},
+		{UserID: f.carol, Status: "cancelled", EstimatedHours: hours(12)},
+	} {
+		task.ID = uuid.Must(uuid.NewV4())
+		task.Title = "Task"
+		require.NoError(t, f.db.Create(&task).Error)
+	}
+
+	departments, err := workload.Workload(f.db, "", now)
+	require.NoError(t, err)
+	require.Len(t, departments, 2)
+
+	engineering := departments[0]
+	assert.Equal(t, "Engineering", engineering.Department)
+	assert.Equal(t, int64(4), engineering.OpenTasks)
+	assert.Equal(t, int64(1), engineering.OverdueTasks)
+	assert.Equal(t, 7.5, engineering.EstimatedHours)
+	assert.Equal(t, 1, engineering.Overloaded)
+	require.Len(t, engineering.Members, 3)
+	assert.Equal(t, services.AssigneeWorkload{
+		UserID:          f.alice,
+		Username:        "alice",
+		OpenTasks:       3,
+		InProgressTasks: 1,
+		OverdueTasks:    1,
+		EstimatedHours:  6.5,
+		UnestimatedOpen: 1,
+		Overloaded:      true,
+	}, engineering.Members[0])
+	assert.Equal(t, services.AssigneeWorkload{
+		UserID:         f.bob,
+		Username:       "bob",
+		OpenTasks:      1,
+		EstimatedHours: 1,
+	}, engineering.Members[1], "assigned tasks count against the assignee, not the owner")
+	assert.Equal(t, services.AssigneeWorkload{UserID: dave, Username: "dave"}, engineering.Members[2], "idle members are listed")
+
+	sales := departments[1]
+	require.Len(t, sales.Members, 1)
+	assert.True(t, sales.Members[0].Overloaded, "over the hour limit")
+
+	departments, err = workload.Workload(f.db, "Sales", now)
+	require.NoError(t, err)
+	require.Len(t, departments, 1)
+	assert.Equal(t, "Sales", departments[0].Department)
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+	ErrNotWorkspaceMember = errors.New("not a member of this workspace")
+	ErrInvalidWorkspace   = errors.New("invalid workspace")
+	ErrLastWorkspaceOwner = errors.New("workspace must keep at least one owner")
+)
+
+// tenantTables are the tables whose rows belong to a workspace. Queries on
+// them are filtered by the workspace carried in the statement context.
+var tenantTables = map[string]bool{
+	"tasks":      true,
+	"sprints":    true,
+	"audit_logs": true,
+	"teams":      true,
+	"webhooks":   true,
+}
+
+type workspaceContextKey struct{}
+
+// WithWorkspace returns a context whose database statements are limited to
+// the workspace. Pass it to queries with db.WithContext, and call
+// ReleaseWorkspaceSession once the context is no longer used.
+func WithWorkspace(ctx context.Context, workspaceID uuid.UUID) context.Context {
+	return context.WithValue(ctx, workspaceContextKey{}, &workspaceSession{workspaceID: workspaceID})
+}
+
+// WorkspaceFromContext returns the workspace set by WithWorkspace.
+func WorkspaceFromContext(ctx context.Context) (uuid.UUID, bool) {
+	session := workspaceSessionFrom(ctx)
+	if session == nil || session.workspaceID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return session.workspaceID, true
+}
+
+func workspaceSessionFrom(ctx context.Context) *workspaceSession {
+	if ctx == nil {
+		return nil
+	}
+	session, _ := ctx.Value(workspaceContextKey{}).(*workspaceSession)
+	return session
+}
+
+// RegisterTenantScope installs callbacks that filter queries, updates and
+// deletes on tenant tables by the context's workspace and stamp it on new
+// rows. Statements without a workspace in their context, such as background
+// jobs, are left unscoped. Raw SQL and queries built with Table are not seen
+// by the callbacks and have to filter with workspaceScope themselves.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", stampWorkspace); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", filterWorkspace); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", filterWorkspace); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", filterWorkspace); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", filterWorkspace); err != nil {
+		return err
+	}
+	return registerRowSecurity(db)
+}
+
+func tenantStatement(db *gorm.DB) (uuid.UUID, bool) {
+	if db.Statement.Schema == nil || !tenantTables[db.Statement.Table] {
+		return uuid.Nil, false
+	}
+	if db.Statement.Schema.LookUpField("workspace_id") == nil {
+		return uuid.Nil, false
+	}
+	return WorkspaceFromContext(db.Statement.Context)
+}
+
+func filterWorkspace(db *gorm.DB) {
+	workspaceID, ok := tenantStatement(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "workspace_id"}, Value: workspaceID},
+	}})
+}
+
+// stampWorkspace sets workspace_id on created rows. The context's workspace
+// always wins so a request cannot write into another tenant.
+func stampWorkspace(db *gorm.DB) {
+	workspaceID, ok := tenantStatement(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("workspace_id")
+	value := db.Statement.ReflectValue
+	ctx := db.Statement.Context
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(value.Index(i)), &workspaceID); err != nil {
+				db.AddError(err)
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(ctx, value, &workspaceID); err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+// workspaceScope filters column by the workspace in db's context, for queries
+// the tenant callbacks cannot see.
+func workspaceScope(db *gorm.DB, column string) *gorm.DB {
+	if workspaceID, ok := WorkspaceFromContext(db.Statement.Context); ok {
+		return db.Where(column+" = ?", workspaceID)
+	}
+	return db
+}
+
+// workspaceMembersOnly limits a query over users to members of the workspace
+// in db's context. userColumn is the users ID column of the query.
+func workspaceMembersOnly(db *gorm.DB, userColumn string) *gorm.DB {
+	if workspaceID, ok := WorkspaceFromContext(db.Statement.Context); ok {
+		return db.Where(userColumn+" IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.WorkspaceMember{}).Select("user_id").Where("workspace_id = ?", workspaceID))
+	}
+	return db
+}
+
+// UserWorkspace is a workspace together with the user's role in it.
+type UserWorkspace struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Slug     string    `json:"slug"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type WorkspaceMemberView struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type WorkspaceService interface {
+	// CreateWorkspace creates a workspace owned by ownerID.
+	CreateWorkspace(db *gorm.DB, name string, ownerID uuid.UUID) (*models.Workspace, error)
+	ListWorkspaces(db *gorm.DB, userID uuid.UUID) ([]UserWorkspace, error)
+	// Membership returns ErrNotWorkspaceMember when the user is not in the
+	// workspace, including when the workspace does not exist.
+	Membership(db *gorm.DB, workspaceID, userID uuid.UUID) (*models.WorkspaceMember, error)
+	// DefaultMembership returns the user's oldest membership.
+	DefaultMembership(db *gorm.DB, userID uuid.UUID) (*models.WorkspaceMember, error)
+	ListMembers(db *gorm.DB, workspaceID uuid.UUID) ([]WorkspaceMemberView, error)
+	// AddMember adds the user or changes their role if already a member.
+	AddMember(db *gorm.DB, workspaceID, userID uuid.UUID, role string) (*models.WorkspaceMember, error)
+	RemoveMember(db *gorm.DB, workspaceID, userID uuid.UUID) error
+}
+
+type WorkspaceServiceImpl struct{}
+
+func NewWorkspaceService() *WorkspaceServiceImpl {
+	return &WorkspaceServiceImpl{}
+}
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func (s *WorkspaceServiceImpl) CreateWorkspace(db *gorm.DB, name string, ownerID uuid.UUID) (*models.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 255 {
+		return nil, fmt.Errorf("%w: name must be between 1 and 255 characters", ErrInvalidWorkspace)
+	}
+
+	id := uuid.Must(uuid.NewV4())
+	slug := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	// The ID suffix keeps slugs unique without a lookup.
+	slug = strings.TrimPrefix(slug+"-"+id.String()[:8], "-")
+
+	workspace := models.Workspace{ID: id, Name: name, Slug: slug, CreatedBy: &ownerID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.WorkspaceMember{WorkspaceID: id, UserID: ownerID, Role: models.WorkspaceRoleOwner}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return &workspace, nil
+}
+
+func (s *WorkspaceServiceImpl) ListWorkspaces(db *gorm.DB, userID uuid.UUID) ([]UserWorkspace, error) {
+	workspaces := []UserWorkspace{}
+	err := db.Table("workspaces w").
+		Joins("JOIN workspace_members wm ON wm.workspace_id = w.id").
+		Select("w.id, w.name, w.slug, wm.role, wm.created_at AS joined_at").
+		Where("wm.user_id = ?", userID).
+		Order("wm.created_at, w.name").
+		Scan(&workspaces).Error
+	return workspaces, err
+}
+
+func (s *WorkspaceServiceImpl) Membership(db *gorm.DB, workspaceID, userID uuid.UUID) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotWorkspaceMember
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *WorkspaceServiceImpl) DefaultMembership(db *gorm.DB, userID uuid.UUID) (*models.WorkspaceMember, error) {
+	return defaultMembership(db, userID)
+}
+
+func defaultMembership(db *gorm.DB, userID uuid.UUID) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := db.Where("user_id = ?", userID).Order("created_at, workspace_id").First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotWorkspaceMember
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *WorkspaceServiceImpl) ListMembers(db *gorm.DB, workspaceID uuid.UUID) ([]WorkspaceMemberView, error) {
+	members := []WorkspaceMemberView{}
+	err := db.Table("workspace_members wm").
+		Joins("JOIN users u ON u.id = wm.user_id").
+		Select("wm.user_id, u.username, u.email, wm.role, wm.created_at AS joined_at").
+		Where("wm.workspace_id = ? AND u.deleted_at IS NULL", workspaceID).
+		Order("u.username").
+		Scan(&members).Error
+	return members, err
+}
+
+func (s *WorkspaceServiceImpl) AddMember(db *gorm.DB, workspaceID, userID uuid.UUID, role string) (*models.WorkspaceMember, error) {
+	if role == "" {
+		role = models.WorkspaceRoleMember
+	}
+	if !models.IsValidWorkspaceRole(role) {
+		return nil, fmt.Errorf("%w: role must be owner, admin or member", ErrInvalidWorkspace)
+	}
+
+	var member models.WorkspaceMember
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var workspaces int64
+		if err := tx.Model(&models.Workspace{}).Where("id = ?", workspaceID).Count(&workspaces).Error; err != nil {
+			return err
+		}
+		if workspaces == 0 {
+			return ErrWorkspaceNotFound
+		}
+		var users int64
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Count(&users).Error; err != nil {
+			return err
+		}
+		if users == 0 {
+			return fmt.Errorf("%w: user not found", ErrInvalidWorkspace)
+		}
+
+		existing, err := s.Membership(tx, workspaceID, userID)
+		switch {
+		case errors.Is(err, ErrNotWorkspaceMember):
+			member = models.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}
+			return tx.Create(&member).Error
+		case err != nil:
+			return err
+		}
+
+		if existing.Role == models.WorkspaceRoleOwner && role != models.WorkspaceRoleOwner {
+			if err := ensureAnotherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+		existing.Role = role
+		member = *existing
+		return tx.Model(existing).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Update("role", role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *WorkspaceServiceImpl) RemoveMember(db *gorm.DB, workspaceID, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		member, err := s.Membership(tx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == models.WorkspaceRoleOwner {
+			if err := ensureAnotherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.WorkspaceMember{}).Error
+	})
+}
+
+func ensureAnotherOwner(db *gorm.DB, workspaceID, userID uuid.UUID) error {
+	var owners int64
+	err := db.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ? AND user_id <> ?", workspaceID, models.WorkspaceRoleOwner, userID).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastWorkspaceOwner
+	}
+	return nil
+}
+
+// WorkspaceResolver checks workspace selectors for AuthzMiddleware.
+type WorkspaceResolver struct {
+	db         *gorm.DB
+	workspaces WorkspaceService
+}
+
+func NewWorkspaceResolver(db *gorm.DB, workspaces WorkspaceService) *WorkspaceResolver {
+	return &WorkspaceResolver{db: db, workspaces: workspaces}
+}
+
+// ResolveWorkspace returns the user's membership in workspaceID, or in their
+// default workspace when workspaceID is uuid.Nil.
+func (r *WorkspaceResolver) ResolveWorkspace(ctx context.Context, userID, workspaceID uuid.UUID) (*models.WorkspaceMember, error) {
+	db := r.db.WithContext(ctx)
+	if workspaceID == uuid.Nil {
+		return r.workspaces.DefaultMembership(db, userID)
+	}
+	return r.workspaces.Membership(db, workspaceID, userID)
+}
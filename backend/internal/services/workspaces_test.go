@@ -0,0 +1,140 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupWorkspaceTestDB(t *testing.T) *testFixture {
+	f := newTestFixture(t, "tasks", "workspaces", "workspace_members")
+	require.NoError(t, services.RegisterTenantScope(f.db))
+	return f
+}
+
+func TestTenantScope_IsolatesWorkspaces(t *testing.T) {
+	f := setupWorkspaceTestDB(t)
+	workspaces := services.NewWorkspaceService()
+	tasks := services.NewTaskService()
+
+	acme, err := workspaces.CreateWorkspace(f.db, "Acme Inc.", f.alice)
+	require.NoError(t, err)
+	assert.Regexp(t, `^acme-inc-[0-9a-f]{8}$`, acme.Slug)
+	globex, err := workspaces.CreateWorkspace(f.db, "Globex", f.carol)
+	require.NoError(t, err)
+
+	acmeDB := f.db.WithContext(services.WithWorkspace(context.Background(), acme.ID))
+	globexDB := f.db.WithContext(services.WithWorkspace(context.Background(), globex.ID))
+
+	// A workspace_id in the payload cannot move a task into another tenant.
+	acmeTask := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: f.alice, Title: "Acme roadmap", Status: "pending", WorkspaceID: &globex.ID}
+	require.NoError(t, tasks.CreateTask(acmeDB, acmeTask))
+	globexTask := models.Task{ID: uuid.Must(uuid.NewV4()), UserID: f.carol, Title: "Globex plan", Status: "pending"}
+	require.NoError(t, tasks.CreateTask(globexDB, globexTask))
+
+	stored, err := tasks.GetTaskByID(f.db, acmeTask.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.WorkspaceID)
+	assert.Equal(t, acme.ID, *stored.WorkspaceID)
+
+	list, err := tasks.GetTasks(acmeDB)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Acme roadmap", list[0].Title)
+
+	_, total, err := tasks.GetTasksPaginated(globexDB, "", "", "1", "10")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	_, err = tasks.GetTaskByID(acmeDB, globexTask.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.NoError(t, tasks.UpdateTask(acmeDB, globexTask.ID, models.Task{Title: "Hijacked"}))
+	require.NoError(t, tasks.DeleteTask(acmeDB, globexTask.ID))
+	stored, err = tasks.GetTaskByID(globexDB, globexTask.ID)
+	require.NoError(t, err, "writes from another workspace do not reach the task")
+	assert.Equal(t, "Globex plan", stored.Title)
+
+	// Without a workspace, as in background jobs, nothing is filtered.
+	all, err := tasks.GetTasks(f.db)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	users, err := services.NewUserService().GetUsers(acmeDB)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, f.alice, users[0].ID)
+	profile, err := services.NewUserService().GetUserProfile(acmeDB, f.carol)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, profile.ID, "users outside the workspace are not visible")
+
+	workload, err := services.NewWorkloadService(services.WorkloadOptions{}).Workload(globexDB, "", *fixtureTime(10, 0))
+	require.NoError(t, err)
+	require.Len(t, workload, 1)
+	assert.Equal(t, "Sales", workload[0].Department)
+	require.Len(t, workload[0].Members, 1)
+	assert.Equal(t, int64(1), workload[0].Members[0].OpenTasks)
+}
+
+func TestWorkspaceService_Membership(t *testing.T) {
+	f := setupWorkspaceTestDB(t)
+	workspaces := services.NewWorkspaceService()
+
+	_, err := workspaces.CreateWorkspace(f.db, "   ", f.alice)
+	assert.ErrorIs(t, err, services.ErrInvalidWorkspace)
+
+	first, err := workspaces.CreateWorkspace(f.db, "First", f.alice)
+	require.NoError(t, err)
+	second, err := workspaces.CreateWorkspace(f.db, "Second", f.bob)
+	require.NoError(t, err)
+	require.NoError(t, f.db.Model(&models.WorkspaceMember{}).Where("workspace_id = ?", first.ID).
+		Update("created_at", *fixtureTime(1, 0)).Error)
+
+	_, err = workspaces.AddMember(f.db, second.ID, f.alice, "superuser")
+	assert.ErrorIs(t, err, services.ErrInvalidWorkspace)
+	_, err = workspaces.AddMember(f.db, uuid.Must(uuid.NewV4()), f.alice, "")
+	assert.ErrorIs(t, err, services.ErrWorkspaceNotFound)
+
+	member, err := workspaces.AddMember(f.db, second.ID, f.alice, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.WorkspaceRoleMember, member.Role)
+	member, err = workspaces.AddMember(f.db, second.ID, f.alice, models.WorkspaceRoleAdmin)
+	require.NoError(t, err)
+	assert.True(t, member.CanManage())
+
+	mine, err := workspaces.ListWorkspaces(f.db, f.alice)
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+	assert.Equal(t, "First", mine[0].Name)
+	assert.Equal(t, models.WorkspaceRoleOwner, mine[0].Role)
+	assert.Equal(t, models.WorkspaceRoleAdmin, mine[1].Role)
+
+	members, err := workspaces.ListMembers(f.db, second.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	resolver := services.NewWorkspaceResolver(f.db, workspaces)
+	resolved, err := resolver.ResolveWorkspace(context.Background(), f.alice, uuid.Nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, resolved.WorkspaceID, "the oldest membership is the default")
+	_, err = resolver.ResolveWorkspace(context.Background(), f.carol, second.ID)
+	assert.ErrorIs(t, err, services.ErrNotWorkspaceMember)
+	_, err = resolver.ResolveWorkspace(context.Background(), f.carol, uuid.Nil)
+	assert.ErrorIs(t, err, services.ErrNotWorkspaceMember)
+
+	// The last owner can neither leave nor be demoted.
+	assert.ErrorIs(t, workspaces.RemoveMember(f.db, second.ID, f.bob), services.ErrLastWorkspaceOwner)
+	_, err = workspaces.AddMember(f.db, second.ID, f.bob, models.WorkspaceRoleMember)
+	assert.ErrorIs(t, err, services.ErrLastWorkspaceOwner)
+
+	_, err = workspaces.AddMember(f.db, second.ID, f.alice, models.WorkspaceRoleOwner)
+	require.NoError(t, err)
+	require.NoError(t, workspaces.RemoveMember(f.db, second.ID, f.bob))
+	assert.ErrorIs(t, workspaces.RemoveMember(f.db, second.ID, f.bob), services.ErrNotWorkspaceMember)
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	// The runtime image ships without zoneinfo, and digests are scheduled in
+	// each user's timezone.
+	_ "time/tzdata"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DigestFrequencyOff    = "off"
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+)
+
+// DigestSchedule is when one user's digest goes out: at Hour local time in
+// Timezone, every day or on Weekday. PeriodEnd is where the last digest
+// stopped.
+type DigestSchedule struct {
+	UserID    string
+	Frequency string
+	Timezone  string
+	Hour      int
+	Weekday   time.Weekday
+	PeriodEnd time.Time
+}
+
+// LastSlot returns the most recent send time at or before now.
+func (s DigestSchedule) LastSlot(now time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, loc)
+	if slot.After(local) {
+		slot = time.Date(local.Year(), local.Month(), local.Day()-1, s.Hour, 0, 0, 0, loc)
+	}
+
+	switch s.Frequency {
+	case DigestFrequencyDaily:
+	case DigestFrequencyWeekly:
+		back := (int(slot.Weekday()) - int(s.Weekday) + 7) % 7
+		slot = time.Date(slot.Year(), slot.Month(), slot.Day()-back, s.Hour, 0, 0, 0, loc)
+	default:
+		return time.Time{}, fmt.Errorf("unknown digest frequency %q", s.Frequency)
+	}
+	return slot, nil
+}
+
+// DigestScheduleSource lists the schedules of users with digests turned on.
+type DigestScheduleSource func(ctx context.Context) ([]DigestSchedule, error)
+
+// DigestScheduler enqueues a digest job for each user whose send time has
+// passed since their last digest. Like Scheduler, each user's slot is claimed
+// with a Redis lock so only one instance enqueues it.
+type DigestScheduler struct {
+	client        *redis.Client
+	queue         *JobQueue
+	source        DigestScheduleSource
+	checkInterval time.Duration
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+func NewDigestScheduler(client *redis.Client, source DigestScheduleSource, checkInterval time.Duration) *DigestScheduler {
+	if checkInterval <= 0 {
+		checkInterval = 5 * time.Minute
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &DigestScheduler{
+		client:        client,
+		queue:         NewJobQueue(client),
+		source:        source,
+		checkInterval: checkInterval,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+func (s *DigestScheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		s.runDue(time.Now())
+
+		ticker := time.NewTicker(s.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.runDue(now)
+			}
+		}
+	}()
+}
+
+func (s *DigestScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *DigestScheduler) runDue(now time.Time) int {
+	schedules, err := s.source(s.ctx)
+	if err != nil {
+		log.Printf("Digest scheduler failed to load schedules: %v", err)
+		return 0
+	}
+
+	enqueued := 0
+	for _, schedule := range schedules {
+		slot, err := schedule.LastSlot(now)
+		if err != nil {
+			log.Printf("Digest scheduler skipped user %s: %v", schedule.UserID, err)
+			continue
+		}
+		if !slot.After(schedule.PeriodEnd) {
+			continue
+		}
+
+		// The job moves PeriodEnd up to the slot, so the lock only has to
+		// outlive the queue backlog.
+		lockKey := fmt.Sprintf("digest:%s:%d", schedule.UserID, slot.Unix())
+		claimed, err := s.client.SetNX(s.ctx, lockKey, now.Unix(), 24*time.Hour).Result()
+		if err != nil {
+			log.Printf("Digest scheduler failed to claim %s: %v", lockKey, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		payload := map[string]interface{}{
+			"user_id":      schedule.UserID,
+			"frequency":    schedule.Frequency,
+			"timezone":     schedule.Timezone,
+			"period_start": schedule.PeriodEnd.UTC().Format(time.RFC3339),
+			"period_end":   slot.UTC().Format(time.RFC3339),
+		}
+		if err := s.queue.Enqueue("default", JobTypeDigest, payload); err != nil {
+			log.Printf("Digest scheduler failed to enqueue digest for %s: %v", schedule.UserID, err)
+			s.client.Del(s.ctx, lockKey)
+			continue
+		}
+		enqueued++
+	}
+
+	return enqueued
+}
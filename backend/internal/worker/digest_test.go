@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestDigestSchedule_LastSlot(t *testing.T) {
+	// 2026-03-04 is a Wednesday; 06:30 UTC is 07:30 in Berlin and 01:30 in New York.
+	now := time.Date(2026, 3, 4, 6, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		schedule DigestSchedule
+		expected string
+	}{
+		{"daily, already passed today", DigestSchedule{Frequency: DigestFrequencyDaily, Timezone: "Europe/Berlin", Hour: 7}, "2026-03-04T07:00:00+01:00"},
+		{"daily, later today", DigestSchedule{Frequency: DigestFrequencyDaily, Timezone: "Europe/Berlin", Hour: 8}, "2026-03-03T08:00:00+01:00"},
+		{"daily, local date behind UTC", DigestSchedule{Frequency: DigestFrequencyDaily, Timezone: "America/New_York", Hour: 8}, "2026-03-03T08:00:00-05:00"},
+		{"weekly on Monday", DigestSchedule{Frequency: DigestFrequencyWeekly, Timezone: "UTC", Hour: 8, Weekday: time.Monday}, "2026-03-02T08:00:00Z"},
+		{"weekly on today, not yet sent", DigestSchedule{Frequency: DigestFrequencyWeekly, Timezone: "UTC", Hour: 9, Weekday: time.Wednesday}, "2026-02-25T09:00:00Z"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			slot, err := tc.schedule.LastSlot(now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := slot.Format(time.RFC3339); got != tc.expected {
+				t.Errorf("Expected slot %s, got %s", tc.expected, got)
+			}
+		})
+	}
+
+	if _, err := (DigestSchedule{Frequency: DigestFrequencyDaily, Timezone: "Mars/Olympus_Mons"}).LastSlot(now); err == nil {
+		t.Error("Expected an error for an unknown timezone")
+	}
+}
+
+func TestDigestScheduler_EnqueuesDueDigestsOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	now := time.Date(2026, 3, 4, 8, 2, 0, 0, time.UTC)
+	schedules := []DigestSchedule{
+		{UserID: "due", Frequency: DigestFrequencyDaily, Timezone: "UTC", Hour: 8, PeriodEnd: now.Add(-24 * time.Hour)},
+		{UserID: "sent", Frequency: DigestFrequencyDaily, Timezone: "UTC", Hour: 8, PeriodEnd: time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)},
+		{UserID: "broken", Frequency: DigestFrequencyDaily, Timezone: "Nowhere/Else", Hour: 8},
+	}
+	source := func(ctx context.Context) ([]DigestSchedule, error) { return schedules, nil }
+
+	first := NewDigestScheduler(client, source, time.Minute)
+	second := NewDigestScheduler(client, source, time.Minute)
+
+	if n := first.runDue(now); n != 1 {
+		t.Errorf("Expected 1 digest to be enqueued, got %d", n)
+	}
+	if n := second.runDue(now.Add(time.Minute)); n != 0 {
+		t.Errorf("Expected the claimed slot to be skipped, got %d", n)
+	}
+
+	data, err := client.LIndex(context.Background(), "default", 0).Result()
+	if err != nil {
+		t.Fatalf("Failed to read queued job: %v", err)
+	}
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		t.Fatalf("Failed to unmarshal job: %v", err)
+	}
+	if job.Type != JobTypeDigest {
+		t.Errorf("Expected job type %s, got %s", JobTypeDigest, job.Type)
+	}
+	if job.Payload["user_id"] != "due" {
+		t.Errorf("Expected digest for user due, got %v", job.Payload["user_id"])
+	}
+	if job.Payload["period_start"] != "2026-03-03T08:02:00Z" || job.Payload["period_end"] != "2026-03-04T08:00:00Z" {
+		t.Errorf("Unexpected period %v - %v", job.Payload["period_start"], job.Payload["period_end"])
+	}
+}
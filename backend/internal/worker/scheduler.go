@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ScheduledJob is enqueued once per Interval across all instances.
+type ScheduledJob struct {
+	Name     string
+	Queue    string
+	Type     JobType
+	Interval time.Duration
+	Payload  map[string]interface{}
+}
+
+// Scheduler enqueues recurring jobs. Each run is claimed with a Redis lock keyed
+// by the interval slot, so scaling out the backend does not duplicate work.
+type Scheduler struct {
+	client        *redis.Client
+	queue         *JobQueue
+	jobs          []ScheduledJob
+	checkInterval time.Duration
+	mu            sync.RWMutex
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+func NewScheduler(client *redis.Client) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		client:        client,
+		queue:         NewJobQueue(client),
+		checkInterval: time.Minute,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+func (s *Scheduler) Add(job ScheduledJob) {
+	if job.Queue == "" {
+		job.Queue = "default"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+}
+
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		s.runDue(time.Now())
+
+		ticker := time.NewTicker(s.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.runDue(now)
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runDue(now time.Time) int {
+	s.mu.RLock()
+	jobs := make([]ScheduledJob, len(s.jobs))
+	copy(jobs, s.jobs)
+	s.mu.RUnlock()
+
+	enqueued := 0
+	for _, job := range jobs {
+		slot := now.Truncate(job.Interval)
+		lockKey := fmt.Sprintf("scheduler:%s:%d", job.Name, slot.Unix())
+
+		claimed, err := s.client.SetNX(s.ctx, lockKey, now.Unix(), job.Interval).Result()
+		if err != nil {
+			log.Printf("Scheduler failed to claim %s: %v", job.Name, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		payload := make(map[string]interface{}, len(job.Payload)+1)
+		for k, v := range job.Payload {
+			payload[k] = v
+		}
+		payload["scheduled_for"] = slot.Format(time.RFC3339)
+
+		if err := s.queue.Enqueue(job.Queue, job.Type, payload); err != nil {
+			log.Printf("Scheduler failed to enqueue %s: %v", job.Name, err)
+			continue
+		}
+		enqueued++
+	}
+
+	return enqueued
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestScheduler_RunDueOncePerSlot(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	first := NewScheduler(client)
+	second := NewScheduler(client)
+	for _, s := range []*Scheduler{first, second} {
+		s.Add(ScheduledJob{
+			Name:     "cleanup",
+			Type:     JobTypeCleanup,
+			Interval: time.Hour,
+			Payload:  map[string]interface{}{"target": "notifications"},
+		})
+	}
+
+	now := time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC)
+
+	if n := first.runDue(now); n != 1 {
+		t.Errorf("Expected first scheduler to enqueue 1 job, got %d", n)
+	}
+	if n := second.runDue(now.Add(10 * time.Minute)); n != 0 {
+		t.Errorf("Expected second scheduler to skip claimed slot, got %d", n)
+	}
+
+	length, err := client.LLen(context.Background(), "default").Result()
+	if err != nil {
+		t.Fatalf("Failed to read queue length: %v", err)
+	}
+	if length != 1 {
+		t.Fatalf("Expected 1 queued job, got %d", length)
+	}
+
+	data, _ := client.LIndex(context.Background(), "default", 0).Result()
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		t.Fatalf("Failed to unmarshal job: %v", err)
+	}
+	if job.Type != JobTypeCleanup {
+		t.Errorf("Expected job type %s, got %s", JobTypeCleanup, job.Type)
+	}
+	if job.Payload["target"] != "notifications" {
+		t.Errorf("Expected payload target to be preserved, got %v", job.Payload["target"])
+	}
+	if job.Payload["scheduled_for"] != "2026-01-01T10:00:00Z" {
+		t.Errorf("Expected scheduled_for to be the slot start, got %v", job.Payload["scheduled_for"])
+	}
+}
+
+func TestScheduler_RunDueNextSlot(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	s := NewScheduler(client)
+	s.Add(ScheduledJob{Name: "cleanup", Type: JobTypeCleanup, Interval: time.Hour})
+
+	now := time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC)
+	s.runDue(now)
+
+	if n := s.runDue(now.Add(time.Hour)); n != 1 {
+		t.Errorf("Expected job to run again in the next slot, got %d", n)
+	}
+}
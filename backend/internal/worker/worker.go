@@ -18,6 +18,10 @@ const (
 	JobTypeTaskReminder      JobType = "task_reminder"
 	JobTypeDataExport        JobType = "data_export"
 	JobTypeCleanup           JobType = "cleanup"
+	JobTypeTaskEvent         JobType = "task_event"
+	JobTypeWebhookDelivery   JobType = "webhook_delivery"
+	JobTypeDigest            JobType = "digest"
+	JobTypeSprintSnapshot    JobType = "sprint_snapshot"
 )
 
 type Job struct {
@@ -207,12 +211,22 @@ func (q *JobQueue) Enqueue(queue string, jobType JobType, payload map[string]int
 }
 
 func (q *JobQueue) EnqueueAt(queue string, jobType JobType, payload map[string]interface{}, processAt time.Time) error {
+	return q.enqueue(queue, jobType, payload, processAt, 3)
+}
+
+// EnqueueWithMaxTries enqueues a job that may be retried more (or fewer) times
+// than the default before it is moved to the dead queue.
+func (q *JobQueue) EnqueueWithMaxTries(queue string, jobType JobType, payload map[string]interface{}, maxTries int) error {
+	return q.enqueue(queue, jobType, payload, time.Now(), maxTries)
+}
+
+func (q *JobQueue) enqueue(queue string, jobType JobType, payload map[string]interface{}, processAt time.Time, maxTries int) error {
 	job := &Job{
 		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
 		Type:      jobType,
 		Payload:   payload,
 		Attempts:  0,
-		MaxTries:  3,
+		MaxTries:  maxTries,
 		CreatedAt: time.Now(),
 		ProcessAt: processAt,
 	}
@@ -11,10 +11,13 @@ import (
 	"task-manager/backend/internal/cache"
 	"task-manager/backend/internal/config"
 	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/mailer"
 	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/monitoring"
 	"task-manager/backend/internal/repositories"
 	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/worker"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -29,18 +32,47 @@ import (
 type Application struct {
 	Config       *config.Config
 	DB           *gorm.DB
+	SystemDB     *gorm.DB
 	Cache        cache.Cache
 	CacheManager *cache.UnifiedCacheManager
 	Redis        *redis.Client
 	Router       *gin.Engine
 	Server       *http.Server
+	JobQueue     *worker.JobQueue
+	Worker       *worker.Worker
+	Scheduler    *worker.Scheduler
+	Digests      *worker.DigestScheduler
 
 	// Services
-	TaskService     services.TaskService
-	AuthService     services.AuthService
-	UserService     services.UserService
-	RegisterService services.RegisterService
-	AuthzService    services.AuthorizationService
+	TaskService          services.TaskService
+	AuthService          services.AuthService
+	UserService          services.UserService
+	RegisterService      services.RegisterService
+	PasswordResetService services.PasswordResetService
+	VerificationService  services.EmailVerificationService
+	MFAService           services.MFAService
+	TokenDenylist        *services.TokenDenylist
+	SessionService       services.SessionService
+	AccessTokenService   services.PersonalAccessTokenService
+	AuthzService         services.AuthorizationService
+	WatcherService       services.WatcherService
+	NotificationService  services.NotificationService
+	DigestService        services.DigestService
+	ReportService        services.ReportService
+	WorkloadService      services.WorkloadService
+	SprintService        services.SprintService
+	ShareService         services.ShareService
+	WorkspaceService     services.WorkspaceService
+	TeamService          services.TeamService
+	EventStream          services.EventStream
+	CollaborationHub     *services.CollaborationHub
+	WebhookService       services.WebhookService
+	EventBus             *services.EventBus
+	CalendarService      services.CalendarService
+	AttachmentService    services.AttachmentService
+	InboundEmailService  services.InboundEmailService
+	InboundSMTP          *services.InboundSMTPServer
+	OutboxRelay          *services.OutboxRelay
 }
 
 func main() {
@@ -76,6 +108,9 @@ func initializeApplication(cfg *config.Config) (*Application, error) {
 	if err != nil {
 		return nil, fmt.Errorf("database connection failed: %w", err)
 	}
+	if err := services.RegisterTenantScope(db); err != nil {
+		return nil, fmt.Errorf("failed to register tenant scope: %w", err)
+	}
 	app.DB = db
 
 	sqlDB, err := db.DB()
@@ -89,6 +124,15 @@ func initializeApplication(cfg *config.Config) (*Application, error) {
 
 	log.Println("✅ Database connected and configured")
 
+	// Row-level security does not apply to superusers or BYPASSRLS roles, so
+	// connecting as one silently removes the isolation between workspaces.
+	var bypassesRowSecurity bool
+	if err := db.Raw("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypassesRowSecurity).Error; err != nil {
+		log.Printf("⚠️  Could not check the database role: %v", err)
+	} else if bypassesRowSecurity {
+		log.Printf("⚠️  DB_USER %q bypasses row-level security; workspaces are not isolated in the database. Connect as a role without SUPERUSER or BYPASSRLS (see SET_UP.md)", dbCfg.User)
+	}
+
 	// Run database migrations automatically
 	migrationConfig := &repositories.MigrationConfig{
 		MigrationsPath: "file://migrations",
@@ -101,6 +145,21 @@ func initializeApplication(cfg *config.Config) (*Application, error) {
 		return nil, fmt.Errorf("database migration failed: %w", err)
 	}
 
+	// Row-level security hides every row from statements without a
+	// workspace. Background jobs and routes that run before a workspace is
+	// selected use a second pool that bypasses it; the application's own
+	// scoping still applies to both. The role is created by the migrations.
+	systemCfg := repositories.NewDatabaseConfig()
+	systemCfg.Role = repositories.SystemRole
+	systemDB, err := systemCfg.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("system database connection failed: %w", err)
+	}
+	if err := services.RegisterTenantScope(systemDB); err != nil {
+		return nil, fmt.Errorf("failed to register tenant scope: %w", err)
+	}
+	app.SystemDB = systemDB
+
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:         cfg.GetRedisAddr(),
 		Password:     cfg.Redis.Password,
@@ -180,10 +239,42 @@ func initializeApplication(cfg *config.Config) (*Application, error) {
 	}
 
 	// Initialize Services
-	app.AuthzService = services.NewAuthorizationService(db)
-	app.AuthService = services.NewAuthService()
+	app.AuthzService = services.NewAuthorizationService(systemDB)
+	app.TokenDenylist = services.NewTokenDenylist(app.Redis)
+	app.AuthService = services.NewAuthService(services.AuthOptions{
+		RequireEmailVerification: cfg.Auth.RequireEmailVerification,
+		Denylist:                 app.TokenDenylist,
+	})
 	app.UserService = services.NewUserService()
 	app.RegisterService = services.NewRegisterService()
+	app.PasswordResetService = services.NewPasswordResetService(cfg.Auth.PasswordResetTTL, cfg.Auth.PasswordResetResendDelay, cfg.Auth.BCryptCost)
+	app.VerificationService = services.NewEmailVerificationService(cfg.Auth.JWTSecret, cfg.Auth.EmailVerificationTTL, cfg.Auth.VerificationResendDelay)
+	app.SessionService = services.NewSessionService(app.TokenDenylist)
+	app.AccessTokenService = services.NewPersonalAccessTokenService()
+	app.MFAService = services.NewMFAService(services.MFAOptions{
+		Secret:        cfg.Auth.JWTSecret,
+		Issuer:        cfg.Auth.MFAIssuer,
+		TokenTTL:      cfg.Auth.MFATokenTTL,
+		RequiredRoles: cfg.Auth.MFARequiredRoles,
+		BCryptCost:    cfg.Auth.BCryptCost,
+		Attempts:      services.NewMFAAttemptLimiter(app.Redis, cfg.Auth.MFAMaxFailedAttempts, cfg.Auth.MFALockout),
+	})
+	app.CalendarService = services.NewCalendarService()
+	app.AttachmentService = services.NewAttachmentService()
+
+	// Report service, cached when the multi-level cache is available
+	app.ReportService = services.NewReportService()
+	if multiCache, ok := app.Cache.(*cache.MultiLevelCache); ok {
+		app.ReportService = services.NewCachedReportService(app.ReportService, multiCache, cfg.Reports.CacheTTL)
+	}
+	app.SprintService = services.NewSprintService()
+	app.ShareService = services.NewShareService()
+	app.WorkspaceService = services.NewWorkspaceService()
+	app.TeamService = services.NewTeamService()
+	app.WorkloadService = services.NewWorkloadService(services.WorkloadOptions{
+		MaxOpenTasks:      cfg.Reports.WorkloadMaxOpenTasks,
+		MaxEstimatedHours: float64(cfg.Reports.WorkloadMaxHours),
+	})
 
 	// Task service with optional caching
 	taskServiceImpl := services.NewTaskService()
@@ -195,11 +286,175 @@ func initializeApplication(cfg *config.Config) (*Application, error) {
 		log.Println("✅ Task service initialized")
 	}
 
+	// Background jobs require Redis; without it task events are delivered inline
+	if app.Redis != nil {
+		app.JobQueue = worker.NewJobQueue(app.Redis)
+	}
+	app.NotificationService = services.NewNotificationService(
+		services.NewInAppNotifier(),
+		services.NewEmailNotifier(app.JobQueue),
+	)
+	app.DigestService = services.NewDigestService()
+	app.WatcherService = services.NewWatcherService(app.JobQueue, app.NotificationService)
+	app.WebhookService = services.NewWebhookService(app.JobQueue, app.AuthzService, services.WebhookOptions{
+		MaxAttempts:         cfg.Webhook.MaxAttempts,
+		FailureThreshold:    cfg.Webhook.FailureThreshold,
+		Timeout:             cfg.Webhook.Timeout,
+		AllowPrivateTargets: cfg.Webhook.AllowPrivateTargets,
+	})
+
+	// Live task events fan out across instances over Redis pub/sub when available
+	app.EventStream = services.NewTaskEventBroker(app.Redis, cfg.Stream.ReplayBufferSize)
+
+	// Side effects of task, user and role changes hang off the event bus.
+	// Synchronous subscribers must succeed for an event to count as published.
+	app.EventBus = services.NewEventBus(cfg.Events.Workers, cfg.Events.BufferSize)
+	if cachedTasks, ok := app.TaskService.(*services.CachedTaskService); ok {
+		cachedTasks.SubscribeInvalidation(app.EventBus)
+	}
+	app.EventBus.SubscribeTaskEvents(app.WatcherService, false)
+	app.EventBus.SubscribeTaskEvents(app.WebhookService, false)
+	app.EventBus.SubscribeTaskEvents(app.EventStream, true)
+	app.EventBus.SubscribeAsync(services.AllEvents, services.NewAuditLogSubscriber())
+
+	// Task, user and role changes are recorded in the outbox with the write that
+	// causes them; the relay publishes them to the bus once committed
+	app.OutboxRelay = services.NewOutboxRelay(systemDB, services.OutboxOptions{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+		MaxAttempts:  cfg.Outbox.MaxAttempts,
+	})
+	for _, aggregate := range []string{models.OutboxAggregateTask, models.OutboxAggregateUser, models.OutboxAggregateRole} {
+		app.OutboxRelay.Handle(aggregate, services.NewEventBusOutboxHandler(app.EventBus))
+	}
+	app.OutboxRelay.Start()
+	log.Println("✅ Outbox relay started")
+
+	// Email-to-task is only enabled once a mail domain is configured
+	if cfg.InboundEmail.Domain != "" {
+		app.InboundEmailService = services.NewInboundEmailService(app.TaskService, app.AttachmentService, services.NewTaskEventOutbox(), services.InboundEmailOptions{
+			Domain:            cfg.InboundEmail.Domain,
+			MaxAttachmentSize: cfg.InboundEmail.MaxMessageSize,
+		})
+		if cfg.InboundEmail.SMTPAddr != "" {
+			app.InboundSMTP = services.NewInboundSMTPServer(systemDB, app.InboundEmailService, services.InboundSMTPOptions{
+				Hostname:       cfg.InboundEmail.Domain,
+				MaxMessageSize: cfg.InboundEmail.MaxMessageSize,
+			})
+		}
+		log.Printf("✅ Inbound email enabled for @%s", cfg.InboundEmail.Domain)
+	}
+
+	app.CollaborationHub = services.NewCollaborationHub(cfg.Collab.LockTTL)
+	app.CollaborationHub.Start(cfg.Collab.HeartbeatInterval)
+
+	if app.Redis != nil {
+		app.Worker = worker.NewWorker(worker.WorkerConfig{
+			RedisClient:  app.Redis,
+			Concurrency:  cfg.Worker.Concurrency,
+			PollInterval: cfg.Worker.PollInterval,
+			Queues:       append(cfg.Worker.Queues, "retry_queue"),
+		})
+		app.Worker.RegisterHandler(worker.JobTypeTaskEvent, services.NewTaskEventJobHandler(systemDB, app.WatcherService))
+		app.Worker.RegisterHandler(worker.JobTypeWebhookDelivery, services.NewWebhookDeliveryJobHandler(systemDB, app.WebhookService))
+		if emailHandler, err := newEmailJobHandler(systemDB, cfg); err != nil {
+			log.Printf("⚠️  Email delivery disabled: %v", err)
+		} else {
+			app.Worker.RegisterHandler(worker.JobTypeEmailNotification, emailHandler)
+			log.Printf("✅ Email delivery via %s", cfg.Email.Transport)
+		}
+		app.Worker.RegisterHandler(worker.JobTypeDigest, services.NewDigestJobHandler(systemDB, app.JobQueue, app.DigestService))
+		app.Worker.RegisterHandler(worker.JobTypeSprintSnapshot, services.NewSprintSnapshotJobHandler(systemDB, app.SprintService))
+		app.Worker.RegisterHandler(worker.JobTypeCleanup, services.NewCleanupJobHandler(systemDB, map[string]services.CleanupFunc{
+			"notifications": func(db *gorm.DB) (int64, error) {
+				return app.NotificationService.DeleteOlderThan(db, time.Now().Add(-cfg.Notification.Retention))
+			},
+			"outbox": func(db *gorm.DB) (int64, error) {
+				return services.DeletePublishedOutbox(db, time.Now().Add(-cfg.Outbox.Retention))
+			},
+			"tokens": func(db *gorm.DB) (int64, error) {
+				return services.DeleteExpiredTokens(db, time.Now())
+			},
+		}))
+		app.Worker.Start(cfg.Worker.Concurrency)
+		log.Println("✅ Background worker started")
+
+		app.Scheduler = worker.NewScheduler(app.Redis)
+		app.Scheduler.Add(worker.ScheduledJob{
+			Name:     "notification_cleanup",
+			Type:     worker.JobTypeCleanup,
+			Interval: cfg.Notification.CleanupInterval,
+			Payload:  map[string]interface{}{"target": "notifications"},
+		})
+		app.Scheduler.Add(worker.ScheduledJob{
+			Name:     "outbox_cleanup",
+			Type:     worker.JobTypeCleanup,
+			Interval: cfg.Notification.CleanupInterval,
+			Payload:  map[string]interface{}{"target": "outbox"},
+		})
+		app.Scheduler.Add(worker.ScheduledJob{
+			Name:     "token_cleanup",
+			Type:     worker.JobTypeCleanup,
+			Interval: cfg.Notification.CleanupInterval,
+			Payload:  map[string]interface{}{"target": "tokens"},
+		})
+		app.Scheduler.Add(worker.ScheduledJob{
+			Name:     "sprint_snapshot",
+			Type:     worker.JobTypeSprintSnapshot,
+			Interval: cfg.Reports.SprintSnapshotInterval,
+		})
+		app.Scheduler.Start()
+		log.Println("✅ Job scheduler started")
+
+		app.Digests = worker.NewDigestScheduler(app.Redis, func(ctx context.Context) ([]worker.DigestSchedule, error) {
+			return app.DigestService.ListSchedules(systemDB.WithContext(ctx))
+		}, cfg.Notification.DigestCheckInterval)
+		app.Digests.Start()
+	}
+
 	log.Println("✅ All services initialized")
 
 	return app, nil
 }
 
+// newEmailJobHandler builds the email job handler for the configured
+// transport.
+func newEmailJobHandler(db *gorm.DB, cfg *config.Config) (worker.JobHandler, error) {
+	var sender mailer.Sender
+	switch cfg.Email.Transport {
+	case "smtp":
+		if cfg.Email.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is not set")
+		}
+		sender = mailer.NewSMTPSender(mailer.SMTPOptions{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.SMTPUsername,
+			Password: cfg.Email.SMTPPassword,
+			TLSMode:  cfg.Email.SMTPTLSMode,
+		})
+	case "file":
+		if cfg.IsProduction() {
+			log.Printf("⚠️  Emails are written to %s instead of being sent", cfg.Email.FileDir)
+		}
+		sender = mailer.NewFileSender(cfg.Email.FileDir)
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_TRANSPORT %q", cfg.Email.Transport)
+	}
+
+	renderer, err := mailer.NewRenderer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email templates: %w", err)
+	}
+
+	return services.NewEmailJobHandler(db, sender, renderer, services.EmailOptions{
+		From:              cfg.Email.From,
+		AppURL:            cfg.Email.AppURL,
+		APIURL:            cfg.Server.PublicURL,
+		UnsubscribeSecret: cfg.Email.UnsubscribeSecret,
+	}), nil
+}
+
 func (app *Application) setupRoutes() {
 	r := gin.New()
 
@@ -218,7 +473,7 @@ func (app *Application) setupRoutes() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://host.docker.internal"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.WorkspaceHeader, middleware.APIKeyHeader, handlers.SharePasswordHeader},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -231,24 +486,170 @@ func (app *Application) setupRoutes() {
 
 	v1 := r.Group("/api/v1")
 
+	// Collaboration WebSocket. Browsers cannot send an Authorization header on
+	// the handshake, so the token may also arrive as ?access_token=.
+	collabHandler := handlers.NewCollaborationHandler(app.DB, app.CollaborationHub, app.AuthzService, app.Config.Collab.HeartbeatInterval)
+	workspaceResolver := services.NewWorkspaceResolver(app.SystemDB, app.WorkspaceService)
+	apiTokens := services.NewAPITokenAuthenticator(app.SystemDB, app.AccessTokenService)
+	// The routes personal access tokens may call, with the scope each needs.
+	// Routes missing here refuse tokens.
+	apiTokenScopes := map[string]string{
+		"POST /api/v1/tasks":                               "task:create",
+		"GET /api/v1/tasks":                                "task:read",
+		"GET /api/v1/tasks/:id":                            "task:read",
+		"PUT /api/v1/tasks/:id":                            "task:update",
+		"DELETE /api/v1/tasks/:id":                         "task:delete",
+		"GET /api/v1/tasks/:id/watchers":                   "task:read",
+		"GET /api/v1/tasks/:id/attachments":                "task:read",
+		"GET /api/v1/tasks/:id/attachments/:attachment_id": "task:read",
+		"GET /api/v1/users/:user_id/tasks":                 "task:read",
+		"GET /api/v1/users/profile":                        "profile:read",
+		"PUT /api/v1/users/profile":                        "profile:update",
+		"GET /api/v1/reports/throughput":                   "report:read",
+		"GET /api/v1/reports/durations":                    "report:read",
+		"GET /api/v1/reports/status":                       "report:read",
+		"GET /api/v1/reports/overdue":                      "report:read",
+		"GET /api/v1/workload":                             "task:read",
+		"GET /api/v1/sprints":                              "task:read",
+		"GET /api/v1/sprints/:id":                          "task:read",
+		"GET /api/v1/sprints/:id/burndown":                 "task:read",
+		"POST /api/v1/sprints":                             "sprint:manage",
+		"POST /api/v1/sprints/:id/start":                   "sprint:manage",
+		"POST /api/v1/sprints/:id/close":                   "sprint:manage",
+		"POST /api/v1/sprints/:id/tasks":                   "task:update",
+		"DELETE /api/v1/sprints/:id/tasks/:task_id":        "task:update",
+		"GET /api/v1/teams":                                "task:read",
+		"GET /api/v1/teams/:id":                            "task:read",
+		"GET /api/v1/teams/:id/members":                    "task:read",
+		"GET /api/v1/teams/:id/queue":                      "task:read",
+		"POST /api/v1/teams/:id/tasks":                     "task:update",
+		"DELETE /api/v1/teams/:id/tasks/:task_id":          "task:update",
+		"POST /api/v1/teams/:id/queue/:task_id/claim":      "task:update",
+	}
+	v1.GET("/ws", middleware.AuthzMiddleware(middleware.AuthzConfig{AllowQueryToken: true, Workspaces: workspaceResolver, Revocations: app.TokenDenylist}), collabHandler.Connect)
+
+	// iCalendar feed. Calendar clients cannot authenticate, so the secret token
+	// in the URL is the credential.
+	calendarHandler := handlers.NewCalendarHandler(app.SystemDB, app.CalendarService, app.Config.Server.PublicURL)
+	v1.GET("/calendar/:token", calendarHandler.Feed)
+
+	// Public read-only task links. The token is the credential.
+	shareHandler := handlers.NewShareHandler(app.SystemDB, app.ShareService, app.AuthzService, app.Config.Server.PublicURL)
+	v1.GET("/shared/:token", shareHandler.GetSharedTask)
+
+	// Inbound email from the mail gateway. The secret recipient address
+	// identifies the user, so there is no user authentication here.
+	var inboundHandler *handlers.InboundEmailHandler
+	if app.InboundEmailService != nil {
+		inboundHandler = handlers.NewInboundEmailHandler(app.SystemDB, app.InboundEmailService, app.Config.InboundEmail.Secret, app.Config.InboundEmail.MaxMessageSize)
+		v1.POST("/inbound/email", inboundHandler.Receive)
+	}
+
+	// Unsubscribe links in emails carry a signed token instead of a session
+	emailHandler := handlers.NewEmailHandler(app.SystemDB, app.Config.Email.UnsubscribeSecret)
+	v1.GET("/email/unsubscribe", emailHandler.Unsubscribe)
+	v1.POST("/email/unsubscribe", emailHandler.Unsubscribe)
+
 	// Public authentication routes (no auth required)
 	authRoutes := v1.Group("/auth")
 	{
-		authHandler := handlers.NewAuthHandler(app.DB, app.AuthService)
-		refreshHandler := handlers.NewRefreshHandler(app.DB, app.AuthService)
-		registrationHandler := handlers.NewRegisterHandler(app.DB, app.RegisterService)
+		authHandler := handlers.NewAuthHandler(app.SystemDB, app.AuthService, app.MFAService)
+		refreshHandler := handlers.NewRefreshHandler(app.SystemDB, app.AuthService)
+		logoutHandler := handlers.NewLogoutHandler(app.SystemDB, app.AuthService, app.TokenDenylist)
+		verificationHandler := handlers.NewEmailVerificationHandler(app.SystemDB, app.VerificationService, app.JobQueue, app.Config.Email.AppURL)
+		registrationHandler := handlers.NewRegisterHandler(app.SystemDB, app.RegisterService, verificationHandler)
 
 		authRoutes.POST("/register", registrationHandler.Registration)
 		authRoutes.POST("/login", authHandler.Token)
 		authRoutes.POST("/refresh", refreshHandler.Refresh)
+
+		// Logout revokes the access token it is called with
+		requireToken := middleware.AuthzMiddleware(middleware.AuthzConfig{Revocations: app.TokenDenylist})
+		authRoutes.POST("/logout", requireToken, logoutHandler.Logout)
+		authRoutes.POST("/logout-all", requireToken, logoutHandler.LogoutAll)
+
+		// Second login step for users with MFA
+		authRoutes.POST("/mfa/verify", authHandler.VerifyMFA)
+		authRoutes.POST("/mfa/enroll", authHandler.EnrollMFA)
+
+		passwordResetHandler := handlers.NewPasswordResetHandler(app.SystemDB, app.PasswordResetService, registrationHandler, app.JobQueue, app.TokenDenylist, app.Config.Email.AppURL)
+		authRoutes.POST("/password/forgot", passwordResetHandler.ForgotPassword)
+		authRoutes.POST("/password/reset", passwordResetHandler.ResetPassword)
+
+		authRoutes.POST("/email/verify", verificationHandler.VerifyEmail)
+		authRoutes.POST("/email/resend", verificationHandler.ResendVerification)
+	}
+
+	// MFA settings belong to the user rather than a workspace
+	mfaHandler := handlers.NewMFAHandler(app.SystemDB, app.MFAService)
+	mfaRoutes := v1.Group("/mfa")
+	mfaRoutes.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Revocations: app.TokenDenylist}))
+	{
+		mfaRoutes.GET("", mfaHandler.GetStatus)
+		mfaRoutes.DELETE("", mfaHandler.Disable)
+		mfaRoutes.POST("/enroll", mfaHandler.BeginEnrollment)
+		mfaRoutes.POST("/enroll/confirm", mfaHandler.ConfirmEnrollment)
+		mfaRoutes.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+	}
+
+	// Sessions, like MFA, belong to the user
+	sessionHandler := handlers.NewSessionHandler(app.SystemDB, app.SessionService)
+	sessionRoutes := v1.Group("/sessions")
+	sessionRoutes.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Revocations: app.TokenDenylist}))
+	{
+		sessionRoutes.GET("", sessionHandler.ListSessions)
+		sessionRoutes.DELETE("/:id", sessionHandler.TerminateSession)
+	}
+
+	// Personal access tokens are managed with a login session only, so a
+	// token cannot be used to mint others.
+	accessTokenHandler := handlers.NewPersonalAccessTokenHandler(app.SystemDB, app.AccessTokenService)
+	accessTokenRoutes := v1.Group("/access-tokens")
+	accessTokenRoutes.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Revocations: app.TokenDenylist}))
+	{
+		accessTokenRoutes.GET("", accessTokenHandler.ListTokens)
+		accessTokenRoutes.POST("", accessTokenHandler.CreateToken)
+		accessTokenRoutes.DELETE("/:id", accessTokenHandler.RevokeToken)
 	}
 
-	// Protected routes (require authentication)
+	adminRoutes := v1.Group("/admin")
+	adminRoutes.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Revocations: app.TokenDenylist}), app.adminOnlyMiddleware())
+	{
+		adminRoutes.GET("/users/:user_id/sessions", sessionHandler.ListUserSessions)
+		adminRoutes.DELETE("/users/:user_id/sessions", sessionHandler.TerminateUserSessions)
+		adminRoutes.DELETE("/users/:user_id/sessions/:id", sessionHandler.TerminateUserSession)
+	}
+
+	// Workspace management names the workspace in the path, so it runs
+	// without a selected workspace and works for users who have none. Its
+	// handlers do not check token scopes, so personal access tokens are not
+	// accepted.
+	workspaceHandler := handlers.NewWorkspaceHandler(app.SystemDB, app.WorkspaceService)
+	workspaceRoutes := v1.Group("/workspaces")
+	workspaceRoutes.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{Revocations: app.TokenDenylist}))
+	{
+		workspaceRoutes.GET("", workspaceHandler.GetWorkspaces)
+		workspaceRoutes.POST("", workspaceHandler.CreateWorkspace)
+		workspaceRoutes.GET("/:id/members", workspaceHandler.GetMembers)
+		workspaceRoutes.POST("/:id/members", workspaceHandler.AddMember)
+		workspaceRoutes.DELETE("/:id/members/:user_id", workspaceHandler.RemoveMember)
+	}
+
+	// Protected routes (require authentication). Tenant data is limited to
+	// the workspace selected by the token or the X-Workspace-ID header.
+	// Personal access tokens work on the routes in apiTokenScopes.
 	protected := v1.Group("")
-	protected.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{}))
+	protected.Use(middleware.AuthzMiddleware(middleware.AuthzConfig{
+		Workspaces:     workspaceResolver,
+		Revocations:    app.TokenDenylist,
+		APITokens:      apiTokens,
+		APITokenScopes: apiTokenScopes,
+	}))
 	{
 		// Task routes
-		taskHandler := handlers.NewTaskHandler(app.DB, app.TaskService)
+		taskHandler := handlers.NewTaskHandler(app.DB, app.TaskService, services.NewTaskEventOutbox())
+		watcherHandler := handlers.NewWatcherHandler(app.DB, app.WatcherService, app.AuthzService)
+		attachmentHandler := handlers.NewAttachmentHandler(app.DB, app.AttachmentService, app.AuthzService)
 		taskRoutes := protected.Group("/tasks")
 		{
 			taskRoutes.POST("", taskHandler.CreateTask)
@@ -256,6 +657,108 @@ func (app *Application) setupRoutes() {
 			taskRoutes.DELETE("/:id", taskHandler.DeleteTask)
 			taskRoutes.GET("/:id", taskHandler.GetTaskByID)
 			taskRoutes.GET("", taskHandler.GetTasks)
+
+			// Watchers
+			taskRoutes.POST("/:id/watch", watcherHandler.WatchTask)
+			taskRoutes.DELETE("/:id/watch", watcherHandler.UnwatchTask)
+			taskRoutes.GET("/:id/watchers", watcherHandler.GetTaskWatchers)
+
+			// Attachments
+			taskRoutes.GET("/:id/attachments", attachmentHandler.GetAttachments)
+			taskRoutes.GET("/:id/attachments/:attachment_id", attachmentHandler.DownloadAttachment)
+
+			// Share links
+			taskRoutes.POST("/:id/share", shareHandler.CreateShare)
+			taskRoutes.GET("/:id/shares", shareHandler.GetShares)
+			taskRoutes.DELETE("/:id/shares/:share_id", shareHandler.RevokeShare)
+		}
+
+		// Real-time task events (Server-Sent Events)
+		streamHandler := handlers.NewStreamHandler(app.DB, app.EventStream, app.AuthzService, app.Config.Stream.HeartbeatInterval)
+		protected.GET("/stream", streamHandler.Stream)
+
+		// Notification routes
+		notificationHandler := handlers.NewNotificationHandler(app.DB, app.NotificationService)
+		notificationRoutes := protected.Group("/notifications")
+		{
+			notificationRoutes.GET("", notificationHandler.GetNotifications)
+			notificationRoutes.GET("/unread-count", notificationHandler.GetUnreadCount)
+			notificationRoutes.PUT("/read-all", notificationHandler.MarkAllRead)
+			notificationRoutes.PUT("/:id/read", notificationHandler.MarkRead)
+			notificationRoutes.GET("/preferences", notificationHandler.GetNotificationPreferences)
+			notificationRoutes.PUT("/preferences", notificationHandler.UpdateNotificationPreference)
+
+			digestHandler := handlers.NewDigestHandler(app.DB, app.DigestService)
+			notificationRoutes.GET("/digest", digestHandler.GetDigestPreference)
+			notificationRoutes.PUT("/digest", digestHandler.UpdateDigestPreference)
+		}
+
+		// Webhook routes
+		webhookHandler := handlers.NewWebhookHandler(app.DB, app.WebhookService, app.AuthzService)
+		webhookRoutes := protected.Group("/webhooks")
+		{
+			webhookRoutes.POST("", webhookHandler.CreateWebhook)
+			webhookRoutes.GET("", webhookHandler.GetWebhooks)
+			webhookRoutes.GET("/:id", webhookHandler.GetWebhook)
+			webhookRoutes.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhookRoutes.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhookRoutes.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+			webhookRoutes.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		}
+
+		// Calendar feed token management
+		protected.POST("/calendar/token", calendarHandler.RegenerateToken)
+		protected.DELETE("/calendar/token", calendarHandler.RevokeToken)
+
+		// Inbound email address management
+		if inboundHandler != nil {
+			protected.GET("/inbound/address", inboundHandler.GetAddress)
+			protected.POST("/inbound/address", inboundHandler.RegenerateAddress)
+		}
+
+		// Report routes
+		reportHandler := handlers.NewReportHandler(app.DB, app.ReportService, app.AuthzService)
+		reportRoutes := protected.Group("/reports")
+		{
+			reportRoutes.GET("/throughput", reportHandler.Throughput)
+			reportRoutes.GET("/durations", reportHandler.TaskDurations)
+			reportRoutes.GET("/status", reportHandler.StatusBreakdown)
+			reportRoutes.GET("/overdue", reportHandler.Overdue)
+		}
+
+		// Workload per assignee, grouped by department
+		workloadHandler := handlers.NewWorkloadHandler(app.DB, app.WorkloadService, app.AuthzService)
+		protected.GET("/workload", workloadHandler.GetWorkload)
+
+		// Sprint routes
+		sprintHandler := handlers.NewSprintHandler(app.DB, app.SprintService, app.AuthzService)
+		sprintRoutes := protected.Group("/sprints")
+		{
+			sprintRoutes.POST("", sprintHandler.CreateSprint)
+			sprintRoutes.GET("", sprintHandler.GetSprints)
+			sprintRoutes.GET("/:id", sprintHandler.GetSprint)
+			sprintRoutes.POST("/:id/start", sprintHandler.StartSprint)
+			sprintRoutes.POST("/:id/close", sprintHandler.CloseSprint)
+			sprintRoutes.GET("/:id/burndown", sprintHandler.GetBurndown)
+			sprintRoutes.POST("/:id/tasks", sprintHandler.AddTask)
+			sprintRoutes.DELETE("/:id/tasks/:task_id", sprintHandler.RemoveTask)
+		}
+
+		// Team routes
+		teamHandler := handlers.NewTeamHandler(app.DB, app.TeamService, app.AuthzService)
+		teamRoutes := protected.Group("/teams")
+		{
+			teamRoutes.GET("", teamHandler.GetTeams)
+			teamRoutes.POST("", teamHandler.CreateTeam)
+			teamRoutes.GET("/:id", teamHandler.GetTeam)
+			teamRoutes.DELETE("/:id", teamHandler.DeleteTeam)
+			teamRoutes.GET("/:id/members", teamHandler.GetMembers)
+			teamRoutes.POST("/:id/members", teamHandler.AddMember)
+			teamRoutes.DELETE("/:id/members/:user_id", teamHandler.RemoveMember)
+			teamRoutes.POST("/:id/tasks", teamHandler.AddTask)
+			teamRoutes.DELETE("/:id/tasks/:task_id", teamHandler.RemoveTask)
+			teamRoutes.GET("/:id/queue", teamHandler.GetQueue)
+			teamRoutes.POST("/:id/queue/:task_id/claim", teamHandler.ClaimTask)
 		}
 
 		// User routes
@@ -310,6 +813,30 @@ func (app *Application) startServer() {
 		IdleTimeout:  app.Config.Server.IdleTimeout,
 	}
 
+	// Event streams and sockets never go idle, so end them when shutdown begins rather than
+	// letting Shutdown wait out its timeout on them.
+	app.Server.RegisterOnShutdown(func() {
+		if app.InboundSMTP != nil {
+			app.InboundSMTP.Close()
+		}
+		if app.CollaborationHub != nil {
+			app.CollaborationHub.Stop()
+		}
+		// The relay and bus publish to the event stream, so stop them first;
+		// anything left pending is picked up after restart.
+		if app.OutboxRelay != nil {
+			app.OutboxRelay.Stop()
+		}
+		if app.EventBus != nil {
+			app.EventBus.Close()
+		}
+		if app.EventStream != nil {
+			if err := app.EventStream.Close(); err != nil {
+				log.Printf("⚠️  Error closing event stream: %v", err)
+			}
+		}
+	})
+
 	go func() {
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -328,6 +855,15 @@ func (app *Application) startServer() {
 		log.Println("✅ Server stopped gracefully")
 	}()
 
+	if app.InboundSMTP != nil {
+		go func() {
+			log.Printf("📨 Inbound SMTP listening on %s", app.Config.InboundEmail.SMTPAddr)
+			if err := app.InboundSMTP.ListenAndServe(app.Config.InboundEmail.SMTPAddr); err != nil {
+				log.Printf("❌ Inbound SMTP stopped: %v", err)
+			}
+		}()
+	}
+
 	log.Printf("🚀 Server starting on %s", addr)
 	log.Printf("📊 Metrics available at http://%s/metrics", addr)
 	log.Printf("💚 Health check at http://%s/health", addr)
@@ -340,6 +876,18 @@ func (app *Application) startServer() {
 func (app *Application) cleanup() {
 	log.Println("🧹 Cleaning up resources...")
 
+	if app.Scheduler != nil {
+		app.Scheduler.Stop()
+	}
+
+	if app.Digests != nil {
+		app.Digests.Stop()
+	}
+
+	if app.Worker != nil {
+		app.Worker.Stop()
+	}
+
 	if app.CacheManager != nil {
 		if err := app.CacheManager.Stop(); err != nil {
 			log.Printf("⚠️  Error stopping cache manager: %v", err)
@@ -358,8 +906,11 @@ func (app *Application) cleanup() {
 		}
 	}
 
-	if app.DB != nil {
-		sqlDB, err := app.DB.DB()
+	for _, db := range []*gorm.DB{app.DB, app.SystemDB} {
+		if db == nil {
+			continue
+		}
+		sqlDB, err := db.DB()
 		if err == nil {
 			if err := sqlDB.Close(); err != nil {
 				log.Printf("⚠️  Error closing database: %v", err)
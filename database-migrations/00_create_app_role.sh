@@ -0,0 +1,19 @@
+#!/bin/sh
+# Runs once, when the Postgres container initializes an empty volume.
+#
+# The backend must not connect as a superuser or a role with BYPASSRLS:
+# those skip the row-level security policies that keep workspaces apart.
+# This creates the application's login role, which owns the database and
+# runs the migrations, and taskify_system, the BYPASSRLS role the backend
+# assumes for work that has no workspace (see migration 000035).
+set -e
+
+psql -v ON_ERROR_STOP=1 \
+    -v app_user="$APP_DB_USER" -v app_password="$APP_DB_PASSWORD" -v db_name="$POSTGRES_DB" \
+    --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<'SQL'
+CREATE ROLE :"app_user" LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEROLE PASSWORD :'app_password';
+CREATE ROLE taskify_system NOLOGIN BYPASSRLS;
+GRANT taskify_system TO :"app_user";
+ALTER DATABASE :"db_name" OWNER TO :"app_user";
+ALTER SCHEMA public OWNER TO :"app_user";
+SQL
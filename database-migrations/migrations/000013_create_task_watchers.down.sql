@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_task_watchers_user_id;
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS task_watchers;
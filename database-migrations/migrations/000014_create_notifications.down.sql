@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_notifications_created_at;
+DROP INDEX IF EXISTS idx_notifications_unread;
+DROP INDEX IF EXISTS idx_notifications_user_created;
+
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS in_app_enabled;
+
+DROP TABLE IF EXISTS notifications;
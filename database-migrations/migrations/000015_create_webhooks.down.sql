@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_created;
+DROP INDEX IF EXISTS idx_webhooks_active;
+DROP INDEX IF EXISTS idx_webhooks_user_id;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+DROP INDEX IF EXISTS idx_outbox_published_at;
+DROP INDEX IF EXISTS idx_outbox_pending;
+
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS event_id;
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,9 @@
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS context;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS request_path;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS request_method;
+
+ALTER TABLE audit_logs ALTER COLUMN ip_address TYPE INET USING NULLIF(ip_address, '')::INET;
+
+DELETE FROM audit_logs WHERE decision = 'recorded';
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_decision_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_decision_check CHECK (decision IN ('allowed', 'denied'));
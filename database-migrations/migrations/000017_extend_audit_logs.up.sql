@@ -0,0 +1,10 @@
+-- Domain events are audited alongside authorization decisions. They are
+-- recorded rather than allowed or denied, and often have no client address.
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_decision_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_decision_check CHECK (decision IN ('allowed', 'denied', 'recorded'));
+
+ALTER TABLE audit_logs ALTER COLUMN ip_address TYPE VARCHAR(45) USING ip_address::TEXT;
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_method VARCHAR(10);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_path TEXT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS context TEXT;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_tasks_user_due_date;
+
+DROP TABLE IF EXISTS calendar_feeds;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS labels;
+ALTER TABLE tasks DROP COLUMN IF EXISTS recurrence_rule;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_date;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS inbound_email_addresses;
+DROP INDEX IF EXISTS idx_task_attachments_task_id;
+DROP TABLE IF EXISTS task_attachments;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_digest_preferences_frequency;
+DROP TABLE IF EXISTS digest_preferences;
@@ -0,0 +1,14 @@
+-- Users who opt into a digest get one email per day or week instead of one
+-- per notification. period_end is the end of the last period sent.
+CREATE TABLE IF NOT EXISTS digest_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL DEFAULT 'off' CHECK (frequency IN ('off', 'daily', 'weekly')),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    hour SMALLINT NOT NULL DEFAULT 8 CHECK (hour BETWEEN 0 AND 23),
+    weekday SMALLINT NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6),
+    period_end TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_preferences_frequency ON digest_preferences(frequency) WHERE frequency <> 'off';
@@ -0,0 +1,8 @@
+DELETE FROM role_permissions WHERE permission_id = '10000000-0000-0000-0000-000000000041';
+DELETE FROM permissions WHERE id = '10000000-0000-0000-0000-000000000041';
+
+DROP INDEX IF EXISTS idx_tasks_completed_at;
+DROP INDEX IF EXISTS idx_tasks_created_at;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,19 @@
+-- started_at is set the first time a task moves to in_progress and
+-- completed_at whenever it is completed, so reports can measure cycle and
+-- lead time. Existing tasks are backfilled from their last update.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS started_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+UPDATE tasks SET started_at = updated_at WHERE status = 'in_progress' AND started_at IS NULL;
+UPDATE tasks SET completed_at = updated_at WHERE status IN ('completed', 'done') AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(completed_at) WHERE completed_at IS NOT NULL;
+
+INSERT INTO permissions (id, resource, action, scope, description) VALUES
+    ('10000000-0000-0000-0000-000000000041', 'report', 'read', 'all', 'Read task reports')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id, granted_by) VALUES
+    ('00000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000041', '00000000-0000-0000-0000-000000000010')
+ON CONFLICT DO NOTHING;
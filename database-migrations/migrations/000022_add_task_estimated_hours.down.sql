@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_open_by_user;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimated_hours;
@@ -0,0 +1,6 @@
+-- Optional effort estimate used by the workload view.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimated_hours NUMERIC(6, 2)
+    CHECK (estimated_hours IS NULL OR estimated_hours >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_open_by_user ON tasks(user_id)
+    WHERE status NOT IN ('completed', 'done', 'cancelled');
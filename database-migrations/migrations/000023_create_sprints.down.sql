@@ -0,0 +1,9 @@
+DELETE FROM role_permissions WHERE permission_id = '10000000-0000-0000-0000-000000000042';
+DELETE FROM permissions WHERE id = '10000000-0000-0000-0000-000000000042';
+
+DROP TABLE IF EXISTS sprint_snapshots;
+
+DROP INDEX IF EXISTS idx_tasks_sprint_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS sprint_id;
+
+DROP TABLE IF EXISTS sprints;
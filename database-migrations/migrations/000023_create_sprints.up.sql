@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS sprints (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    goal TEXT,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'planned' CHECK (status IN ('planned', 'active', 'closed')),
+    closed_at TIMESTAMP,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sprints_status ON sprints(status);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS sprint_id UUID REFERENCES sprints(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_sprint_id ON tasks(sprint_id) WHERE sprint_id IS NOT NULL;
+
+-- One row per sprint and day, rewritten by each snapshot run that day so the
+-- last run records the day's closing state.
+CREATE TABLE IF NOT EXISTS sprint_snapshots (
+    sprint_id UUID NOT NULL REFERENCES sprints(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    total_tasks INTEGER NOT NULL DEFAULT 0,
+    completed_tasks INTEGER NOT NULL DEFAULT 0,
+    remaining_tasks INTEGER NOT NULL DEFAULT 0,
+    total_hours NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    remaining_hours NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (sprint_id, snapshot_date)
+);
+
+INSERT INTO permissions (id, resource, action, scope, description) VALUES
+    ('10000000-0000-0000-0000-000000000042', 'sprint', 'manage', 'all', 'Create, start and close sprints')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id, granted_by) VALUES
+    ('00000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000042', '00000000-0000-0000-0000-000000000010')
+ON CONFLICT DO NOTHING;
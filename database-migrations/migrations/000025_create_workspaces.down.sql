@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_audit_logs_workspace_id;
+DROP INDEX IF EXISTS idx_sprints_workspace_id;
+DROP INDEX IF EXISTS idx_tasks_workspace_id;
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE sprints DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
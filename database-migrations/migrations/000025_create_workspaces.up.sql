@@ -0,0 +1,48 @@
+-- Workspaces partition tasks, sprints and audit logs between tenants. Users
+-- may belong to several workspaces with a role in each.
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- Existing data moves into one default workspace that every current user
+-- joins; admins own it.
+INSERT INTO workspaces (id, name, slug, created_by) VALUES
+    ('20000000-0000-0000-0000-000000000001', 'Default', 'default', '00000000-0000-0000-0000-000000000010')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT '20000000-0000-0000-0000-000000000001', u.id,
+    CASE WHEN EXISTS (
+        SELECT 1 FROM user_roles ur
+        WHERE ur.user_id = u.id AND ur.role_id = '00000000-0000-0000-0000-000000000002'
+    ) THEN 'owner' ELSE 'member' END
+FROM users u
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE sprints ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE SET NULL;
+
+UPDATE tasks SET workspace_id = '20000000-0000-0000-0000-000000000001' WHERE workspace_id IS NULL;
+UPDATE sprints SET workspace_id = '20000000-0000-0000-0000-000000000001' WHERE workspace_id IS NULL;
+UPDATE audit_logs SET workspace_id = '20000000-0000-0000-0000-000000000001' WHERE workspace_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_id ON tasks(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_sprints_workspace_id ON sprints(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_workspace_id ON audit_logs(workspace_id);
@@ -0,0 +1,33 @@
+DROP POLICY IF EXISTS tenant_isolation ON sprint_snapshots;
+ALTER TABLE sprint_snapshots NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE sprint_snapshots DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON task_shares;
+ALTER TABLE task_shares NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE task_shares DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON task_watchers;
+ALTER TABLE task_watchers NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE task_watchers DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON task_attachments;
+ALTER TABLE task_attachments NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE task_attachments DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON users;
+ALTER TABLE users NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON audit_logs;
+ALTER TABLE audit_logs NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE audit_logs DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON sprints;
+ALTER TABLE sprints NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE sprints DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON tasks;
+ALTER TABLE tasks NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tasks DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_current_workspace();
@@ -0,0 +1,63 @@
+-- Row-level security backs up the application's workspace scoping. The API
+-- sets app.workspace_id for requests made inside a workspace; when it is
+-- unset, as for background jobs, logins and public links, every row is
+-- visible. FORCE makes the policies apply to the table owner as well.
+-- Superusers and roles with BYPASSRLS are never subject to them, so the
+-- application must not connect as one.
+CREATE OR REPLACE FUNCTION app_current_workspace() RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.workspace_id', true), '')::UUID
+$$ LANGUAGE SQL STABLE;
+
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON tasks;
+CREATE POLICY tenant_isolation ON tasks
+    USING (app_current_workspace() IS NULL OR workspace_id = app_current_workspace());
+
+ALTER TABLE sprints ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sprints FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON sprints;
+CREATE POLICY tenant_isolation ON sprints
+    USING (app_current_workspace() IS NULL OR workspace_id = app_current_workspace());
+
+ALTER TABLE audit_logs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_logs FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON audit_logs;
+CREATE POLICY tenant_isolation ON audit_logs
+    USING (app_current_workspace() IS NULL OR workspace_id = app_current_workspace());
+
+-- Users are shared between workspaces; inside one, only its members exist.
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users
+    USING (app_current_workspace() IS NULL OR EXISTS (
+        SELECT 1 FROM workspace_members wm
+        WHERE wm.user_id = users.id AND wm.workspace_id = app_current_workspace()
+    ));
+
+-- Rows hanging off a task or sprint follow their parent, whose own policy
+-- applies inside the subquery.
+ALTER TABLE task_attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_attachments FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON task_attachments;
+CREATE POLICY tenant_isolation ON task_attachments
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_attachments.task_id));
+
+ALTER TABLE task_watchers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_watchers FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON task_watchers;
+CREATE POLICY tenant_isolation ON task_watchers
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_watchers.task_id));
+
+ALTER TABLE task_shares ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_shares FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON task_shares;
+CREATE POLICY tenant_isolation ON task_shares
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_shares.task_id));
+
+ALTER TABLE sprint_snapshots ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sprint_snapshots FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON sprint_snapshots;
+CREATE POLICY tenant_isolation ON sprint_snapshots
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM sprints s WHERE s.id = sprint_snapshots.sprint_id));
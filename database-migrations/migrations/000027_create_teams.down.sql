@@ -0,0 +1,10 @@
+DELETE FROM role_permissions WHERE permission_id = '10000000-0000-0000-0000-000000000043';
+DELETE FROM permissions WHERE id = '10000000-0000-0000-0000-000000000043';
+
+DROP INDEX IF EXISTS idx_tasks_assignee_id;
+DROP INDEX IF EXISTS idx_tasks_team_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS assignee_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS team_id;
+
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,64 @@
+-- Teams are the unit people work in inside a workspace. They replace the
+-- free-text users.department, which is kept for existing reports.
+CREATE TABLE IF NOT EXISTS teams (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (workspace_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    is_lead BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members(user_id);
+
+-- A team-owned task sits in the team's queue until a member claims it.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_team_id ON tasks(team_id) WHERE team_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks(assignee_id) WHERE assignee_id IS NOT NULL;
+
+-- Every department becomes a team in each workspace its members belong to.
+INSERT INTO teams (workspace_id, name)
+SELECT DISTINCT wm.workspace_id, LEFT(TRIM(u.department), 100)
+FROM users u
+JOIN workspace_members wm ON wm.user_id = u.id
+WHERE TRIM(COALESCE(u.department, '')) <> '' AND u.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO team_members (team_id, user_id)
+SELECT t.id, u.id
+FROM users u
+JOIN workspace_members wm ON wm.user_id = u.id
+JOIN teams t ON t.workspace_id = wm.workspace_id AND t.name = LEFT(TRIM(u.department), 100)
+WHERE u.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE teams ENABLE ROW LEVEL SECURITY;
+ALTER TABLE teams FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON teams;
+CREATE POLICY tenant_isolation ON teams
+    USING (app_current_workspace() IS NULL OR workspace_id = app_current_workspace());
+
+ALTER TABLE team_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE team_members FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON team_members;
+CREATE POLICY tenant_isolation ON team_members
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM teams t WHERE t.id = team_members.team_id));
+
+INSERT INTO permissions (id, resource, action, scope, description) VALUES
+    ('10000000-0000-0000-0000-000000000043', 'team', 'manage', 'all', 'Create and delete teams and appoint team leads')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id, granted_by) VALUES
+    ('00000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000043', '00000000-0000-0000-0000-000000000010')
+ON CONFLICT DO NOTHING;
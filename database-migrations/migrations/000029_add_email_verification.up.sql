@@ -0,0 +1,6 @@
+-- Accounts that existed before verification was introduced count as
+-- verified, so turning on REQUIRE_EMAIL_VERIFICATION does not lock them out.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMP;
+
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
@@ -0,0 +1,10 @@
+-- Refresh tokens issued by rotating another one share its family. Rotated
+-- tokens are kept until they expire, so replaying one can be recognised and
+-- the whole family revoked.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMPTZ;
+
+UPDATE tokens SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_family_id ON tokens(family_id);
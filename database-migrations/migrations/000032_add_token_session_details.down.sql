@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tokens_user_active;
+ALTER TABLE tokens DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE tokens DROP COLUMN IF EXISTS ip_address;
+ALTER TABLE tokens DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE tokens DROP COLUMN IF EXISTS device;
+ALTER TABLE tokens DROP COLUMN IF EXISTS access_jti;
@@ -0,0 +1,12 @@
+-- Refresh tokens double as login sessions: record where each one is used so
+-- users can review and end them. access_jti names the access token issued
+-- with the refresh token, letting an ended session revoke it too.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS access_jti UUID;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS device VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ;
+
+UPDATE tokens SET last_used_at = created_at WHERE last_used_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_user_active ON tokens(user_id) WHERE rotated_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_webhooks_workspace_id;
+
+ALTER TABLE webhooks DROP COLUMN IF EXISTS workspace_id;
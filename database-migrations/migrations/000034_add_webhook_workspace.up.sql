@@ -0,0 +1,8 @@
+-- Webhooks belong to the workspace they were created in and only receive
+-- events for that workspace's tasks. Existing webhooks move to the Default
+-- workspace, like the tasks they were created for.
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE webhooks SET workspace_id = '20000000-0000-0000-0000-000000000001' WHERE workspace_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_workspace_id ON webhooks(workspace_id);
@@ -0,0 +1,47 @@
+DROP POLICY IF EXISTS tenant_isolation ON tasks;
+CREATE POLICY tenant_isolation ON tasks
+    USING (app_current_workspace() IS NULL OR workspace_id = app_current_workspace());
+
+DROP POLICY IF EXISTS tenant_isolation ON sprints;
+CREATE POLICY tenant_isolation ON sprints
+    USING (app_current_workspace() IS NULL OR workspace_id = app_current_workspace());
+
+DROP POLICY IF EXISTS tenant_isolation ON audit_logs;
+CREATE POLICY tenant_isolation ON audit_logs
+    USING (app_current_workspace() IS NULL OR workspace_id = app_current_workspace());
+
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users
+    USING (app_current_workspace() IS NULL OR EXISTS (
+        SELECT 1 FROM workspace_members wm
+        WHERE wm.user_id = users.id AND wm.workspace_id = app_current_workspace()
+    ));
+
+DROP POLICY IF EXISTS tenant_isolation ON task_attachments;
+CREATE POLICY tenant_isolation ON task_attachments
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_attachments.task_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON task_watchers;
+CREATE POLICY tenant_isolation ON task_watchers
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_watchers.task_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON task_shares;
+CREATE POLICY tenant_isolation ON task_shares
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_shares.task_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON sprint_snapshots;
+CREATE POLICY tenant_isolation ON sprint_snapshots
+    USING (app_current_workspace() IS NULL OR EXISTS (SELECT 1 FROM sprints s WHERE s.id = sprint_snapshots.sprint_id));
+
+-- The role is left in place: it may own grants in other databases.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'taskify_system') THEN
+        ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL ON TABLES FROM taskify_system;
+        ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE ALL ON SEQUENCES FROM taskify_system;
+        REVOKE ALL ON ALL TABLES IN SCHEMA public FROM taskify_system;
+        REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM taskify_system;
+        REVOKE USAGE ON SCHEMA public FROM taskify_system;
+    END IF;
+END
+$$;
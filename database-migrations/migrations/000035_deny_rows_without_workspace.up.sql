@@ -0,0 +1,71 @@
+-- Requests inside a workspace see only its rows; any other statement on the
+-- application role now sees none, rather than every tenant's. Work that has
+-- no workspace - background jobs, logins, public links, workspace selection -
+-- runs on a separate pool that assumes taskify_system, which bypasses the
+-- policies.
+--
+-- Creating a BYPASSRLS role takes a superuser. When the migration runs as
+-- the application role, a superuser must create it instead:
+--   CREATE ROLE taskify_system NOLOGIN BYPASSRLS;
+--   GRANT taskify_system TO <application role>;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'taskify_system') THEN
+        CREATE ROLE taskify_system NOLOGIN BYPASSRLS;
+    END IF;
+    IF NOT pg_has_role(current_user, 'taskify_system', 'MEMBER') THEN
+        EXECUTE format('GRANT taskify_system TO %I', current_user);
+    END IF;
+EXCEPTION WHEN insufficient_privilege THEN
+    RAISE WARNING 'taskify_system must be created by a superuser and granted to %', current_user;
+END
+$$;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'taskify_system') THEN
+        GRANT USAGE ON SCHEMA public TO taskify_system;
+        GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO taskify_system;
+        GRANT USAGE, SELECT, UPDATE ON ALL SEQUENCES IN SCHEMA public TO taskify_system;
+        ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO taskify_system;
+        ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT, UPDATE ON SEQUENCES TO taskify_system;
+    END IF;
+END
+$$;
+
+-- With app.workspace_id unset, app_current_workspace() is NULL and every
+-- comparison with it fails.
+DROP POLICY IF EXISTS tenant_isolation ON tasks;
+CREATE POLICY tenant_isolation ON tasks
+    USING (workspace_id = app_current_workspace());
+
+DROP POLICY IF EXISTS tenant_isolation ON sprints;
+CREATE POLICY tenant_isolation ON sprints
+    USING (workspace_id = app_current_workspace());
+
+DROP POLICY IF EXISTS tenant_isolation ON audit_logs;
+CREATE POLICY tenant_isolation ON audit_logs
+    USING (workspace_id = app_current_workspace());
+
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users
+    USING (EXISTS (
+        SELECT 1 FROM workspace_members wm
+        WHERE wm.user_id = users.id AND wm.workspace_id = app_current_workspace()
+    ));
+
+DROP POLICY IF EXISTS tenant_isolation ON task_attachments;
+CREATE POLICY tenant_isolation ON task_attachments
+    USING (EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_attachments.task_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON task_watchers;
+CREATE POLICY tenant_isolation ON task_watchers
+    USING (EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_watchers.task_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON task_shares;
+CREATE POLICY tenant_isolation ON task_shares
+    USING (EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_shares.task_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON sprint_snapshots;
+CREATE POLICY tenant_isolation ON sprint_snapshots
+    USING (EXISTS (SELECT 1 FROM sprints s WHERE s.id = sprint_snapshots.sprint_id));
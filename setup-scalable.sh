@@ -99,8 +99,11 @@ if [ ! -f .env ]; then
 # Database Configuration
 DB_HOST=postgres
 DB_PORT=$POSTGRES_PORT
-DB_USER=postgres
-DB_PASSWORD=postgres
+# The backend's role must not be a superuser; see SET_UP.md
+DB_USER=taskify_app
+DB_PASSWORD=taskify_app
+DB_ADMIN_USER=postgres
+DB_ADMIN_PASSWORD=postgres
 DB_NAME=task_manager
 DB_MAX_OPEN_CONNS=25
 DB_MAX_IDLE_CONNS=10
@@ -220,7 +223,7 @@ sleep 10
 max_attempts=30
 attempt=0
 while [ $attempt -lt $max_attempts ]; do
-    if docker-compose -f docker-compose.scalable.yml exec -T postgres pg_isready -U postgres > /dev/null 2>&1; then
+    if docker-compose -f docker-compose.scalable.yml exec -T postgres pg_isready -U ${DB_ADMIN_USER:-postgres} > /dev/null 2>&1; then
         break
     fi
     attempt=$((attempt + 1))